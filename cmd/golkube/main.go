@@ -3,6 +3,7 @@ package main
 import (
 	"golkube/pkg/commands"
 	"log"
+	"os"
 
 	"github.com/spf13/viper"
 )
@@ -10,12 +11,28 @@ import (
 func main() {
 	// Load environment and configuration files
 	commands.LoadEnvironment()
+
+	// doctor diagnoses the configuration and clients itself, so it runs before loading them fails
+	if commands.CommandRequested("doctor") {
+		os.Exit(commands.RunDoctor())
+	}
+
 	commands.LoadConfiguration()
 
 	// Set the logging level based on the log-level flag
 	logLevel := viper.GetString("log-level")
 	commands.SetLogLevel(logLevel)
 
+	// With --contexts or --all-contexts, golkube re-runs itself once per cluster
+	if commands.MultiContextRequested() {
+		os.Exit(commands.RunMultiContext())
+	}
+
+	// validate connects to the cluster only when it needs schemas from it, so it works offline
+	if commands.CommandRequested("validate") {
+		os.Exit(commands.RunValidate())
+	}
+
 	// Initialize Kubernetes and Docker clients
 	kubeClient, dockerRegistry := commands.InitializeClients()
 
@@ -0,0 +1,337 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golkube/pkg/kube"
+	"golkube/pkg/notify"
+)
+
+// State is the lifecycle state of an alert
+type State string
+
+const (
+	StatePending  State = "pending"
+	StateFiring   State = "firing"
+	StateResolved State = "resolved"
+)
+
+// defaultRepeatInterval is used when the alerting config does not set repeat_interval
+const defaultRepeatInterval = 4 * time.Hour
+
+// dispatchTimeout bounds how long a grouped notification may take including retries
+const dispatchTimeout = 2 * time.Minute
+
+// Alert is a single instance of a rule firing for a specific object
+type Alert struct {
+	Rule        string
+	Labels      map[string]string
+	Severity    notify.Severity
+	State       State
+	Summary     string
+	ActiveSince time.Time
+	FiredAt     time.Time
+	ResolvedAt  time.Time
+
+	lastNotified time.Time
+}
+
+// Engine evaluates alert rules against monitor snapshots and routes notifications.
+// It implements kube.MonitorObserver.
+type Engine struct {
+	mu sync.Mutex
+
+	rules          []Rule
+	repeatInterval time.Duration
+	notifiers      map[string]notify.Notifier
+	silences       *SilenceStore
+
+	alerts    map[string]*Alert
+	restarts  map[string][]restartSample
+	maxWindow time.Duration
+}
+
+// NewEngine validates the rules and creates an Engine routing to the given notifiers
+func NewEngine(config Config, notifiers []notify.Notifier) (*Engine, error) {
+	engine := &Engine{
+		repeatInterval: config.RepeatInterval,
+		notifiers:      make(map[string]notify.Notifier, len(notifiers)),
+		silences:       NewSilenceStore(config.SilencesFile),
+		alerts:         make(map[string]*Alert),
+		restarts:       make(map[string][]restartSample),
+	}
+	if engine.repeatInterval <= 0 {
+		engine.repeatInterval = defaultRepeatInterval
+	}
+	for _, notifier := range notifiers {
+		engine.notifiers[notifier.Name()] = notifier
+	}
+
+	seen := make(map[string]bool)
+	for _, rule := range config.Rules {
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+		if seen[rule.Name] {
+			return nil, fmt.Errorf("duplicate rule name %q", rule.Name)
+		}
+		seen[rule.Name] = true
+
+		for _, channel := range rule.Route {
+			if _, ok := engine.notifiers[channel]; !ok {
+				log.Printf("Warning: rule %s routes to channel %q which is not enabled", rule.Name, channel)
+			}
+		}
+		if rule.Condition.Window > engine.maxWindow {
+			engine.maxWindow = rule.Condition.Window
+		}
+		engine.rules = append(engine.rules, rule)
+	}
+
+	return engine, nil
+}
+
+// Rules returns the validated rules
+func (e *Engine) Rules() []Rule {
+	return e.rules
+}
+
+// Alerts returns the pending and firing alerts, sorted by rule and labels
+func (e *Engine) Alerts() []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	alerts := make([]Alert, 0, len(e.alerts))
+	for _, alert := range e.alerts {
+		alerts = append(alerts, *alert)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		if alerts[i].Rule != alerts[j].Rule {
+			return alerts[i].Rule < alerts[j].Rule
+		}
+		return fingerprint(alerts[i].Labels) < fingerprint(alerts[j].Labels)
+	})
+	return alerts
+}
+
+// RecordWatchError is part of kube.MonitorObserver; watch failures do not affect alert state
+func (e *Engine) RecordWatchError(resource string, err error) {}
+
+// Observe evaluates every rule against the snapshot and sends the resulting notifications
+func (e *Engine) Observe(snapshot kube.MonitorSnapshot) {
+	e.mu.Lock()
+	e.recordRestarts(snapshot)
+
+	var changed []Alert
+	for i := range e.rules {
+		changed = append(changed, e.evaluateRule(&e.rules[i], snapshot)...)
+	}
+	e.mu.Unlock()
+
+	for _, alert := range changed {
+		fmt.Printf("[ALERT %s] %s {%s}: %s\n", strings.ToUpper(string(alert.State)), alert.Rule, fingerprint(alert.Labels), alert.Summary)
+	}
+	e.dispatch(e.unsilenced(changed, snapshot.Time))
+}
+
+// recordRestarts appends the current restart counts to each pod's history
+func (e *Engine) recordRestarts(snapshot kube.MonitorSnapshot) {
+	if e.maxWindow == 0 {
+		return
+	}
+
+	current := make(map[string]bool, len(snapshot.Pods))
+	for i := range snapshot.Pods {
+		pod := &snapshot.Pods[i]
+		key := pod.Namespace + "/" + pod.Name
+		current[key] = true
+
+		samples := append(e.restarts[key], restartSample{
+			at:       snapshot.Time,
+			restarts: kube.DiagnosePod(pod).Restarts,
+		})
+		// Keep one sample older than the window so deltas cover the full period
+		for len(samples) > 2 && snapshot.Time.Sub(samples[1].at) > e.maxWindow {
+			samples = samples[1:]
+		}
+		e.restarts[key] = samples
+	}
+
+	for key := range e.restarts {
+		if !current[key] {
+			delete(e.restarts, key)
+		}
+	}
+}
+
+// evaluateRule advances the state of every alert of a rule and returns the alerts to notify about
+func (e *Engine) evaluateRule(rule *Rule, snapshot kube.MonitorSnapshot) []Alert {
+	var notifications []Alert
+	now := snapshot.Time
+	active := make(map[string]bool)
+
+	for _, candidate := range rule.evaluate(snapshot, e.restarts) {
+		labels := map[string]string{"alertname": rule.Name, "severity": string(rule.Severity)}
+		for key, value := range rule.Labels {
+			labels[key] = value
+		}
+		for key, value := range candidate.labels {
+			labels[key] = value
+		}
+		key := fingerprint(labels)
+		active[key] = true
+
+		alert, exists := e.alerts[key]
+		if !exists {
+			alert = &Alert{
+				Rule:        rule.Name,
+				Labels:      labels,
+				Severity:    rule.Severity,
+				State:       StatePending,
+				ActiveSince: now,
+			}
+			e.alerts[key] = alert
+		}
+		alert.Summary = candidate.summary
+
+		switch alert.State {
+		case StatePending:
+			if now.Sub(alert.ActiveSince) >= rule.Condition.For {
+				alert.State = StateFiring
+				alert.FiredAt = now
+				alert.lastNotified = now
+				notifications = append(notifications, *alert)
+			}
+		case StateFiring:
+			// Deduplicate: a firing alert is only re-sent once per repeat interval
+			if now.Sub(alert.lastNotified) >= e.repeatInterval {
+				alert.lastNotified = now
+				notifications = append(notifications, *alert)
+			}
+		}
+	}
+
+	for key, alert := range e.alerts {
+		if alert.Rule != rule.Name || active[key] {
+			continue
+		}
+		if alert.State == StateFiring {
+			alert.State = StateResolved
+			alert.ResolvedAt = now
+			notifications = append(notifications, *alert)
+		}
+		delete(e.alerts, key)
+	}
+
+	return notifications
+}
+
+// unsilenced drops alerts matched by an active silence
+func (e *Engine) unsilenced(alerts []Alert, now time.Time) []Alert {
+	var result []Alert
+	for _, alert := range alerts {
+		silence, err := e.silences.Silenced(alert.Labels, now)
+		if err != nil {
+			log.Printf("Warning: failed to read silences: %v", err)
+		}
+		if silence != nil {
+			fmt.Printf("[ALERT SILENCED] %s {%s} by silence %s\n", alert.Rule, fingerprint(alert.Labels), silence.ID)
+			continue
+		}
+		result = append(result, alert)
+	}
+	return result
+}
+
+// dispatch groups alerts by rule and state and sends one notification per group to each routed channel
+func (e *Engine) dispatch(alerts []Alert) {
+	if len(alerts) == 0 || len(e.notifiers) == 0 {
+		return
+	}
+
+	routes := make(map[string][]string, len(e.rules))
+	for _, rule := range e.rules {
+		routes[rule.Name] = rule.Route
+	}
+
+	groups := make(map[string][]Alert)
+	var order []string
+	for _, alert := range alerts {
+		key := alert.Rule + "/" + string(alert.State)
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], alert)
+	}
+
+	for _, key := range order {
+		group := groups[key]
+		msg := groupMessage(group)
+
+		var targets []notify.Notifier
+		if route := routes[group[0].Rule]; len(route) > 0 {
+			for _, channel := range route {
+				if notifier, ok := e.notifiers[channel]; ok {
+					targets = append(targets, notifier)
+				}
+			}
+		} else {
+			for _, notifier := range e.notifiers {
+				targets = append(targets, notifier)
+			}
+		}
+
+		for _, notifier := range targets {
+			go func(notifier notify.Notifier) {
+				ctx, cancel := context.WithTimeout(context.Background(), dispatchTimeout)
+				defer cancel()
+				if err := notifier.Notify(ctx, msg); err != nil {
+					log.Printf("Warning: failed to send alert %q via %s: %v", msg.Title, notifier.Name(), err)
+				}
+			}(notifier)
+		}
+	}
+}
+
+// groupMessage renders a group of alerts sharing a rule and state as one notification
+func groupMessage(group []Alert) notify.Message {
+	first := group[0]
+	msg := notify.Message{
+		Title:    fmt.Sprintf("[%s:%d] %s", strings.ToUpper(string(first.State)), len(group), first.Rule),
+		Severity: first.Severity,
+		Source:   "monitor",
+		Event:    "alert." + string(first.State),
+		Fields:   map[string]string{},
+		Time:     time.Now(),
+	}
+	if first.State == StateResolved {
+		msg.Severity = notify.SeverityInfo
+	}
+
+	// Labels shared by every alert in the group become fields; the rest stay per line
+	for key, value := range first.Labels {
+		shared := true
+		for _, alert := range group[1:] {
+			if alert.Labels[key] != value {
+				shared = false
+				break
+			}
+		}
+		if shared && key != "alertname" {
+			msg.Fields[key] = value
+		}
+	}
+
+	lines := make([]string, 0, len(group))
+	for _, alert := range group {
+		lines = append(lines, "- "+alert.Summary)
+	}
+	msg.Body = strings.Join(lines, "\n")
+	return msg
+}
@@ -0,0 +1,214 @@
+package alert
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"golkube/pkg/kube"
+	"golkube/pkg/notify"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Condition types supported by alert rules
+const (
+	ConditionPodNotReady           = "pod_not_ready"
+	ConditionPodRestarts           = "pod_restarts"
+	ConditionDeploymentUnavailable = "deployment_unavailable"
+	ConditionWarningEvent          = "warning_event"
+)
+
+// defaultEventWindow is how long a matching Warning event keeps a warning_event alert active
+const defaultEventWindow = 5 * time.Minute
+
+// Config mirrors the "alerting" block of the configuration file
+type Config struct {
+	// RepeatInterval controls how often a still-firing alert is re-notified
+	RepeatInterval time.Duration `mapstructure:"repeat_interval"`
+	// SilencesFile is where silences created from the CLI are stored
+	SilencesFile string `mapstructure:"silences_file"`
+	Rules        []Rule `mapstructure:"rules"`
+}
+
+// Rule is a declarative alert rule
+type Rule struct {
+	Name      string            `mapstructure:"name"`
+	Condition Condition         `mapstructure:"condition"`
+	Severity  notify.Severity   `mapstructure:"severity"`
+	Labels    map[string]string `mapstructure:"labels"`
+	// Route lists the notification channels (slack, email, ...) for this rule; empty means all
+	Route []string `mapstructure:"route"`
+}
+
+// Condition describes when a rule is active
+type Condition struct {
+	Type string `mapstructure:"type"`
+	// For is how long the condition must hold before the alert fires
+	For time.Duration `mapstructure:"for"`
+	// Threshold is the value that must be exceeded (restarts, unavailable replicas)
+	Threshold int32 `mapstructure:"threshold"`
+	// Window is the look-back period for restart and event conditions
+	Window time.Duration `mapstructure:"window"`
+	// Reason is a regular expression matched against Warning event reasons
+	Reason string `mapstructure:"reason"`
+
+	reason *regexp.Regexp
+}
+
+// Validate checks the rule and compiles its condition
+func (r *Rule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("rule name is required")
+	}
+	if r.Severity == "" {
+		r.Severity = notify.SeverityWarning
+	}
+
+	switch r.Condition.Type {
+	case ConditionPodNotReady, ConditionDeploymentUnavailable:
+	case ConditionPodRestarts:
+		if r.Condition.Window <= 0 {
+			return fmt.Errorf("rule %s: pod_restarts requires a window", r.Name)
+		}
+	case ConditionWarningEvent:
+		if r.Condition.Window <= 0 {
+			r.Condition.Window = defaultEventWindow
+		}
+		pattern := r.Condition.Reason
+		if pattern == "" {
+			pattern = ".*"
+		}
+		compiled, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return fmt.Errorf("rule %s: invalid reason pattern: %w", r.Name, err)
+		}
+		r.Condition.reason = compiled
+	default:
+		return fmt.Errorf("rule %s: unknown condition type %q", r.Name, r.Condition.Type)
+	}
+	return nil
+}
+
+// Describe renders the rule's condition in human-readable form
+func (r *Rule) Describe() string {
+	c := r.Condition
+	var description string
+	switch c.Type {
+	case ConditionPodNotReady:
+		description = "pod not ready"
+	case ConditionPodRestarts:
+		description = fmt.Sprintf("pod restarts > %d in %s", c.Threshold, c.Window)
+	case ConditionDeploymentUnavailable:
+		description = fmt.Sprintf("deployment unavailable replicas > %d", c.Threshold)
+	case ConditionWarningEvent:
+		description = fmt.Sprintf("warning event reason matches %q within %s", c.Reason, c.Window)
+	default:
+		description = c.Type
+	}
+	if c.For > 0 {
+		description += fmt.Sprintf(" for %s", c.For)
+	}
+	return description
+}
+
+// candidate is an object for which a rule's condition currently holds
+type candidate struct {
+	labels  map[string]string
+	summary string
+}
+
+// restartSample records a pod's total restart count at a point in time
+type restartSample struct {
+	at       time.Time
+	restarts int32
+}
+
+// evaluate returns the candidates for which the rule's condition holds in the snapshot
+func (r *Rule) evaluate(snapshot kube.MonitorSnapshot, history map[string][]restartSample) []candidate {
+	var candidates []candidate
+
+	switch r.Condition.Type {
+	case ConditionPodNotReady:
+		for i := range snapshot.Pods {
+			diagnosis := kube.DiagnosePod(&snapshot.Pods[i])
+			if diagnosis.Healthy() || diagnosis.Health == kube.PodHealthTerminating {
+				continue
+			}
+			candidates = append(candidates, candidate{
+				labels:  map[string]string{"namespace": diagnosis.Namespace, "pod": diagnosis.Name},
+				summary: fmt.Sprintf("Pod %s is %s", diagnosis.Name, diagnosis),
+			})
+		}
+
+	case ConditionPodRestarts:
+		for i := range snapshot.Pods {
+			pod := &snapshot.Pods[i]
+			samples := history[pod.Namespace+"/"+pod.Name]
+			if len(samples) < 2 {
+				continue
+			}
+			// Compare against the oldest sample inside this rule's window
+			oldest := samples[len(samples)-1]
+			for _, sample := range samples {
+				if snapshot.Time.Sub(sample.at) <= r.Condition.Window {
+					oldest = sample
+					break
+				}
+			}
+			delta := samples[len(samples)-1].restarts - oldest.restarts
+			if delta > r.Condition.Threshold {
+				candidates = append(candidates, candidate{
+					labels:  map[string]string{"namespace": pod.Namespace, "pod": pod.Name},
+					summary: fmt.Sprintf("Pod %s restarted %d times in the last %s", pod.Name, delta, r.Condition.Window),
+				})
+			}
+		}
+
+	case ConditionDeploymentUnavailable:
+		for _, deployment := range snapshot.Deployments {
+			if deployment.Status.UnavailableReplicas > r.Condition.Threshold {
+				candidates = append(candidates, candidate{
+					labels: map[string]string{"namespace": deployment.Namespace, "deployment": deployment.Name},
+					summary: fmt.Sprintf("Deployment %s has %d unavailable replicas",
+						deployment.Name, deployment.Status.UnavailableReplicas),
+				})
+			}
+		}
+
+	case ConditionWarningEvent:
+		for i := range snapshot.Events {
+			event := &snapshot.Events[i]
+			if event.Type != corev1.EventTypeWarning || !r.Condition.reason.MatchString(event.Reason) {
+				continue
+			}
+			if snapshot.Time.Sub(kube.EventLastSeen(event)) > r.Condition.Window {
+				continue
+			}
+			object := strings.ToLower(event.InvolvedObject.Kind) + "/" + event.InvolvedObject.Name
+			candidates = append(candidates, candidate{
+				labels:  map[string]string{"namespace": event.Namespace, "object": object, "reason": event.Reason},
+				summary: fmt.Sprintf("%s %s: %s", object, event.Reason, strings.TrimSpace(event.Message)),
+			})
+		}
+	}
+
+	return candidates
+}
+
+// fingerprint identifies an alert by rule name and sorted labels
+func fingerprint(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, key+"="+labels[key])
+	}
+	return strings.Join(parts, ",")
+}
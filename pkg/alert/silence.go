@@ -0,0 +1,190 @@
+package alert
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultSilencesFile is used when the alerting config does not set silences_file
+const DefaultSilencesFile = ".golkube/silences.json"
+
+// Silence suppresses notifications for alerts whose labels match every matcher
+type Silence struct {
+	ID        string            `json:"id"`
+	Matchers  map[string]string `json:"matchers"`
+	StartsAt  time.Time         `json:"startsAt"`
+	EndsAt    time.Time         `json:"endsAt"`
+	CreatedBy string            `json:"createdBy,omitempty"`
+	Comment   string            `json:"comment,omitempty"`
+}
+
+// Active reports whether the silence is in effect at the given time
+func (s Silence) Active(now time.Time) bool {
+	return !now.Before(s.StartsAt) && now.Before(s.EndsAt)
+}
+
+// Matches reports whether the silence applies to an alert with the given labels
+func (s Silence) Matches(labels map[string]string) bool {
+	for key, value := range s.Matchers {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// String renders the matchers as key=value pairs
+func (s Silence) String() string {
+	return fingerprint(s.Matchers)
+}
+
+// SilenceStore persists silences to a JSON file so they can be managed from the CLI
+// while a monitor is running in another process.
+type SilenceStore struct {
+	path string
+}
+
+// NewSilenceStore creates a store backed by the given file
+func NewSilenceStore(path string) *SilenceStore {
+	if path == "" {
+		path = DefaultSilencesFile
+	}
+	return &SilenceStore{path: path}
+}
+
+// List returns all stored silences, including expired ones
+func (s *SilenceStore) List() ([]Silence, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read silences file: %w", err)
+	}
+
+	var silences []Silence
+	if err := json.Unmarshal(data, &silences); err != nil {
+		return nil, fmt.Errorf("failed to parse silences file: %w", err)
+	}
+	return silences, nil
+}
+
+// Add stores a new silence and returns it with its generated ID
+func (s *SilenceStore) Add(matchers map[string]string, duration time.Duration, createdBy, comment string) (Silence, error) {
+	if len(matchers) == 0 {
+		return Silence{}, fmt.Errorf("at least one matcher is required")
+	}
+	if duration <= 0 {
+		return Silence{}, fmt.Errorf("duration must be positive")
+	}
+
+	silences, err := s.List()
+	if err != nil {
+		return Silence{}, err
+	}
+
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return Silence{}, fmt.Errorf("failed to generate silence id: %w", err)
+	}
+
+	now := time.Now()
+	silence := Silence{
+		ID:        hex.EncodeToString(id),
+		Matchers:  matchers,
+		StartsAt:  now,
+		EndsAt:    now.Add(duration),
+		CreatedBy: createdBy,
+		Comment:   comment,
+	}
+
+	// Drop expired silences while rewriting the file
+	var kept []Silence
+	for _, existing := range silences {
+		if existing.EndsAt.After(now) {
+			kept = append(kept, existing)
+		}
+	}
+	kept = append(kept, silence)
+
+	return silence, s.save(kept)
+}
+
+// Remove expires the silence with the given ID
+func (s *SilenceStore) Remove(id string) error {
+	silences, err := s.List()
+	if err != nil {
+		return err
+	}
+
+	var kept []Silence
+	found := false
+	for _, silence := range silences {
+		if silence.ID == id {
+			found = true
+			continue
+		}
+		kept = append(kept, silence)
+	}
+	if !found {
+		return fmt.Errorf("silence %s not found", id)
+	}
+	return s.save(kept)
+}
+
+// Silenced returns the first active silence matching the labels, if any
+func (s *SilenceStore) Silenced(labels map[string]string, now time.Time) (*Silence, error) {
+	silences, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, silence := range silences {
+		if silence.Active(now) && silence.Matches(labels) {
+			return &silence, nil
+		}
+	}
+	return nil, nil
+}
+
+// save writes silences to the store file, sorted by expiry
+func (s *SilenceStore) save(silences []Silence) error {
+	sort.Slice(silences, func(i, j int) bool { return silences[i].EndsAt.Before(silences[j].EndsAt) })
+
+	data, err := json.MarshalIndent(silences, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode silences: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create silences directory: %w", err)
+	}
+
+	// Write atomically so a running monitor never reads a partial file
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write silences file: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace silences file: %w", err)
+	}
+	return nil
+}
+
+// ParseMatchers parses key=value matchers as given on the command line
+func ParseMatchers(values []string) (map[string]string, error) {
+	matchers := make(map[string]string, len(values))
+	for _, value := range values {
+		key, val, found := strings.Cut(value, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid matcher %q: expected key=value", value)
+		}
+		matchers[key] = val
+	}
+	return matchers, nil
+}
@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golkube/pkg/alert"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// RegisterAlertCommands registers commands for alert rules and silences.
+func RegisterAlertCommands() {
+	alertsCmd := &cobra.Command{
+		Use:   "alerts",
+		Short: "Manage monitor alert rules and silences",
+	}
+
+	// Subcommand to list and validate the configured rules
+	alertsCmd.AddCommand(&cobra.Command{
+		Use:   "rules",
+		Short: "List the configured alert rules",
+		Run: func(cmd *cobra.Command, args []string) {
+			config := loadAlertConfig()
+			engine, err := alert.NewEngine(config, nil)
+			if err != nil {
+				log.Fatalf("Invalid alert rules: %v", err)
+			}
+
+			rules := engine.Rules()
+			if len(rules) == 0 {
+				fmt.Println("No alert rules configured.")
+				return
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tSEVERITY\tCONDITION\tROUTE\tLABELS")
+			for _, rule := range rules {
+				route := "all"
+				if len(rule.Route) > 0 {
+					route = strings.Join(rule.Route, ",")
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", rule.Name, rule.Severity, rule.Describe(), route, formatLabels(rule.Labels))
+			}
+			w.Flush()
+		},
+	})
+
+	silenceCmd := &cobra.Command{
+		Use:   "silence",
+		Short: "Manage alert silences",
+	}
+
+	addCmd := &cobra.Command{
+		Use:   "add",
+		Short: "Silence alerts matching the given labels",
+		Run: func(cmd *cobra.Command, args []string) {
+			matches, _ := cmd.Flags().GetStringArray("match")
+			duration, _ := cmd.Flags().GetDuration("duration")
+			comment, _ := cmd.Flags().GetString("comment")
+
+			matchers, err := alert.ParseMatchers(matches)
+			if err != nil {
+				log.Fatalf("Error parsing matchers: %v", err)
+			}
+
+			createdBy := ""
+			if current, err := user.Current(); err == nil {
+				createdBy = current.Username
+			}
+
+			store := alert.NewSilenceStore(loadAlertConfig().SilencesFile)
+			silence, err := store.Add(matchers, duration, createdBy, comment)
+			if err != nil {
+				log.Fatalf("Error adding silence: %v", err)
+			}
+			fmt.Printf("Silence %s created for {%s} until %s\n", silence.ID, silence, silence.EndsAt.Format(time.RFC3339))
+		},
+	}
+	addCmd.Flags().StringArray("match", nil, "Label matcher in key=value form (repeatable, e.g. alertname=PodNotReady)")
+	addCmd.Flags().Duration("duration", 2*time.Hour, "How long the silence lasts")
+	addCmd.Flags().String("comment", "", "Reason for the silence")
+	addCmd.MarkFlagRequired("match")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List active silences",
+		Run: func(cmd *cobra.Command, args []string) {
+			all, _ := cmd.Flags().GetBool("all")
+
+			store := alert.NewSilenceStore(loadAlertConfig().SilencesFile)
+			silences, err := store.List()
+			if err != nil {
+				log.Fatalf("Error listing silences: %v", err)
+			}
+
+			now := time.Now()
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tMATCHERS\tENDS\tCREATED BY\tCOMMENT")
+			for _, silence := range silences {
+				if !all && !silence.Active(now) {
+					continue
+				}
+				ends := "expired"
+				if silence.EndsAt.After(now) {
+					ends = "in " + silence.EndsAt.Sub(now).Round(time.Minute).String()
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", silence.ID, silence, ends, silence.CreatedBy, silence.Comment)
+			}
+			w.Flush()
+		},
+	}
+	listCmd.Flags().Bool("all", false, "Include expired silences")
+
+	removeCmd := &cobra.Command{
+		Use:   "remove <silence-id>",
+		Short: "Remove a silence",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			store := alert.NewSilenceStore(loadAlertConfig().SilencesFile)
+			if err := store.Remove(args[0]); err != nil {
+				log.Fatalf("Error removing silence: %v", err)
+			}
+			fmt.Printf("Silence %s removed\n", args[0])
+		},
+	}
+
+	silenceCmd.AddCommand(addCmd)
+	silenceCmd.AddCommand(listCmd)
+	silenceCmd.AddCommand(removeCmd)
+	alertsCmd.AddCommand(silenceCmd)
+
+	RootCmd.AddCommand(alertsCmd)
+}
+
+// loadAlertConfig reads the "alerting" block of the configuration
+func loadAlertConfig() alert.Config {
+	var config alert.Config
+	if err := viper.UnmarshalKey("alerting", &config); err != nil {
+		log.Fatalf("Error parsing alerting configuration: %v", err)
+	}
+	return config
+}
+
+// formatLabels renders labels as sorted key=value pairs
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return "-"
+	}
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
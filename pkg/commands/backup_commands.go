@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+
+	"golkube/pkg/kube"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// RegisterBackupCommands registers the namespace backup and restore commands.
+func RegisterBackupCommands(kubeClient *kube.KubeClient) {
+	backupCmd := &cobra.Command{
+		Use:   "backup [namespace]",
+		Short: "Export every namespaced resource to a directory, one YAML file per object",
+		Long: `Exports all namespaced resources found through discovery. Server-populated fields (uid,
+resourceVersion, status, managedFields) are stripped, and objects owned by a controller are
+left out because restoring their owner recreates them.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			out, _ := cmd.Flags().GetString("out")
+			namespace := viper.GetString("kubernetes.namespace")
+			if len(args) == 1 {
+				namespace = args[0]
+			}
+
+			if _, err := kubeClient.BackupNamespace(namespace, out); err != nil {
+				log.Fatalf("Error backing up namespace: %v", err)
+			}
+		},
+	}
+	backupCmd.Flags().String("out", "", "Directory to write the backup to")
+	backupCmd.MarkFlagRequired("out")
+
+	restoreCmd := &cobra.Command{
+		Use:   "restore <dir>",
+		Short: "Re-create the objects of a backup in dependency order",
+		Long: `Creates the Namespace, ServiceAccounts, ConfigMaps and Secrets, and then workloads from a backup
+directory. Objects that already exist are skipped. Pass --namespace to restore into a different
+namespace and clone the environment. Policies are not checked, since the objects were accepted
+when backed up; pass --enforce-policy to check them.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Without an explicit --namespace, objects go back to the namespace they came from
+			target := ""
+			if cmd.Flags().Changed("namespace") {
+				target = viper.GetString("kubernetes.namespace")
+			}
+
+			enforcePolicy, _ := cmd.Flags().GetBool("enforce-policy")
+
+			result, err := kubeClient.RestoreNamespace(args[0], target, enforcePolicy)
+			if err != nil {
+				log.Fatalf("Error restoring backup: %v", err)
+			}
+			fmt.Printf("Restore finished: %d created, %d skipped, %d failed\n", result.Created, result.Skipped, result.Failed)
+			if result.Failed > 0 {
+				log.Fatalf("%d objects could not be restored", result.Failed)
+			}
+		},
+	}
+
+	restoreCmd.Flags().Bool("enforce-policy", false, "Check objects against the configured policies before creating them")
+
+	RootCmd.AddCommand(backupCmd)
+	RootCmd.AddCommand(restoreCmd)
+}
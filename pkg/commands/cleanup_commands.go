@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golkube/pkg/kube"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// RegisterCleanupCommands registers commands that find and remove unused resources.
+func RegisterCleanupCommands(kubeClient *kube.KubeClient) {
+	cleanupCmd := &cobra.Command{
+		Use:   "cleanup",
+		Short: "Find and remove unused resources",
+	}
+
+	orphansCmd := &cobra.Command{
+		Use:   "orphans",
+		Short: "Find ConfigMaps, Secrets, PVCs and Services that nothing uses",
+		Long: `Flags ConfigMaps, Secrets and PersistentVolumeClaims not referenced by any pod or pod template
+(volumes, envFrom, valueFrom, imagePullSecrets) and Services whose selectors match no pod or pod template.
+Resources chosen for deletion are written to the backup directory first.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			yes, _ := cmd.Flags().GetBool("yes")
+			backupDir, _ := cmd.Flags().GetString("backup-dir")
+			namespace := viper.GetString("kubernetes.namespace")
+
+			orphans, err := kubeClient.FindOrphans(namespace)
+			if err != nil {
+				log.Fatalf("Error finding orphaned resources: %v", err)
+			}
+			if len(orphans) == 0 {
+				fmt.Printf("No orphaned resources found in namespace %s.\n", namespace)
+				return
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "KIND\tNAME\tREASON")
+			for _, orphan := range orphans {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", orphan.Kind, orphan.Name, orphan.Reason)
+			}
+			w.Flush()
+
+			selected := orphans
+			if !yes {
+				if !isInteractive() {
+					fmt.Println("\nRun with --yes to delete these resources.")
+					return
+				}
+				selected = selectOrphans(orphans)
+			}
+			if len(selected) == 0 {
+				fmt.Println("Nothing deleted.")
+				return
+			}
+
+			if backupDir == "" {
+				backupDir = filepath.Join(".golkube", "backups",
+					fmt.Sprintf("orphans-%s-%s", namespace, time.Now().Format("20060102-150405")))
+			}
+			for _, orphan := range selected {
+				backup := orphan.Object.DeepCopy()
+				kube.StripServerFields(backup)
+				if _, err := kube.WriteManifest(backupDir, backup); err != nil {
+					log.Fatalf("Error writing backup, nothing deleted: %v", err)
+				}
+			}
+			fmt.Printf("Backed up %d resources to %s\n", len(selected), backupDir)
+
+			failed := 0
+			for _, orphan := range selected {
+				if err := kubeClient.DeleteOrphan(orphan); err != nil {
+					fmt.Printf("✗ %v\n", err)
+					failed++
+				}
+			}
+			if failed > 0 {
+				log.Fatalf("%d of %d deletions failed", failed, len(selected))
+			}
+		},
+	}
+	orphansCmd.Flags().Bool("yes", false, "Delete every orphaned resource without prompting")
+	orphansCmd.Flags().String("backup-dir", "", "Where to write backups before deleting (default .golkube/backups/orphans-<namespace>-<time>)")
+
+	cleanupCmd.AddCommand(orphansCmd)
+	RootCmd.AddCommand(cleanupCmd)
+}
+
+// isInteractive reports whether stdin is a terminal that can answer prompts
+func isInteractive() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// selectOrphans asks about each orphan in turn and returns the ones to delete
+func selectOrphans(orphans []kube.OrphanedResource) []kube.OrphanedResource {
+	reader := bufio.NewReader(os.Stdin)
+	var selected []kube.OrphanedResource
+
+	fmt.Println()
+	for i, orphan := range orphans {
+		fmt.Printf("Delete %s %s? [y]es/[N]o/[a]ll/[q]uit: ", strings.ToLower(orphan.Kind), orphan.Name)
+		answer, err := reader.ReadString('\n')
+		if err != nil {
+			return selected
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			selected = append(selected, orphan)
+		case "a", "all":
+			return append(selected, orphans[i:]...)
+		case "q", "quit":
+			return selected
+		}
+	}
+	return selected
+}
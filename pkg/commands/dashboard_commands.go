@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"log"
+	"time"
+
+	"golkube/pkg/dashboard"
+	"golkube/pkg/kube"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// RegisterDashboardCommands registers the interactive terminal dashboard.
+func RegisterDashboardCommands(kubeClient *kube.KubeClient) {
+	dashboardCmd := &cobra.Command{
+		Use:   "dashboard",
+		Short: "Interactive terminal dashboard for namespace health",
+		Long: `Shows live Deployments, Pods, recent Warning events and pod logs for the configured namespace.
+Select a Deployment and press Enter to drill into its pods, Enter on a pod to follow its logs,
+r to restart the selected workload and s to scale it.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			selector, _ := cmd.Flags().GetString("selector")
+			refresh, _ := cmd.Flags().GetDuration("refresh")
+			tail, _ := cmd.Flags().GetInt64("tail")
+
+			dash, err := dashboard.New(kubeClient, dashboard.Config{
+				Namespace:     viper.GetString("kubernetes.namespace"),
+				LabelSelector: selector,
+				Refresh:       refresh,
+				LogTail:       tail,
+			})
+			if err != nil {
+				log.Fatalf("Error starting dashboard: %v", err)
+			}
+			if err := dash.Run(); err != nil {
+				log.Fatalf("Dashboard error: %v", err)
+			}
+		},
+	}
+	dashboardCmd.Flags().StringP("selector", "l", "", "Label selector to filter deployments and pods")
+	dashboardCmd.Flags().Duration("refresh", 2*time.Second, "How often the panes refresh from the informer cache")
+	dashboardCmd.Flags().Int64("tail", 200, "Number of existing log lines to show when following a pod")
+
+	RootCmd.AddCommand(dashboardCmd)
+}
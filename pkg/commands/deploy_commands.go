@@ -0,0 +1,249 @@
+package commands
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	golConfig "golkube/pkg/config"
+	"golkube/pkg/kube"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// configHashAnnotation records the hash of the app's ConfigMap on the pod template, so
+// that changing the ConfigMap rolls the pods
+const configHashAnnotation = "golkube.io/config-hash"
+
+// appResources are the Kubernetes objects golkube deploy creates for an app config
+type appResources struct {
+	deployment kube.DeploymentConfig
+	service    *kube.ServiceConfig
+	configMap  *kube.ConfigMapConfig
+}
+
+// RegisterDeployCommands registers the one-shot app deploy command
+func RegisterDeployCommands(kubeClient *kube.KubeClient) {
+	deployCmd := &cobra.Command{
+		Use:   "deploy",
+		Short: "Deploy an app from an app config file",
+		Long: `Deploy the app described by an app config file (namespace, image, replicas, ports,
+env and an optional config_map) as a Deployment, a Service for the ports and a
+ConfigMap. Existing resources are updated in place, so running deploy again with
+an unchanged file changes nothing. Deploy waits for the rollout and prints the
+app's endpoints.
+
+Example app config:
+  name: web
+  namespace: default
+  image: registry.example.com/web:1.4.2
+  replicas: 2
+  ports: [8080]
+  env:
+    LOG_LEVEL: info
+  config_map:
+    FEATURE_FLAGS: "search,checkout"`,
+		Run: func(cmd *cobra.Command, args []string) {
+			file, _ := cmd.Flags().GetString("file")
+			serviceType, _ := cmd.Flags().GetString("service-type")
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+			noWait, _ := cmd.Flags().GetBool("no-wait")
+
+			appConfig, err := golConfig.ParseConfig(file)
+			if err != nil {
+				log.Fatalf("Error loading app config: %v", err)
+			}
+			resources, err := buildAppResources(appConfig, corev1.ServiceType(serviceType))
+			if err != nil {
+				log.Fatalf("Error in app config %s: %v", file, err)
+			}
+			deployment := resources.deployment
+
+			notifier := loadNotifier()
+			sendNotification(notifier, deployNotification("started", deployment, nil))
+			fail := func(format string, err error) {
+				sendNotification(notifier, deployNotification("failed", deployment, err))
+				log.Fatalf(format, err)
+			}
+
+			if resources.configMap != nil {
+				if err := kubeClient.ApplyConfigMap(*resources.configMap); err != nil {
+					fail("Error applying ConfigMap: %v", err)
+				}
+			}
+			if err := kubeClient.ApplyDeployment(deployment); err != nil {
+				fail("Error applying Deployment: %v", err)
+			}
+			if resources.service != nil {
+				if err := kubeClient.ApplyService(*resources.service); err != nil {
+					fail("Error applying Service: %v", err)
+				}
+			}
+
+			if !noWait {
+				fmt.Printf("Waiting for deployment %s to roll out...\n", deployment.Name)
+				if err := kubeClient.WaitForRollout(context.Background(), deployment.Name, deployment.Namespace, timeout); err != nil {
+					fail("Error waiting for rollout: %v", err)
+				}
+			}
+			sendNotification(notifier, deployNotification("succeeded", deployment, nil))
+
+			fmt.Printf("\n✓ Deployed %s to namespace %s\n", deployment.Name, deployment.Namespace)
+			printAppSummary(kubeClient, resources)
+		},
+	}
+	deployCmd.Flags().StringP("file", "f", "app.yaml", "App config file")
+	deployCmd.Flags().String("service-type", string(corev1.ServiceTypeClusterIP), "Service type: ClusterIP, NodePort or LoadBalancer")
+	deployCmd.Flags().Duration("timeout", 5*time.Minute, "How long to wait for the rollout")
+	deployCmd.Flags().Bool("no-wait", false, "Do not wait for the rollout to finish")
+
+	RootCmd.AddCommand(deployCmd)
+}
+
+// buildAppResources converts an app config to the objects to apply. Env and ConfigMap keys
+// are sorted so that deploying the same config twice yields the same pod template.
+func buildAppResources(appConfig *golConfig.Config, serviceType corev1.ServiceType) (appResources, error) {
+	name := appConfig.AppName()
+	if problems := validation.IsDNS1035Label(name); len(problems) > 0 {
+		return appResources{}, fmt.Errorf("invalid app name %q: %s; set name in the app config", name, strings.Join(problems, ", "))
+	}
+	switch serviceType {
+	case corev1.ServiceTypeClusterIP, corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer:
+	default:
+		return appResources{}, fmt.Errorf("unsupported service type %q", serviceType)
+	}
+
+	labels := map[string]string{"app": name}
+	var resources appResources
+	var env []corev1.EnvVar
+	for _, key := range sortedKeys(appConfig.Env) {
+		env = append(env, corev1.EnvVar{Name: key, Value: appConfig.Env[key]})
+	}
+
+	var annotations map[string]string
+	if len(appConfig.ConfigMap) > 0 {
+		configMapName := name + "-config"
+		hash := sha256.New()
+		for _, key := range sortedKeys(appConfig.ConfigMap) {
+			fmt.Fprintf(hash, "%s=%s\n", key, appConfig.ConfigMap[key])
+			env = append(env, corev1.EnvVar{
+				Name: key,
+				ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: configMapName},
+					Key:                  key,
+				}},
+			})
+		}
+		annotations = map[string]string{configHashAnnotation: hex.EncodeToString(hash.Sum(nil))[:16]}
+		resources.configMap = &kube.ConfigMapConfig{
+			Name:      configMapName,
+			Namespace: appConfig.Namespace,
+			Labels:    labels,
+			Data:      appConfig.ConfigMap,
+		}
+	}
+
+	var containerPort int32
+	if len(appConfig.Ports) > 0 {
+		containerPort = int32(appConfig.Ports[0])
+		service := &kube.ServiceConfig{
+			Name:      name,
+			Namespace: appConfig.Namespace,
+			Labels:    labels,
+			Selector:  labels,
+			Type:      serviceType,
+		}
+		for _, port := range appConfig.Ports {
+			service.Ports = append(service.Ports, corev1.ServicePort{
+				Name:       fmt.Sprintf("port-%d", port),
+				Port:       int32(port),
+				TargetPort: intstr.FromInt32(int32(port)),
+				Protocol:   corev1.ProtocolTCP,
+			})
+		}
+		resources.service = service
+	}
+
+	resources.deployment = kube.DeploymentConfig{
+		Name:             name,
+		Namespace:        appConfig.Namespace,
+		Replicas:         int32(appConfig.Replicas),
+		Image:            appConfig.Image,
+		ContainerName:    name,
+		ContainerPort:    containerPort,
+		Labels:           labels,
+		Annotations:      annotations,
+		Env:              env,
+		TerminationGrace: 30,
+	}
+	return resources, nil
+}
+
+// printAppSummary prints the deployed resources and the addresses the app is reachable at
+func printAppSummary(kubeClient *kube.KubeClient, resources appResources) {
+	deployment := resources.deployment
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "  Deployment\t%s\t%d replicas\t%s\n", deployment.Name, deployment.Replicas, deployment.Image)
+	if resources.configMap != nil {
+		fmt.Fprintf(w, "  ConfigMap\t%s\t%d keys\t\n", resources.configMap.Name, len(resources.configMap.Data))
+	}
+	if resources.service == nil {
+		w.Flush()
+		fmt.Println("No ports configured; the app has no Service.")
+		return
+	}
+
+	service, err := kubeClient.GetService(resources.service.Name, resources.service.Namespace)
+	if err != nil {
+		w.Flush()
+		log.Printf("Warning: %v", err)
+		return
+	}
+	fmt.Fprintf(w, "  Service\t%s\t%s\t%s\n", service.Name, service.Spec.Type, service.Spec.ClusterIP)
+	w.Flush()
+
+	fmt.Println("\nEndpoints:")
+	for _, port := range service.Spec.Ports {
+		fmt.Printf("  %s.%s.svc:%d (cluster)\n", service.Name, service.Namespace, port.Port)
+		if port.NodePort != 0 {
+			fmt.Printf("  <node-ip>:%d (node port)\n", port.NodePort)
+		}
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			fmt.Printf("  %s:%d (load balancer)\n", firstNonEmpty(ingress.IP, ingress.Hostname), port.Port)
+		}
+	}
+	if service.Spec.Type == corev1.ServiceTypeLoadBalancer && len(service.Status.LoadBalancer.Ingress) == 0 {
+		fmt.Println("  load balancer address pending")
+	}
+
+	addresses, err := kubeClient.ServiceEndpoints(service.Name, service.Namespace)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+		return
+	}
+	if len(addresses) == 0 {
+		fmt.Println("  no ready pod endpoints yet")
+		return
+	}
+	fmt.Printf("  %s (pods)\n", strings.Join(addresses, ", "))
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"golkube/pkg/dev"
+	"golkube/pkg/kube"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// RegisterDevCommands registers the inner-loop development command
+func RegisterDevCommands(kubeClient *kube.KubeClient) {
+	devCmd := &cobra.Command{
+		Use:   "dev",
+		Short: "Rebuild and redeploy a Deployment whenever its sources change",
+		Long: `Watch the build context, rebuild the image on every change, roll it out to the
+Deployment and stream the logs of the new pods.
+
+Images are tagged with a hash of the build context, so unchanged sources are not
+rebuilt and every rollout gets a distinct tag. Rapid changes such as a save-all in
+an editor are combined into one rebuild. Changes under a sync source directory are
+copied straight into the running containers instead of rebuilding, which suits
+interpreted languages that reload their sources.
+
+Settings come from the "dev" block of the configuration file; flags override them.
+
+Examples:
+  golkube dev --deployment web
+  golkube dev --deployment api --sync app:/srv/app`,
+		Run: func(cmd *cobra.Command, args []string) {
+			var config dev.Config
+			if err := viper.UnmarshalKey("dev", &config); err != nil {
+				log.Fatalf("Error loading dev settings: %v", err)
+			}
+			if config.Context == "" {
+				config.Context = viper.GetString("build.context")
+			}
+			if config.Dockerfile == "" {
+				config.Dockerfile = viper.GetString("build.dockerfile")
+			}
+			config.RegistryURL = viper.GetString("registry.url")
+			if !viper.IsSet("dev.logs") {
+				config.Logs = true
+			}
+
+			if cmd.Flags().Changed("deployment") {
+				config.Deployment, _ = cmd.Flags().GetString("deployment")
+			}
+			if cmd.Flags().Changed("container") {
+				config.Container, _ = cmd.Flags().GetString("container")
+			}
+			if cmd.Flags().Changed("image") {
+				config.Image, _ = cmd.Flags().GetString("image")
+			}
+			if cmd.Flags().Changed("push") {
+				config.Push, _ = cmd.Flags().GetBool("push")
+			}
+			if cmd.Flags().Changed("debounce") {
+				config.Debounce, _ = cmd.Flags().GetDuration("debounce")
+			}
+			if cmd.Flags().Changed("logs") {
+				config.Logs, _ = cmd.Flags().GetBool("logs")
+			}
+			syncRules, _ := cmd.Flags().GetStringArray("sync")
+			for _, rule := range syncRules {
+				src, dest, found := strings.Cut(rule, ":")
+				if !found {
+					log.Fatalf("Invalid --sync value %q: expected <local dir>:<container dir>", rule)
+				}
+				config.Sync = append(config.Sync, dev.SyncRule{Src: src, Dest: dest})
+			}
+
+			loop, err := dev.NewLoop(kubeClient, config, viper.GetString("kubernetes.namespace"))
+			if err != nil {
+				log.Fatalf("Error starting dev mode: %v", err)
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			if err := loop.Run(ctx); err != nil {
+				log.Fatalf("Error in dev mode: %v", err)
+			}
+		},
+	}
+	devCmd.Flags().String("deployment", "", "Deployment to update (overrides dev.deployment)")
+	devCmd.Flags().String("container", "", "Container to update; defaults to the first container")
+	devCmd.Flags().String("image", "", "Image repository to tag builds with; defaults to the deployment name")
+	devCmd.Flags().Bool("push", false, "Push each image to the registry, for clusters that cannot use local images")
+	devCmd.Flags().Duration("debounce", dev.DefaultDebounce, "Wait this long after the last change before rebuilding")
+	devCmd.Flags().Bool("logs", true, "Stream the logs of the new pods after each rollout")
+	devCmd.Flags().StringArray("sync", nil, "Copy changes under a local directory into the container instead of rebuilding, as <local dir>:<container dir> (repeatable)")
+
+	RootCmd.AddCommand(devCmd)
+}
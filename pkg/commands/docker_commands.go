@@ -5,6 +5,8 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 
 	"golkube/pkg/docker"
 	"golkube/pkg/registry"
@@ -49,6 +51,20 @@ func validateDockerCredentials() (string, string) {
 	return username, password
 }
 
+// lastBuildFile records the tag of the last image built by "docker build", which
+// "docker push" pushes unless given an image explicitly
+const lastBuildFile = ".golkube/last-build"
+
+// contextImageTag tags the golkube image with the hash of the build context, so that every
+// distinct build gets its own tag and unchanged sources map to the same one
+func contextImageTag(username string) (string, error) {
+	hash, err := docker.ContextHash(viper.GetString("build.context"), viper.GetString("build.dockerfile"))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/golkube:%s", username, hash[:12]), nil
+}
+
 // Build Docker image.
 func buildDockerCmd() *cobra.Command {
 	return &cobra.Command{
@@ -59,7 +75,10 @@ func buildDockerCmd() *cobra.Command {
 			log.Printf("Using Docker credentials: username=%s", username)
 
 			// Use the proper tag with namespace
-			tag := fmt.Sprintf("%s/golkube:latest", username)
+			tag, err := contextImageTag(username)
+			if err != nil {
+				log.Fatalf("Error tagging image: %v", err)
+			}
 			contextDir := viper.GetString("build.context")
 			dockerfile := viper.GetString("build.dockerfile")
 
@@ -74,6 +93,9 @@ func buildDockerCmd() *cobra.Command {
 			}
 
 			log.Println("Image built successfully.")
+			if err := recordBuiltImage(tag); err != nil {
+				log.Printf("Warning: %v", err)
+			}
 
 			// Push the image automatically after build
 			log.Printf("Pushing image '%s' to Docker Hub...", tag)
@@ -91,13 +113,25 @@ func buildDockerCmd() *cobra.Command {
 // Push Docker image to registry.
 func pushDockerCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "push",
+		Use:   "push [image:tag]",
 		Short: "Push a Docker image to a registry",
+		Long: `Push a Docker image to a registry.
+
+Without an argument the image tagged by the last "docker build" is pushed, as recorded
+in ` + lastBuildFile + `.`,
+		Args: cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			username, _ := validateDockerCredentials()
+			validateDockerCredentials()
 
-			// Ensure the correct tag with namespace
-			imageTag := fmt.Sprintf("%s/golkube:latest", username)
+			var imageTag string
+			if len(args) == 1 {
+				imageTag = args[0]
+			} else {
+				var err error
+				if imageTag, err = lastBuiltImage(); err != nil {
+					log.Fatalf("Error: %v", err)
+				}
+			}
 			log.Printf("Pushing Docker image with tag '%s'...", imageTag)
 
 			// Push the Docker image
@@ -112,6 +146,33 @@ func pushDockerCmd() *cobra.Command {
 	}
 }
 
+// recordBuiltImage stores the tag of a freshly built image for a later push
+func recordBuiltImage(tag string) error {
+	if err := os.MkdirAll(filepath.Dir(lastBuildFile), 0755); err != nil {
+		return fmt.Errorf("failed to record built image: %w", err)
+	}
+	if err := os.WriteFile(lastBuildFile, []byte(tag+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to record built image: %w", err)
+	}
+	return nil
+}
+
+// lastBuiltImage returns the tag recorded by the last build
+func lastBuiltImage() (string, error) {
+	data, err := os.ReadFile(lastBuildFile)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("no built image recorded in %s; run \"docker build\" or pass an image:tag", lastBuildFile)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read built image: %w", err)
+	}
+	tag := strings.TrimSpace(string(data))
+	if tag == "" {
+		return "", fmt.Errorf("no built image recorded in %s; run \"docker build\" or pass an image:tag", lastBuildFile)
+	}
+	return tag, nil
+}
+
 // Run a Docker container.
 func runDockerCmd() *cobra.Command {
 	runCmd := &cobra.Command{
@@ -0,0 +1,570 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golkube/pkg/alert"
+	"golkube/pkg/kube"
+	"golkube/pkg/notify"
+	"golkube/pkg/policy"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/versions"
+	"github.com/docker/docker/client"
+	"github.com/spf13/viper"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// doctorTimeout bounds each network call doctor makes
+const doctorTimeout = 10 * time.Second
+
+// doctorOptions are the command line overrides for the configured values
+type doctorOptions struct {
+	configFile string
+	kubeconfig string
+	context    string
+	namespace  string
+	// contexts and allContexts select several clusters, checked up to parallel at a time
+	contexts    []string
+	allContexts bool
+	parallel    int
+}
+
+// knownConfigSections are the top-level configuration keys golkube reads
+var knownConfigSections = []string{
+	"docker", "kubernetes", "build", "registry", "monitoring",
+	"alerting", "logging", "pipeline", "notifications", "policy", "validation", "report",
+	"quotas", "dev",
+}
+
+// configDurations are configuration keys that must parse as durations when set. Durations
+// in the alerting and notifications sections are checked when those sections are decoded.
+var configDurations = []string{"docker.timeout", "monitoring.interval", "dev.debounce", "dev.rollout_timeout"}
+
+// permissionCheck is a group of API permissions that one area of golkube needs
+type permissionCheck struct {
+	feature string
+	// required permissions fail the check when denied; the others only warn
+	required    bool
+	clusterWide bool
+	rules       []authorizationv1.ResourceAttributes
+}
+
+// doctorPermissions lists the permissions golkube's commands use
+var doctorPermissions = []permissionCheck{
+	{feature: "pods", required: true, rules: []authorizationv1.ResourceAttributes{
+		{Verb: "list", Resource: "pods"},
+		{Verb: "watch", Resource: "pods"},
+		{Verb: "delete", Resource: "pods"},
+		{Verb: "get", Resource: "pods", Subresource: "log"},
+	}},
+	{feature: "deployments", required: true, rules: []authorizationv1.ResourceAttributes{
+		{Verb: "list", Group: "apps", Resource: "deployments"},
+		{Verb: "watch", Group: "apps", Resource: "deployments"},
+		{Verb: "create", Group: "apps", Resource: "deployments"},
+		{Verb: "update", Group: "apps", Resource: "deployments"},
+		{Verb: "patch", Group: "apps", Resource: "deployments"},
+		{Verb: "delete", Group: "apps", Resource: "deployments"},
+		{Verb: "update", Group: "apps", Resource: "deployments", Subresource: "scale"},
+	}},
+	{feature: "services and configmaps", rules: []authorizationv1.ResourceAttributes{
+		{Verb: "create", Resource: "services"},
+		{Verb: "list", Resource: "services"},
+		{Verb: "delete", Resource: "services"},
+		{Verb: "create", Resource: "configmaps"},
+		{Verb: "list", Resource: "configmaps"},
+		{Verb: "delete", Resource: "configmaps"},
+	}},
+	{feature: "events", rules: []authorizationv1.ResourceAttributes{
+		{Verb: "list", Resource: "events"},
+		{Verb: "watch", Resource: "events"},
+	}},
+	{feature: "backup and cleanup", rules: []authorizationv1.ResourceAttributes{
+		{Verb: "list", Resource: "secrets"},
+		{Verb: "list", Resource: "persistentvolumeclaims"},
+		{Verb: "list", Group: "apps", Resource: "statefulsets"},
+	}},
+	{feature: "metrics", rules: []authorizationv1.ResourceAttributes{
+		{Verb: "list", Group: "metrics.k8s.io", Resource: "pods"},
+	}},
+	{feature: "nodes and events across namespaces", clusterWide: true, rules: []authorizationv1.ResourceAttributes{
+		{Verb: "list", Resource: "nodes"},
+		{Verb: "watch", Resource: "nodes"},
+		{Verb: "list", Resource: "events"},
+		{Verb: "watch", Resource: "events"},
+	}},
+	{feature: "node maintenance", clusterWide: true, rules: []authorizationv1.ResourceAttributes{
+		{Verb: "list", Resource: "nodes"},
+		{Verb: "patch", Resource: "nodes"},
+		{Verb: "create", Resource: "pods", Subresource: "eviction"},
+	}},
+}
+
+// runDoctorChecks runs every check in dependency order. Checks whose prerequisites
+// failed are reported as skipped rather than failing again with a less useful error.
+func runDoctorChecks(options doctorOptions) *doctorReport {
+	report := &doctorReport{}
+
+	configLoaded := checkConfig(report, options.configFile)
+
+	kubeconfig := firstNonEmpty(options.kubeconfig, viper.GetString("kubernetes.kubeconfig"), "~/.kube/config")
+	namespace := firstNonEmpty(options.namespace, viper.GetString("kubernetes.namespace"), "default")
+
+	contexts := []string{firstNonEmpty(options.context, viper.GetString("kubernetes.context"))}
+	if options.allContexts {
+		all, _, err := kube.ListContexts(kubeconfig)
+		if err != nil {
+			report.fail("Kubeconfig", err.Error(), "set kubernetes.kubeconfig in the configuration or pass --kubeconfig")
+		}
+		contexts = all
+	} else if len(options.contexts) > 0 {
+		contexts = options.contexts
+	}
+
+	// Clusters are checked concurrently and reported in the order they were selected
+	reports := make([]*doctorReport, len(contexts))
+	semaphore := make(chan struct{}, max(options.parallel, 1))
+	var wg sync.WaitGroup
+	for i, contextName := range contexts {
+		wg.Add(1)
+		go func(i int, contextName string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			reports[i] = &doctorReport{}
+			checkCluster(reports[i], kubeconfig, contextName, namespace)
+		}(i, contextName)
+	}
+	wg.Wait()
+	for i, clusterReport := range reports {
+		for _, result := range clusterReport.results {
+			if len(contexts) > 1 {
+				result.Check += " [" + contexts[i] + "]"
+			}
+			report.results = append(report.results, result)
+		}
+	}
+
+	dockerClient := checkDocker(report)
+	checkRegistry(report, dockerClient)
+	if dockerClient != nil {
+		dockerClient.Close()
+	}
+
+	if configLoaded {
+		checkPipeline(report, viper.GetString("pipeline.config_file"))
+	} else {
+		report.warn("Pipeline file", "skipped: the configuration file could not be loaded", "")
+	}
+	return report
+}
+
+// checkCluster checks the kubeconfig context, API access and permissions of one cluster
+func checkCluster(report *doctorReport, kubeconfig, contextName, namespace string) {
+	if !checkKubeconfig(report, kubeconfig, contextName) {
+		report.warn("Kubernetes API", "skipped: no usable kubeconfig context", "")
+		report.warn("RBAC permissions", "skipped: no usable kubeconfig context", "")
+		return
+	}
+	kc := checkKubernetesAPI(report, kubeconfig, contextName)
+	if kc == nil {
+		report.warn("RBAC permissions", "skipped: the Kubernetes API is unreachable", "")
+		return
+	}
+	checkPermissions(report, kc, namespace)
+}
+
+// checkConfig reads the configuration file into viper and validates the settings golkube uses
+func checkConfig(report *doctorReport, configFile string) bool {
+	const check = "Config file"
+	if configFile == "" {
+		configFile = "configs/default.yaml"
+	}
+
+	if _, err := os.Stat(configFile); err != nil {
+		report.fail(check, fmt.Sprintf("%s: %v", configFile, err),
+			"pass --config <file> or run golkube from the directory containing configs/default.yaml")
+		return false
+	}
+	viper.SetConfigFile(configFile)
+	if err := viper.ReadInConfig(); err != nil {
+		report.fail(check, fmt.Sprintf("failed to parse %s: %v", configFile, err),
+			"fix the YAML syntax at the reported line")
+		return false
+	}
+
+	var problems, unknown []string
+	seen := make(map[string]bool, len(knownConfigSections))
+	for _, section := range knownConfigSections {
+		seen[section] = true
+	}
+	for _, key := range viper.AllKeys() {
+		section, _, _ := strings.Cut(key, ".")
+		if !seen[section] {
+			seen[section] = true
+			unknown = append(unknown, section)
+		}
+	}
+
+	if viper.GetString("kubernetes.namespace") == "" {
+		problems = append(problems, "kubernetes.namespace is empty")
+	}
+	for _, key := range configDurations {
+		if value := viper.GetString(key); value != "" {
+			if _, err := time.ParseDuration(value); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: invalid duration %q", key, value))
+			}
+		}
+	}
+
+	var alertConfig alert.Config
+	if err := viper.UnmarshalKey("alerting", &alertConfig); err != nil {
+		problems = append(problems, "alerting: "+oneLine(err))
+	}
+	for i := range alertConfig.Rules {
+		if err := alertConfig.Rules[i].Validate(); err != nil {
+			problems = append(problems, fmt.Sprintf("alerting rule %d: %v", i+1, err))
+		}
+	}
+
+	notifyConfig, err := loadNotifyConfig()
+	if err == nil {
+		_, err = notify.FromConfig(notifyConfig)
+	}
+	if err != nil {
+		problems = append(problems, "notifications: "+oneLine(err))
+	}
+
+	policyConfig, err := loadPolicyConfig()
+	if err == nil {
+		_, err = policy.NewEngine(policyConfig)
+	}
+	if err != nil {
+		problems = append(problems, "policy: "+oneLine(err))
+	}
+	if _, err := loadQuotaSettings(); err != nil {
+		problems = append(problems, oneLine(err))
+	}
+
+	switch {
+	case len(problems) > 0:
+		report.fail(check, fmt.Sprintf("%s is invalid: %s", configFile, strings.Join(problems, "; ")),
+			"correct the listed settings; see configs/default.yaml for the expected format")
+	case len(unknown) > 0:
+		sort.Strings(unknown)
+		report.warn(check, fmt.Sprintf("%s has unknown sections: %s", configFile, strings.Join(unknown, ", ")),
+			"check the section names for typos; unknown sections are ignored")
+	default:
+		report.pass(check, configFile+" parsed and validated")
+	}
+	return true
+}
+
+// checkKubeconfig verifies the kubeconfig file loads and the selected context is complete
+func checkKubeconfig(report *doctorReport, kubeconfig, contextName string) bool {
+	const check = "Kubeconfig"
+	path, err := kube.ResolveKubeconfigPath(kubeconfig)
+	if err != nil {
+		report.fail(check, err.Error(), "set kubernetes.kubeconfig in the configuration or pass --kubeconfig")
+		return false
+	}
+	config, err := clientcmd.LoadFromFile(path)
+	if err != nil {
+		report.fail(check, fmt.Sprintf("failed to load %s: %v", path, err), "check the file is a valid kubeconfig")
+		return false
+	}
+
+	if contextName == "" {
+		contextName = config.CurrentContext
+	}
+	if contextName == "" {
+		report.fail(check, path+" has no current context",
+			"run `kubectl config use-context <name>` or set kubernetes.context")
+		return false
+	}
+	kubeContext, ok := config.Contexts[contextName]
+	if !ok {
+		names, _, _ := kube.ListContexts(path)
+		report.fail(check, fmt.Sprintf("context %q not found in %s", contextName, path),
+			"use one of: "+strings.Join(names, ", "))
+		return false
+	}
+	if _, ok := config.Clusters[kubeContext.Cluster]; !ok {
+		report.fail(check, fmt.Sprintf("context %q refers to missing cluster %q", contextName, kubeContext.Cluster),
+			"add the cluster entry or fix the context in "+path)
+		return false
+	}
+	if _, ok := config.AuthInfos[kubeContext.AuthInfo]; !ok {
+		report.fail(check, fmt.Sprintf("context %q refers to missing user %q", contextName, kubeContext.AuthInfo),
+			"add the user entry or fix the context in "+path)
+		return false
+	}
+
+	report.pass(check, fmt.Sprintf("%s, context %q (cluster %s, user %s)", path, contextName, kubeContext.Cluster, kubeContext.AuthInfo))
+	return true
+}
+
+// checkKubernetesAPI connects to the API server and compares its version with client-go's.
+// It returns the client when the server is reachable.
+func checkKubernetesAPI(report *doctorReport, kubeconfig, contextName string) *kube.KubeClient {
+	const check = "Kubernetes API"
+	kc, err := kube.NewKubeClientForContext(kubeconfig, contextName)
+	if err != nil {
+		report.fail(check, err.Error(), "check the cluster and user entries of the context")
+		return nil
+	}
+	// Rebuild the typed client with a timeout so an unreachable server does not hang doctor
+	kc.RESTConfig.Timeout = doctorTimeout
+	if kc.Clientset, err = kubernetes.NewForConfig(kc.RESTConfig); err != nil {
+		report.fail(check, err.Error(), "check the cluster and user entries of the context")
+		return nil
+	}
+
+	version, err := kc.Clientset.ServerVersion()
+	if err != nil {
+		hint := "check the server address, your network or VPN, and that the cluster is running"
+		if k8sErrors.IsUnauthorized(err) || k8sErrors.IsForbidden(err) {
+			hint = "your credentials were rejected; refresh them (e.g. log in to your cloud provider again)"
+		}
+		report.fail(check, fmt.Sprintf("%s unreachable: %v", kc.RESTConfig.Host, err), hint)
+		return nil
+	}
+
+	detail := fmt.Sprintf("%s reachable, server %s", kc.RESTConfig.Host, version.GitVersion)
+	clientMinor, clientKnown := clientGoMinor()
+	serverMinor, err := strconv.Atoi(strings.TrimRight(version.Minor, "+"))
+	if !clientKnown || err != nil {
+		report.pass(check, detail)
+		return kc
+	}
+	detail += fmt.Sprintf(", client 1.%d", clientMinor)
+	if skew := serverMinor - clientMinor; skew > 1 || skew < -1 {
+		report.warn(check, detail+fmt.Sprintf(" (skew of %d minor versions)", max(skew, -skew)),
+			"client and server should be within one minor version; some API calls may fail")
+		return kc
+	}
+	report.pass(check, detail)
+	return kc
+}
+
+// clientGoMinor returns the Kubernetes minor version matching the linked client-go,
+// which is versioned v0.<minor>.<patch>
+func clientGoMinor() (int, bool) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return 0, false
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != "k8s.io/client-go" {
+			continue
+		}
+		parts := strings.Split(strings.TrimPrefix(dep.Version, "v"), ".")
+		if len(parts) < 2 {
+			return 0, false
+		}
+		minor, err := strconv.Atoi(parts[1])
+		return minor, err == nil
+	}
+	return 0, false
+}
+
+// checkPermissions asks the API server which of golkube's permissions the current user has
+func checkPermissions(report *doctorReport, kc *kube.KubeClient, namespace string) {
+permissions:
+	for _, permission := range doctorPermissions {
+		check := "RBAC " + permission.feature
+		var denied []string
+		for _, rule := range permission.rules {
+			if !permission.clusterWide {
+				rule.Namespace = namespace
+			}
+			allowed, err := kc.CanI(rule)
+			if err != nil {
+				report.fail(check, err.Error(), "the SelfSubjectAccessReview API must be available")
+				continue permissions
+			}
+			if !allowed {
+				denied = append(denied, describeRule(rule))
+			}
+		}
+
+		scope, role := "namespace "+namespace, "Role"
+		if permission.clusterWide {
+			scope, role = "cluster scope", "ClusterRole"
+		}
+		switch {
+		case len(denied) == 0:
+			report.pass(check, "all permissions granted in "+scope)
+		case permission.required:
+			report.fail(check, fmt.Sprintf("denied in %s: %s", scope, strings.Join(denied, ", ")),
+				"ask a cluster admin to bind a "+role+" granting these verbs")
+		default:
+			report.warn(check, fmt.Sprintf("denied in %s: %s", scope, strings.Join(denied, ", ")),
+				"commands using "+permission.feature+" will fail until these are granted")
+		}
+	}
+}
+
+// describeRule formats a permission like "delete pods" or "get pods/log"
+func describeRule(rule authorizationv1.ResourceAttributes) string {
+	resource := rule.Resource
+	if rule.Group != "" {
+		resource += "." + rule.Group
+	}
+	if rule.Subresource != "" {
+		resource += "/" + rule.Subresource
+	}
+	return rule.Verb + " " + resource
+}
+
+// checkDocker connects to the configured Docker daemon and verifies the configured API
+// version is supported. It returns the client when the daemon is reachable.
+func checkDocker(report *doctorReport) *client.Client {
+	const check = "Docker daemon"
+	host := viper.GetString("docker.host")
+	apiVersion := viper.GetString("docker.api_version")
+
+	opts := []client.Opt{client.FromEnv}
+	if host != "" {
+		opts = append(opts, client.WithHost(host))
+	}
+	if apiVersion != "" {
+		opts = append(opts, client.WithVersion(apiVersion))
+	}
+	cli, err := client.NewClientWithOpts(opts...)
+	if err != nil {
+		report.fail(check, fmt.Sprintf("invalid docker.host %q: %v", host, err),
+			"use a host like unix:///var/run/docker.sock or tcp://host:2376")
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+	defer cancel()
+	version, err := cli.ServerVersion(ctx)
+	if err != nil {
+		hint := "start Docker and check docker.host"
+		if strings.Contains(err.Error(), "permission denied") {
+			hint = "add your user to the docker group or use a rootless Docker socket"
+		}
+		report.fail(check, fmt.Sprintf("%s unreachable: %v", cli.DaemonHost(), err), hint)
+		cli.Close()
+		return nil
+	}
+
+	detail := fmt.Sprintf("%s reachable, Docker %s (API %s)", cli.DaemonHost(), version.Version, version.APIVersion)
+	switch {
+	case apiVersion != "" && versions.GreaterThan(apiVersion, version.APIVersion):
+		report.fail(check, detail+fmt.Sprintf("; docker.api_version %s is newer than the daemon supports", apiVersion),
+			"set docker.api_version to "+version.APIVersion+" or lower")
+		cli.Close()
+		return nil
+	case apiVersion != "" && version.MinAPIVersion != "" && versions.LessThan(apiVersion, version.MinAPIVersion):
+		report.fail(check, detail+fmt.Sprintf("; docker.api_version %s is older than the minimum %s", apiVersion, version.MinAPIVersion),
+			"set docker.api_version to "+version.MinAPIVersion+" or higher")
+		cli.Close()
+		return nil
+	}
+	report.pass(check, detail)
+	return cli
+}
+
+// checkRegistry verifies registry credentials are set and accepted by the registry
+func checkRegistry(report *doctorReport, cli *client.Client) {
+	const check = "Registry login"
+	registryURL := viper.GetString("registry.url")
+	username := firstNonEmpty(os.Getenv("DOCKER_USERNAME"), viper.GetString("registry.username"))
+	password := firstNonEmpty(os.Getenv("DOCKER_PASSWORD"), viper.GetString("registry.password"))
+
+	if username == "" || password == "" {
+		report.warn(check, "no registry credentials configured",
+			"set DOCKER_USERNAME and DOCKER_PASSWORD (needed by docker build and push)")
+		return
+	}
+	if cli == nil {
+		report.warn(check, "skipped: the Docker daemon is unreachable", "")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+	defer cancel()
+	_, err := cli.RegistryLogin(ctx, types.AuthConfig{
+		Username:      username,
+		Password:      password,
+		ServerAddress: registryURL,
+	})
+	if err != nil {
+		hint := "check DOCKER_USERNAME and DOCKER_PASSWORD; Docker Hub accounts with 2FA need an access token"
+		if errors.Is(err, context.DeadlineExceeded) {
+			hint = "check registry.url and that the registry is reachable from the Docker daemon"
+		}
+		report.fail(check, fmt.Sprintf("login to %s as %s failed: %v", registryURL, username, err), hint)
+		return
+	}
+	report.pass(check, fmt.Sprintf("logged in to %s as %s", registryURL, username))
+}
+
+// checkPipeline verifies the pipeline file parses and every stage has a name and commands
+func checkPipeline(report *doctorReport, pipelineFile string) {
+	const check = "Pipeline file"
+	if pipelineFile == "" {
+		report.warn(check, "pipeline.config_file is not set", "set it to use golkube pipeline execute")
+		return
+	}
+	pipeline, err := loadPipeline(pipelineFile)
+	if err != nil {
+		report.fail(check, err.Error(), "check pipeline.config_file points to a valid YAML file")
+		return
+	}
+	if len(pipeline.Stages) == 0 {
+		report.warn(check, pipelineFile+" defines no stages", "add a stages list with name and commands entries")
+		return
+	}
+
+	var problems []string
+	seen := make(map[string]bool)
+	for i, stage := range pipeline.Stages {
+		switch {
+		case stage.Name == "":
+			problems = append(problems, fmt.Sprintf("stage %d has no name", i+1))
+		case seen[stage.Name]:
+			problems = append(problems, fmt.Sprintf("stage %q is defined twice", stage.Name))
+		}
+		seen[stage.Name] = true
+		if len(stage.Commands) == 0 {
+			problems = append(problems, fmt.Sprintf("stage %d has no commands", i+1))
+		}
+	}
+	if len(problems) > 0 {
+		report.fail(check, fmt.Sprintf("%s: %s", pipelineFile, strings.Join(problems, "; ")),
+			"every stage needs a unique name and at least one command")
+		return
+	}
+	report.pass(check, fmt.Sprintf("%s defines %d stages", pipelineFile, len(pipeline.Stages)))
+}
+
+// firstNonEmpty returns the first non-empty string
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// oneLine collapses a multi-line error, such as a mapstructure decoding error, onto one line
+func oneLine(err error) string {
+	return strings.Join(strings.Fields(err.Error()), " ")
+}
@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// doctorStatus is the outcome of a single doctor check
+type doctorStatus string
+
+const (
+	doctorPass doctorStatus = "PASS"
+	doctorWarn doctorStatus = "WARN"
+	doctorFail doctorStatus = "FAIL"
+)
+
+// doctorResult is one line of the doctor report
+type doctorResult struct {
+	Check  string
+	Status doctorStatus
+	Detail string
+	// Hint suggests how to fix a warning or failure
+	Hint string
+}
+
+// doctorReport collects check results in the order they ran
+type doctorReport struct {
+	results []doctorResult
+}
+
+func (r *doctorReport) pass(check, detail string) {
+	r.results = append(r.results, doctorResult{Check: check, Status: doctorPass, Detail: detail})
+}
+
+func (r *doctorReport) warn(check, detail, hint string) {
+	r.results = append(r.results, doctorResult{Check: check, Status: doctorWarn, Detail: detail, Hint: hint})
+}
+
+func (r *doctorReport) fail(check, detail, hint string) {
+	r.results = append(r.results, doctorResult{Check: check, Status: doctorFail, Detail: detail, Hint: hint})
+}
+
+// count returns the number of results with the given status
+func (r *doctorReport) count(status doctorStatus) int {
+	n := 0
+	for _, result := range r.results {
+		if result.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// print writes the report with each hint indented under its check
+func (r *doctorReport) print(w io.Writer) {
+	width := 0
+	for _, result := range r.results {
+		width = max(width, len(result.Check))
+	}
+	for _, result := range r.results {
+		fmt.Fprintf(w, "[%s] %-*s  %s\n", result.Status, width, result.Check, result.Detail)
+		if result.Hint != "" {
+			fmt.Fprintf(w, "       %-*s  hint: %s\n", width, "", result.Hint)
+		}
+	}
+	fmt.Fprintf(w, "\n%d passed, %d warnings, %d failed\n",
+		r.count(doctorPass), r.count(doctorWarn), r.count(doctorFail))
+}
+
+// RunDoctor registers the global flags without reading the configuration, which doctor
+// checks itself, and runs doctor. It returns the process exit code; doctor itself exits
+// non-zero when any check failed.
+func RunDoctor() int {
+	registerGlobalFlags()
+
+	RegisterDoctorCommand()
+	if err := RootCmd.Execute(); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// RegisterDoctorCommand registers the preflight diagnostics command
+func RegisterDoctorCommand() {
+	doctorCmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check configuration, cluster access, Docker and registry setup",
+		Long: `Run preflight checks for everything golkube depends on and report each as
+PASS, WARN or FAIL with a hint on how to fix it: the configuration file, kubeconfig
+and context, Kubernetes API reachability and version skew, RBAC permissions,
+the Docker daemon, registry credentials and the pipeline file. With --contexts or
+--all-contexts the cluster checks run for each selected context.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			flags := cmd.Flags()
+			options := doctorOptions{}
+			options.configFile, _ = flags.GetString("config")
+			if flags.Changed("kubeconfig") {
+				options.kubeconfig, _ = flags.GetString("kubeconfig")
+			}
+			if flags.Changed("context") {
+				options.context, _ = flags.GetString("context")
+			}
+			if flags.Changed("namespace") {
+				options.namespace, _ = flags.GetString("namespace")
+			}
+			options.contexts, _ = flags.GetStringSlice("contexts")
+			options.allContexts, _ = flags.GetBool("all-contexts")
+			options.parallel, _ = flags.GetInt("parallel")
+
+			// Client setup logs progress; the report already covers it
+			log.SetOutput(io.Discard)
+			report := runDoctorChecks(options)
+			log.SetOutput(os.Stderr)
+
+			report.print(os.Stdout)
+			if report.count(doctorFail) > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+
+	RootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,211 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golkube/pkg/kube"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// eventView is the JSON representation of a cluster event
+type eventView struct {
+	Namespace string    `json:"namespace"`
+	Type      string    `json:"type"`
+	Reason    string    `json:"reason"`
+	Object    string    `json:"object"`
+	Count     int32     `json:"count"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+	Message   string    `json:"message"`
+}
+
+// RegisterEventCommands registers the "events" command for viewing cluster events.
+func RegisterEventCommands(kubeClient *kube.KubeClient) {
+	eventsCmd := &cobra.Command{
+		Use:   "events",
+		Short: "Show Kubernetes events with optional filtering",
+		Run: func(cmd *cobra.Command, args []string) {
+			target, _ := cmd.Flags().GetString("for")
+			types, _ := cmd.Flags().GetStringSlice("types")
+			since, _ := cmd.Flags().GetDuration("since")
+			watchEvents, _ := cmd.Flags().GetBool("watch")
+			output, _ := cmd.Flags().GetString("output")
+			allNamespaces, _ := cmd.Flags().GetBool("all-namespaces")
+
+			if output != "table" && output != "json" {
+				log.Fatalf("Invalid output format %q: must be table or json", output)
+			}
+
+			filter := kube.EventFilter{
+				Namespace: viper.GetString("kubernetes.namespace"),
+				Types:     types,
+				Since:     since,
+			}
+			if allNamespaces {
+				filter.Namespace = ""
+			}
+			if target != "" {
+				kind, name, found := strings.Cut(target, "/")
+				if !found || kind == "" || name == "" {
+					log.Fatalf("Invalid --for value %q: expected kind/name", target)
+				}
+				filter.InvolvedKind = normalizeKind(kind)
+				filter.InvolvedName = name
+			}
+
+			events, err := kubeClient.ListEvents(filter)
+			if err != nil {
+				log.Fatalf("Error listing events: %v", err)
+			}
+
+			if output == "json" {
+				printEventsJSON(events)
+			} else {
+				printEventsTable(events, true)
+			}
+
+			if !watchEvents {
+				return
+			}
+
+			// Only report events that were not part of the initial listing
+			seen := make(map[string]int32, len(events))
+			for _, event := range events {
+				seen[string(event.UID)] = kube.EventCount(&event)
+			}
+			err = kubeClient.WatchClusterEvents(filter, func(event *corev1.Event) {
+				count := kube.EventCount(event)
+				if previous, ok := seen[string(event.UID)]; ok && previous >= count {
+					return
+				}
+				seen[string(event.UID)] = count
+
+				if output == "json" {
+					// Stream one JSON object per line so the output can be piped to jq
+					data, err := json.Marshal(toEventView(event))
+					if err != nil {
+						log.Printf("Error encoding event: %v", err)
+						return
+					}
+					fmt.Println(string(data))
+				} else {
+					printEventsTable([]corev1.Event{*event}, false)
+				}
+			})
+			if err != nil {
+				log.Fatalf("Error watching events: %v", err)
+			}
+		},
+	}
+
+	eventsCmd.Flags().String("for", "", "Only show events for the given object (kind/name, e.g. pod/web-0)")
+	eventsCmd.Flags().StringSlice("types", nil, "Only show events of these types (Normal, Warning)")
+	eventsCmd.Flags().Duration("since", 0, "Only show events last seen within this duration (e.g. 1h)")
+	eventsCmd.Flags().BoolP("watch", "w", false, "Keep streaming new events after the initial listing")
+	eventsCmd.Flags().StringP("output", "o", "table", "Output format: table or json")
+	eventsCmd.Flags().BoolP("all-namespaces", "A", false, "Show events from all namespaces")
+
+	RootCmd.AddCommand(eventsCmd)
+}
+
+// normalizeKind maps common short and lowercase resource names to their Kind
+func normalizeKind(kind string) string {
+	kinds := map[string]string{
+		"po":          "Pod",
+		"pod":         "Pod",
+		"pods":        "Pod",
+		"deploy":      "Deployment",
+		"deployment":  "Deployment",
+		"deployments": "Deployment",
+		"rs":          "ReplicaSet",
+		"replicaset":  "ReplicaSet",
+		"replicasets": "ReplicaSet",
+		"sts":         "StatefulSet",
+		"statefulset": "StatefulSet",
+		"ds":          "DaemonSet",
+		"daemonset":   "DaemonSet",
+		"job":         "Job",
+		"cronjob":     "CronJob",
+		"svc":         "Service",
+		"service":     "Service",
+		"no":          "Node",
+		"node":        "Node",
+		"nodes":       "Node",
+		"pvc":         "PersistentVolumeClaim",
+		"cm":          "ConfigMap",
+		"configmap":   "ConfigMap",
+		"hpa":         "HorizontalPodAutoscaler",
+	}
+	if normalized, ok := kinds[strings.ToLower(kind)]; ok {
+		return normalized
+	}
+	return kind
+}
+
+// printEventsTable prints events as an aligned table
+func printEventsTable(events []corev1.Event, withHeader bool) {
+	if withHeader && len(events) == 0 {
+		fmt.Println("No events found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if withHeader {
+		fmt.Fprintln(w, "LAST SEEN\tTYPE\tREASON\tOBJECT\tCOUNT\tFIRST SEEN\tMESSAGE")
+	}
+	for i := range events {
+		event := &events[i]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			kube.FormatAge(kube.EventLastSeen(event)),
+			event.Type,
+			event.Reason,
+			involvedObject(event),
+			kube.EventCount(event),
+			kube.FormatAge(kube.EventFirstSeen(event)),
+			strings.TrimSpace(event.Message),
+		)
+	}
+	w.Flush()
+}
+
+// printEventsJSON prints events as a JSON array
+func printEventsJSON(events []corev1.Event) {
+	views := make([]eventView, 0, len(events))
+	for i := range events {
+		views = append(views, toEventView(&events[i]))
+	}
+
+	data, err := json.MarshalIndent(views, "", "  ")
+	if err != nil {
+		log.Fatalf("Error encoding events: %v", err)
+	}
+	fmt.Println(string(data))
+}
+
+// toEventView converts an event to its JSON representation
+func toEventView(event *corev1.Event) eventView {
+	return eventView{
+		Namespace: event.Namespace,
+		Type:      event.Type,
+		Reason:    event.Reason,
+		Object:    involvedObject(event),
+		Count:     kube.EventCount(event),
+		FirstSeen: kube.EventFirstSeen(event),
+		LastSeen:  kube.EventLastSeen(event),
+		Message:   strings.TrimSpace(event.Message),
+	}
+}
+
+// involvedObject formats the object an event refers to as kind/name
+func involvedObject(event *corev1.Event) string {
+	return fmt.Sprintf("%s/%s", strings.ToLower(event.InvolvedObject.Kind), event.InvolvedObject.Name)
+}
@@ -1,15 +1,26 @@
 package commands
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"golkube/pkg/kube"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/yaml"
 )
 
 // RegisterKubeCommands registers Kubernetes-related commands under the "kube" namespace.
@@ -32,6 +43,8 @@ func RegisterKubeCommands(kubeClient *kube.KubeClient) {
 	kubeCmd.AddCommand(updateDeploymentCmd(kubeClient))
 	kubeCmd.AddCommand(listDeploymentsCmd(kubeClient))
 	kubeCmd.AddCommand(deleteDeploymentCmd(kubeClient))
+	kubeCmd.AddCommand(patchResourceCmd(kubeClient))
+	kubeCmd.AddCommand(exposeCmd(kubeClient))
 }
 
 // findOrCreateKubeCommand checks if "kube" exists or creates it under RootCmd.
@@ -87,7 +100,7 @@ func createResourceCmd(kubeClient *kube.KubeClient) *cobra.Command {
 								"containers": []interface{}{
 									map[string]interface{}{
 										"name":  "nginx",
-										"image": "nginx:latest",
+										"image": "nginx:1.27",
 										"ports": []interface{}{
 											map[string]interface{}{
 												"containerPort": int32(80),
@@ -325,7 +338,7 @@ func createDeploymentCmd(kubeClient *kube.KubeClient) *cobra.Command {
 				Name:          "example-deployment",
 				Namespace:     viper.GetString("kubernetes.namespace"),
 				Replicas:      2,
-				Image:         "nginx:latest",
+				Image:         "nginx:1.27",
 				ContainerName: "nginx-container",
 				ContainerPort: 80,
 				Labels: map[string]string{
@@ -335,10 +348,14 @@ func createDeploymentCmd(kubeClient *kube.KubeClient) *cobra.Command {
 					"description": "Example deployment",
 				},
 			}
+			notifier := loadNotifier()
+			sendNotification(notifier, deployNotification("started", config, nil))
 			err := kubeClient.CreateDeployment(config)
 			if err != nil {
+				sendNotification(notifier, deployNotification("failed", config, err))
 				log.Fatalf("Error creating Deployment: %v", err)
 			}
+			sendNotification(notifier, deployNotification("succeeded", config, nil))
 			fmt.Println("Deployment created successfully.")
 		},
 	}
@@ -355,10 +372,14 @@ func updateDeploymentCmd(kubeClient *kube.KubeClient) *cobra.Command {
 				Replicas:  3,
 				Image:     "nginx:stable",
 			}
+			notifier := loadNotifier()
+			sendNotification(notifier, deployNotification("started", config, nil))
 			err := kubeClient.UpdateDeployment(config)
 			if err != nil {
+				sendNotification(notifier, deployNotification("failed", config, err))
 				log.Fatalf("Error updating Deployment: %v", err)
 			}
+			sendNotification(notifier, deployNotification("succeeded", config, nil))
 			fmt.Println("Deployment updated successfully.")
 		},
 	}
@@ -397,3 +418,264 @@ func deleteDeploymentCmd(kubeClient *kube.KubeClient) *cobra.Command {
 		},
 	}
 }
+
+// Patch fields of any resource in place
+func patchResourceCmd(kubeClient *kube.KubeClient) *cobra.Command {
+	patchCmd := &cobra.Command{
+		Use:   "patch <type>/<name> | <type> <name>",
+		Short: "Update fields of a resource with a patch",
+		Long: `Update fields of a resource with a strategic-merge, JSON-merge or JSON patch. Only
+the fields named in the patch change, so concurrent updates to other fields are not
+overwritten. The patch may be given as JSON or YAML.
+
+Patch types:
+  strategic  merges lists such as containers by their key (the default; built-in kinds only)
+  merge      JSON merge patch (RFC 7386): maps merge, lists are replaced
+  json       JSON patch (RFC 6902): a list of add, remove, replace, move, copy and test operations
+
+Examples:
+  golkube kube patch deploy/web -p '{"spec":{"replicas":3}}'
+  golkube kube patch deployment web -p '{"spec":{"template":{"spec":{"containers":[{"name":"web","image":"web:1.5"}]}}}}'
+  golkube kube patch configmap web-config --type merge -p '{"data":{"LOG_LEVEL":"debug"}}'
+  golkube kube patch svc/web --type json -p '[{"op":"replace","path":"/spec/type","value":"NodePort"}]'
+  golkube kube patch widgets.example.com/w1 --type merge --patch-file patch.yaml`,
+		Args: cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			patchTypeName, _ := cmd.Flags().GetString("type")
+			inline, _ := cmd.Flags().GetString("patch")
+			patchFile, _ := cmd.Flags().GetString("patch-file")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			output, _ := cmd.Flags().GetString("output")
+
+			resourceType, name := args[0], ""
+			if len(args) == 2 {
+				name = args[1]
+			} else if before, after, found := strings.Cut(args[0], "/"); found {
+				resourceType, name = before, after
+			}
+			if resourceType == "" || name == "" {
+				log.Fatalf("Error: expected <type>/<name> or <type> <name>")
+			}
+			if output != "" && output != "json" && output != "yaml" {
+				log.Fatalf("Error: unknown output format %q; use json or yaml", output)
+			}
+
+			patchType, ok := kube.PatchTypes[patchTypeName]
+			if !ok {
+				log.Fatalf("Error: unknown patch type %q; use %s", patchTypeName, strings.Join(patchTypeNames(), ", "))
+			}
+			if (inline == "") == (patchFile == "") {
+				log.Fatalf("Error: pass the patch with exactly one of --patch or --patch-file")
+			}
+			data := []byte(inline)
+			if patchFile != "" {
+				var err error
+				if data, err = os.ReadFile(patchFile); err != nil {
+					log.Fatalf("Error reading patch file: %v", err)
+				}
+			}
+			patch, err := yaml.YAMLToJSON(data)
+			if err != nil {
+				log.Fatalf("Error parsing patch: %v", err)
+			}
+
+			gvr, namespaced, err := kubeClient.ResolveResource(resourceType)
+			if err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+			namespace := ""
+			if namespaced {
+				namespace = viper.GetString("kubernetes.namespace")
+			}
+
+			patched, err := kubeClient.PatchResource(name, gvr, namespace, patchType, patch, dryRun)
+			if err != nil {
+				log.Fatalf("Error patching %s/%s: %v", gvr.Resource, name, err)
+			}
+
+			switch output {
+			case "":
+				suffix := ""
+				if dryRun {
+					suffix = " (dry run)"
+				}
+				fmt.Printf("✓ %s/%s patched%s\n", gvr.Resource, name, suffix)
+			case "json":
+				out, err := json.MarshalIndent(patched.Object, "", "  ")
+				if err != nil {
+					log.Fatalf("Error encoding result: %v", err)
+				}
+				fmt.Println(string(out))
+			case "yaml":
+				out, err := yaml.Marshal(patched.Object)
+				if err != nil {
+					log.Fatalf("Error encoding result: %v", err)
+				}
+				fmt.Print(string(out))
+			}
+		},
+	}
+	patchCmd.Flags().StringP("patch", "p", "", "The patch, as JSON or YAML")
+	patchCmd.Flags().String("patch-file", "", "File holding the patch")
+	patchCmd.Flags().String("type", "strategic", "Patch type: strategic, merge or json")
+	patchCmd.Flags().Bool("dry-run", false, "Show the result without changing the resource")
+	patchCmd.Flags().StringP("output", "o", "", "Print the patched resource as json or yaml")
+	return patchCmd
+}
+
+// patchTypeNames lists the accepted patch type names
+func patchTypeNames() []string {
+	names := make([]string, 0, len(kube.PatchTypes))
+	for name := range kube.PatchTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Create a Service for a Deployment or StatefulSet
+func exposeCmd(kubeClient *kube.KubeClient) *cobra.Command {
+	exposeCmd := &cobra.Command{
+		Use:   "expose <kind>/<name> | <kind> <name>",
+		Short: "Create a Service for a Deployment or StatefulSet",
+		Long: `Create a Service for the pods of a Deployment or StatefulSet. The selector is the
+workload's selector and the ports are the container ports of its pod template, so
+nothing needs to be filled in by hand. Pass --port to expose other ports.
+
+After creating the Service, expose waits until it has ready endpoints. If none appear
+in time, it lists the selected pods that are not ready and why.
+
+Examples:
+  golkube kube expose deploy/web
+  golkube kube expose deployment web --type LoadBalancer --port 80:8080
+  golkube kube expose sts/db --headless --name db-peers`,
+		Args: cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			serviceName, _ := cmd.Flags().GetString("name")
+			serviceType, _ := cmd.Flags().GetString("type")
+			headless, _ := cmd.Flags().GetBool("headless")
+			portSpecs, _ := cmd.Flags().GetStringSlice("port")
+			noWait, _ := cmd.Flags().GetBool("no-wait")
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+
+			kind, workload := args[0], ""
+			if len(args) == 2 {
+				workload = args[1]
+			} else if before, after, found := strings.Cut(args[0], "/"); found {
+				kind, workload = before, after
+			}
+			if kind == "" || workload == "" {
+				log.Fatalf("Error: expected <kind>/<name> or <kind> <name>")
+			}
+
+			switch corev1.ServiceType(serviceType) {
+			case corev1.ServiceTypeClusterIP, corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer:
+			default:
+				log.Fatalf("Error: unsupported service type %q; use ClusterIP, NodePort or LoadBalancer", serviceType)
+			}
+			var ports []corev1.ServicePort
+			seenPorts := make(map[int32]bool)
+			for _, spec := range portSpecs {
+				port, err := parseServicePort(spec)
+				if err != nil {
+					log.Fatalf("Error: %v", err)
+				}
+				if seenPorts[port.Port] {
+					log.Fatalf("Error: port %d is given more than once", port.Port)
+				}
+				seenPorts[port.Port] = true
+				ports = append(ports, port)
+			}
+			if len(ports) > 1 {
+				for i := range ports {
+					ports[i].Name = fmt.Sprintf("tcp-%d", ports[i].Port)
+				}
+			}
+
+			namespace := viper.GetString("kubernetes.namespace")
+			config, err := kubeClient.ServiceForWorkload(kube.ExposeConfig{
+				Kind:      kind,
+				Workload:  workload,
+				Namespace: namespace,
+				Name:      serviceName,
+				Type:      corev1.ServiceType(serviceType),
+				Headless:  headless,
+				Ports:     ports,
+			})
+			if err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+			if err := kubeClient.CreateService(config); err != nil {
+				log.Fatalf("Error creating Service: %v", err)
+			}
+			for _, port := range config.Ports {
+				fmt.Printf("  %s %d/%s -> %s\n", firstNonEmpty(port.Name, "port"), port.Port, port.Protocol, port.TargetPort.String())
+			}
+			if noWait {
+				return
+			}
+
+			fmt.Printf("Waiting for service %s to have ready endpoints...\n", config.Name)
+			addresses, err := kubeClient.WaitForServiceEndpoints(context.Background(), config.Name, namespace, timeout)
+			if err == nil {
+				fmt.Printf("✓ Service %s is ready: %s\n", config.Name, strings.Join(addresses, ", "))
+				return
+			}
+
+			fmt.Printf("✗ %v\n", err)
+			reportUnreadyPods(kubeClient, config.Selector, namespace)
+			os.Exit(1)
+		},
+	}
+	exposeCmd.Flags().String("name", "", "Name of the Service; defaults to the workload name")
+	exposeCmd.Flags().String("type", string(corev1.ServiceTypeClusterIP), "Service type: ClusterIP, NodePort or LoadBalancer")
+	exposeCmd.Flags().Bool("headless", false, "Create a headless Service (clusterIP None), as StatefulSets use for stable pod DNS names")
+	exposeCmd.Flags().StringSlice("port", nil, "Port to expose as <port> or <port>:<target port or name>, instead of the container ports (repeatable)")
+	exposeCmd.Flags().Bool("no-wait", false, "Do not wait for ready endpoints")
+	exposeCmd.Flags().Duration("timeout", 2*time.Minute, "How long to wait for ready endpoints")
+	return exposeCmd
+}
+
+// parseServicePort parses a TCP service port given as <port> or <port>:<target>, where the
+// target is a container port number or name
+func parseServicePort(spec string) (corev1.ServicePort, error) {
+	portText, target, hasTarget := strings.Cut(spec, ":")
+	port, err := strconv.ParseInt(portText, 10, 32)
+	if err != nil || port < 1 || port > 65535 {
+		return corev1.ServicePort{}, fmt.Errorf("invalid port %q: expected <port> or <port>:<target>", spec)
+	}
+	servicePort := corev1.ServicePort{
+		Port:       int32(port),
+		Protocol:   corev1.ProtocolTCP,
+		TargetPort: intstr.FromInt32(int32(port)),
+	}
+	if hasTarget {
+		servicePort.TargetPort = intstr.Parse(target)
+	}
+	return servicePort, nil
+}
+
+// reportUnreadyPods explains why the pods behind a Service are not serving
+func reportUnreadyPods(kubeClient *kube.KubeClient, selector map[string]string, namespace string) {
+	diagnoses, err := kubeClient.DiagnoseSelectedPods(selector, namespace)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+		return
+	}
+	if len(diagnoses) == 0 {
+		fmt.Printf("No pods match the selector %s in namespace %s.\n", labels.SelectorFromSet(selector), namespace)
+		return
+	}
+
+	ready := 0
+	for _, diagnosis := range diagnoses {
+		if diagnosis.Health == kube.PodHealthReady {
+			ready++
+			continue
+		}
+		fmt.Printf("  ✗ %s: %s\n", diagnosis.Name, diagnosis)
+	}
+	if ready == len(diagnoses) {
+		fmt.Println("All selected pods are ready, but none became an endpoint; check that the target ports match the container ports.")
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"golkube/pkg/kube"
 	"golkube/pkg/registry"
 	"log"
+	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
@@ -19,9 +21,6 @@ func LoadEnvironment() {
 
 // loadConfiguration loads the application's configuration file
 func LoadConfiguration() {
-	RootCmd.PersistentFlags().String("config", "configs/default.yaml", "Path to configuration file")
-	viper.BindPFlag("config", RootCmd.PersistentFlags().Lookup("config"))
-
 	configFile := viper.GetString("config")
 	if configFile == "" {
 		configFile = "configs/default.yaml"
@@ -32,11 +31,70 @@ func LoadConfiguration() {
 		log.Fatalf("Failed to read config file: %v", err)
 	}
 
-	RootCmd.PersistentFlags().String("namespace", viper.GetString("kubernetes.namespace"), "Kubernetes namespace")
-	viper.BindPFlag("kubernetes.namespace", RootCmd.PersistentFlags().Lookup("namespace"))
+	registerGlobalFlags()
+	flags := RootCmd.PersistentFlags()
+	viper.BindPFlag("config", flags.Lookup("config"))
+	viper.BindPFlag("kubernetes.namespace", flags.Lookup("namespace"))
+	viper.BindPFlag("kubeconfig", flags.Lookup("kubeconfig"))
+
+	// The Kubernetes client is created before cobra parses flags, so --context is also
+	// applied here from the raw command line
+	if clusterContext := parseClusterArgs(os.Args[1:]).context; clusterContext != "" {
+		viper.Set("kubernetes.context", clusterContext)
+	}
+}
+
+// registerGlobalFlags defines the flags shared by every command. Their defaults come from
+// the configuration file when it has been read.
+func registerGlobalFlags() {
+	flags := RootCmd.PersistentFlags()
+	flags.String("config", "configs/default.yaml", "Path to configuration file")
+	flags.String("namespace", viper.GetString("kubernetes.namespace"), "Kubernetes namespace")
+	flags.String("kubeconfig", viper.GetString("kubernetes.kubeconfig"), "Path to kubeconfig file")
+
+	// Cluster selection flags
+	flags.String("context", viper.GetString("kubernetes.context"), "Kubeconfig context to use")
+	flags.StringSlice("contexts", nil, "Run the command against each of these kubeconfig contexts")
+	flags.Bool("all-contexts", false, "Run the command against every kubeconfig context")
+	flags.Int("parallel", defaultParallelClusters, "Maximum clusters addressed at once with --contexts or --all-contexts")
+}
 
-	RootCmd.PersistentFlags().String("kubeconfig", viper.GetString("kubernetes.kubeconfig"), "Path to kubeconfig file")
-	viper.BindPFlag("kubeconfig", RootCmd.PersistentFlags().Lookup("kubeconfig"))
+// globalValueFlags are the global flags that take a value, used to find the command name
+// on the raw command line
+var globalValueFlags = map[string]bool{
+	"--config":     true,
+	"--namespace":  true,
+	"--kubeconfig": true,
+	"--context":    true,
+	"--contexts":   true,
+	"--parallel":   true,
+}
+
+// CommandRequested reports whether the command line runs the named top-level command.
+// It is used before cobra parses flags, to run commands that must not wait for the
+// clients to be created.
+func CommandRequested(name string) bool {
+	args := os.Args[1:]
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return false
+		}
+		if strings.HasPrefix(arg, "-") {
+			if globalValueFlags[arg] {
+				i++
+			}
+			continue
+		}
+		return arg == name
+	}
+	return false
+}
+
+// connectKubeClient creates the Kubernetes client for commands that only need the
+// cluster for part of their work
+func connectKubeClient() (*kube.KubeClient, error) {
+	return kube.NewKubeClientForContext(viper.GetString("kubernetes.kubeconfig"), viper.GetString("kubernetes.context"))
 }
 
 // setLogLevel configures the logging level
@@ -60,14 +118,23 @@ func SetLogLevel(level string) {
 // initializeClients sets up Kubernetes and Docker clients
 func InitializeClients() (*kube.KubeClient, *registry.RegistryClient) {
 	kubeconfig := viper.GetString("kubernetes.kubeconfig")
-	kubeClient, err := kube.NewKubeClient(kubeconfig)
+	kubeClient, err := kube.NewKubeClientForContext(kubeconfig, viper.GetString("kubernetes.context"))
 	if err != nil {
 		log.Fatalf("Error initializing Kubernetes client: %v", err)
 	}
 
-	err = kubeClient.TestConnection()
-	if err != nil {
-		log.Fatalf("Kubernetes API connection test failed: %v", err)
+	// Policy rules are enforced on every object golkube creates or updates
+	if config, engine := loadPolicy(); config.Enabled {
+		kubeClient.Admission = engine
+	}
+
+	// Processes started by RunMultiContext skip the connection banner so it does not
+	// repeat in the combined output; connection failures surface from the command itself
+	if os.Getenv(multiContextChildEnv) == "" {
+		err = kubeClient.TestConnection()
+		if err != nil {
+			log.Fatalf("Kubernetes API connection test failed: %v", err)
+		}
 	}
 
 	dockerRegistry, err := registry.NewRegistryClient()
@@ -0,0 +1,337 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+	"unicode"
+
+	"golkube/pkg/kube"
+
+	"github.com/spf13/viper"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// defaultParallelClusters limits how many clusters are addressed at once
+const defaultParallelClusters = 4
+
+// multiContextChildEnv is set in the environment of the per-cluster golkube processes
+const multiContextChildEnv = "GOLKUBE_MULTI_CONTEXT_CHILD"
+
+// clusterArgs are the cluster selection flags. They are read from the raw command line
+// because clients are created before cobra parses the flags.
+type clusterArgs struct {
+	context     string
+	contexts    []string
+	allContexts bool
+	parallel    int
+	// rest is the command line without the multi-cluster flags
+	rest []string
+}
+
+// parseClusterArgs extracts --context, --contexts, --all-contexts and --parallel from args
+func parseClusterArgs(args []string) clusterArgs {
+	parsed := clusterArgs{parallel: defaultParallelClusters}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			parsed.rest = append(parsed.rest, args[i:]...)
+			break
+		}
+
+		name, value, hasValue := strings.Cut(arg, "=")
+		// value returns the flag's value from "--flag=value" or the next argument
+		next := func() string {
+			if hasValue {
+				return value
+			}
+			if i+1 < len(args) {
+				i++
+				return args[i]
+			}
+			return ""
+		}
+
+		switch name {
+		case "--context":
+			parsed.context = next()
+			// --context is also understood by cobra, so it stays on the command line
+			parsed.rest = append(parsed.rest, "--context="+parsed.context)
+		case "--contexts":
+			for _, name := range strings.Split(next(), ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					parsed.contexts = append(parsed.contexts, name)
+				}
+			}
+		case "--all-contexts":
+			parsed.allContexts = !hasValue || value == "true"
+		case "--parallel":
+			if n, err := strconv.Atoi(next()); err == nil && n > 0 {
+				parsed.parallel = n
+			}
+		default:
+			parsed.rest = append(parsed.rest, arg)
+		}
+	}
+	return parsed
+}
+
+// MultiContextRequested reports whether --contexts or --all-contexts was given
+func MultiContextRequested() bool {
+	parsed := parseClusterArgs(os.Args[1:])
+	return len(parsed.contexts) > 0 || parsed.allContexts
+}
+
+// clusterResult is the outcome of running the command against one cluster
+type clusterResult struct {
+	context  string
+	stdout   []byte
+	stderr   []byte
+	err      error
+	duration time.Duration
+}
+
+// RunMultiContext runs the command line once per selected kubeconfig context, in parallel
+// up to the --parallel limit. Each cluster's output and errors are printed in a CLUSTER
+// column, followed by a per-cluster summary. With -o json or yaml the results are instead
+// printed as one object keyed by context, and the summary goes to stderr. It returns the
+// process exit code.
+//
+// Each cluster runs in its own golkube process so that a fatal error in one cluster
+// cannot abort the others.
+func RunMultiContext() int {
+	parsed := parseClusterArgs(os.Args[1:])
+
+	contexts := parsed.contexts
+	if parsed.allContexts {
+		all, _, err := kube.ListContexts(viper.GetString("kubernetes.kubeconfig"))
+		if err != nil {
+			log.Printf("Error listing kubeconfig contexts: %v", err)
+			return 1
+		}
+		contexts = all
+	}
+	if len(contexts) == 0 {
+		log.Printf("No kubeconfig contexts selected")
+		return 1
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		log.Printf("Error locating golkube executable: %v", err)
+		return 1
+	}
+
+	width := len("CLUSTER")
+	for _, name := range contexts {
+		width = max(width, len(name))
+	}
+
+	results := make(chan clusterResult)
+	semaphore := make(chan struct{}, parsed.parallel)
+	var wg sync.WaitGroup
+	for _, name := range contexts {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			results <- runInContext(executable, name, parsed.rest)
+		}(name)
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Print each cluster's output and errors as it finishes, every line prefixed with its
+	// cluster; a table header shared by all clusters is printed once
+	format := outputFormat(parsed.rest)
+	structured := format == "json" || format == "yaml"
+	byContext := make(map[string]clusterResult, len(contexts))
+	header := ""
+	for result := range results {
+		byContext[result.context] = result
+		if !structured {
+			header = printPrefixed(os.Stdout, result.context, width, result.stdout, header)
+		}
+		printPrefixed(os.Stderr, result.context, width, result.stderr, "")
+	}
+
+	summary := os.Stdout
+	if structured {
+		if err := printKeyedByContext(os.Stdout, format, contexts, byContext); err != nil {
+			log.Printf("Error combining cluster output: %v", err)
+			return 1
+		}
+		summary = os.Stderr
+	}
+
+	fmt.Fprintln(summary)
+	failed := 0
+	w := tabwriter.NewWriter(summary, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CLUSTER\tRESULT\tDURATION\tERROR")
+	for _, name := range contexts {
+		result := byContext[name]
+		status, message := "ok", ""
+		if result.err != nil {
+			failed++
+			status, message = "failed", lastLine(result.stderr)
+			if message == "" {
+				message = result.err.Error()
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, status, result.duration.Round(time.Millisecond), message)
+	}
+	w.Flush()
+	fmt.Fprintf(summary, "%d of %d clusters succeeded\n", len(contexts)-failed, len(contexts))
+
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runInContext runs golkube with the given arguments against one kubeconfig context
+func runInContext(executable, contextName string, args []string) clusterResult {
+	started := time.Now()
+	cmd := exec.Command(executable, append(append([]string{}, args...), "--context="+contextName)...)
+
+	cmd.Env = append(os.Environ(), multiContextChildEnv+"=1")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	return clusterResult{
+		context:  contextName,
+		stdout:   stdout.Bytes(),
+		stderr:   stderr.Bytes(),
+		err:      err,
+		duration: time.Since(started),
+	}
+}
+
+// printPrefixed writes each line of output to w behind the cluster name, padded to width.
+// A table header on the first line is printed under CLUSTER instead, unless it equals the
+// header already printed. It returns the last header printed.
+func printPrefixed(w io.Writer, contextName string, width int, output []byte, header string) string {
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	first := true
+	for scanner.Scan() {
+		line := scanner.Text()
+		if first && isHeaderLine(line) {
+			first = false
+			if line != header {
+				header = line
+				fmt.Fprintf(w, "%-*s  %s\n", width, "CLUSTER", line)
+			}
+			continue
+		}
+		first = false
+		fmt.Fprintf(w, "%-*s  %s\n", width, contextName, line)
+	}
+	return header
+}
+
+// isHeaderLine reports whether a line looks like a table header: several upper-case columns
+func isHeaderLine(line string) bool {
+	if len(strings.Fields(line)) < 2 {
+		return false
+	}
+	for _, r := range line {
+		if unicode.IsLower(r) {
+			return false
+		}
+	}
+	return strings.IndexFunc(line, unicode.IsLetter) >= 0
+}
+
+// outputFormat returns the value of -o/--output on the command line, if any
+func outputFormat(args []string) string {
+	for i, arg := range args {
+		switch {
+		case arg == "-o" || arg == "--output":
+			if i+1 < len(args) {
+				return args[i+1]
+			}
+		case strings.HasPrefix(arg, "--output="):
+			return strings.TrimPrefix(arg, "--output=")
+		case strings.HasPrefix(arg, "-o"):
+			return strings.TrimPrefix(strings.TrimPrefix(arg, "-o"), "=")
+		}
+	}
+	return ""
+}
+
+// printKeyedByContext prints the json or yaml output of every cluster as one object keyed by
+// context. Clusters that failed map to null; output of several documents becomes a list.
+func printKeyedByContext(w io.Writer, format string, contexts []string, byContext map[string]clusterResult) error {
+	combined := make(map[string]interface{}, len(contexts))
+	for _, name := range contexts {
+		result := byContext[name]
+		var documents []interface{}
+		decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(result.stdout), 4096)
+		for {
+			var document interface{}
+			err := decoder.Decode(&document)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("failed to parse output of %s: %w", name, err)
+			}
+			if document != nil {
+				documents = append(documents, document)
+			}
+		}
+
+		switch len(documents) {
+		case 0:
+			combined[name] = nil
+		case 1:
+			combined[name] = documents[0]
+		default:
+			combined[name] = documents
+		}
+	}
+
+	var out []byte
+	var err error
+	if format == "json" {
+		out, err = json.MarshalIndent(combined, "", "  ")
+		out = append(out, '\n')
+	} else {
+		out, err = yaml.Marshal(combined)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// logTimestamp matches the date and time the standard logger prefixes lines with
+var logTimestamp = regexp.MustCompile(`^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2} `)
+
+// lastLine returns the last non-empty line of output without its log timestamp
+func lastLine(output []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	return logTimestamp.ReplaceAllString(strings.TrimSpace(lines[len(lines)-1]), "")
+}
@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golkube/pkg/kube"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// RegisterNodeCommands registers commands for node inspection and maintenance.
+func RegisterNodeCommands(kubeClient *kube.KubeClient) {
+	nodeCmd := &cobra.Command{
+		Use:   "node",
+		Short: "Inspect nodes and manage node maintenance",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List nodes with conditions, taints, capacity and allocatable resources",
+		Run: func(cmd *cobra.Command, args []string) {
+			selector, _ := cmd.Flags().GetString("selector")
+
+			nodes, err := kubeClient.ListNodes(selector)
+			if err != nil {
+				log.Fatalf("Error listing nodes: %v", err)
+			}
+			if len(nodes) == 0 {
+				fmt.Println("No nodes found.")
+				return
+			}
+			sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tSTATUS\tCONDITIONS\tTAINTS\tCPU (ALLOC/CAP)\tMEMORY (ALLOC/CAP)\tPODS\tAGE")
+			for _, node := range nodes {
+				capacity, allocatable := node.Status.Capacity, node.Status.Allocatable
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s/%s\t%s/%s\t%s\t%s\n",
+					node.Name,
+					nodeStatus(node),
+					nodeConditions(node),
+					nodeTaints(node),
+					formatCPU(allocatable[corev1.ResourceCPU]), formatCPU(capacity[corev1.ResourceCPU]),
+					formatMemory(allocatable[corev1.ResourceMemory]), formatMemory(capacity[corev1.ResourceMemory]),
+					allocatable.Pods().String(),
+					kube.FormatAge(node.CreationTimestamp.Time),
+				)
+			}
+			w.Flush()
+		},
+	}
+	listCmd.Flags().StringP("selector", "l", "", "Label selector to filter nodes")
+
+	cordonCmd := &cobra.Command{
+		Use:   "cordon <node>",
+		Short: "Mark a node unschedulable",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := kubeClient.CordonNode(args[0]); err != nil {
+				log.Fatalf("Error cordoning node: %v", err)
+			}
+		},
+	}
+
+	uncordonCmd := &cobra.Command{
+		Use:   "uncordon <node>",
+		Short: "Mark a node schedulable",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := kubeClient.UncordonNode(args[0]); err != nil {
+				log.Fatalf("Error uncordoning node: %v", err)
+			}
+		},
+	}
+
+	drainCmd := &cobra.Command{
+		Use:   "drain <node>",
+		Short: "Cordon a node and evict its pods, respecting PodDisruptionBudgets",
+		Long: `Cordons the node and evicts its pods through the Eviction API so PodDisruptionBudgets are honoured.
+DaemonSet-managed and mirror pods are skipped. Pods using emptyDir volumes or not managed by a
+controller stop the drain unless --delete-emptydir-data or --force is given.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			deleteEmptyDir, _ := cmd.Flags().GetBool("delete-emptydir-data")
+			force, _ := cmd.Flags().GetBool("force")
+			gracePeriod, _ := cmd.Flags().GetInt64("grace-period")
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+
+			err := kubeClient.DrainNode(kube.DrainConfig{
+				NodeName:           args[0],
+				DeleteEmptyDirData: deleteEmptyDir,
+				Force:              force,
+				GracePeriod:        gracePeriod,
+				Timeout:            timeout,
+			})
+			if err != nil {
+				log.Fatalf("Error draining node: %v", err)
+			}
+		},
+	}
+	drainCmd.Flags().Bool("delete-emptydir-data", false, "Evict pods using emptyDir volumes; their data is lost")
+	drainCmd.Flags().Bool("force", false, "Evict pods not managed by a controller; they are not recreated")
+	drainCmd.Flags().Int64("grace-period", -1, "Seconds each pod is given to terminate; -1 uses the pod's own setting")
+	drainCmd.Flags().Duration("timeout", 5*time.Minute, "Give up after this long; 0 waits forever")
+
+	nodeCmd.AddCommand(listCmd)
+	nodeCmd.AddCommand(cordonCmd)
+	nodeCmd.AddCommand(uncordonCmd)
+	nodeCmd.AddCommand(drainCmd)
+	RootCmd.AddCommand(nodeCmd)
+}
+
+// nodeStatus renders readiness and whether scheduling is disabled, like kubectl
+func nodeStatus(node corev1.Node) string {
+	status := "Unknown"
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			status = "NotReady"
+			if condition.Status == corev1.ConditionTrue {
+				status = "Ready"
+			}
+		}
+	}
+	if node.Spec.Unschedulable {
+		status += ",SchedulingDisabled"
+	}
+	return status
+}
+
+// nodeConditions lists the pressure and availability conditions that are currently true
+func nodeConditions(node corev1.Node) string {
+	var active []string
+	for _, condition := range node.Status.Conditions {
+		if condition.Type != corev1.NodeReady && condition.Status == corev1.ConditionTrue {
+			active = append(active, string(condition.Type))
+		}
+	}
+	if len(active) == 0 {
+		return "-"
+	}
+	return strings.Join(active, ",")
+}
+
+// nodeTaints renders taints as key=value:effect
+func nodeTaints(node corev1.Node) string {
+	if len(node.Spec.Taints) == 0 {
+		return "-"
+	}
+	taints := make([]string, 0, len(node.Spec.Taints))
+	for _, taint := range node.Spec.Taints {
+		text := taint.Key
+		if taint.Value != "" {
+			text += "=" + taint.Value
+		}
+		taints = append(taints, text+":"+string(taint.Effect))
+	}
+	return strings.Join(taints, ",")
+}
@@ -0,0 +1,260 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golkube/pkg/kube"
+	"golkube/pkg/notify"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// notificationTimeout bounds how long a single notification may take including retries
+const notificationTimeout = 2 * time.Minute
+
+// RegisterNotifyCommands registers commands for the notification channels.
+func RegisterNotifyCommands() {
+	notifyCmd := &cobra.Command{
+		Use:   "notify",
+		Short: "Manage notification channels",
+	}
+
+	testCmd := &cobra.Command{
+		Use:   "test",
+		Short: "Send a test notification to the configured channels",
+		Run: func(cmd *cobra.Command, args []string) {
+			channel, _ := cmd.Flags().GetString("channel")
+			title, _ := cmd.Flags().GetString("title")
+			message, _ := cmd.Flags().GetString("message")
+			severity, _ := cmd.Flags().GetString("severity")
+
+			config, err := loadNotifyConfig()
+			if err != nil {
+				log.Fatalf("Error loading notification configuration: %v", err)
+			}
+
+			// Explicitly requested channels are tested even when disabled in the config
+			switch channel {
+			case "":
+			case "slack", "email", "webhook":
+				config.Slack.Enabled = channel == "slack"
+				config.Email.Enabled = channel == "email"
+				config.Webhook.Enabled = channel == "webhook"
+			default:
+				log.Fatalf("Unknown channel %q: must be slack, email or webhook", channel)
+			}
+
+			notifiers, err := notify.FromConfig(config)
+			if err != nil {
+				log.Fatalf("Error creating notifiers: %v", err)
+			}
+			if len(notifiers) == 0 {
+				log.Fatalf("No notification channels are enabled; enable one under notifications in the config or pass --channel")
+			}
+
+			msg := notify.Message{
+				Title:    title,
+				Body:     message,
+				Severity: notify.Severity(severity),
+				Source:   "golkube notify test",
+				Event:    "test",
+				Fields: map[string]string{
+					"namespace": viper.GetString("kubernetes.namespace"),
+				},
+				Time: time.Now(),
+			}
+
+			failed := false
+			for _, notifier := range notifiers {
+				ctx, cancel := context.WithTimeout(context.Background(), notificationTimeout)
+				err := notifier.Notify(ctx, msg)
+				cancel()
+				if err != nil {
+					fmt.Printf("✗ %s: %v\n", notifier.Name(), err)
+					failed = true
+					continue
+				}
+				fmt.Printf("✓ %s: test notification sent\n", notifier.Name())
+			}
+			if failed {
+				log.Fatalf("One or more notification channels failed")
+			}
+		},
+	}
+	testCmd.Flags().String("channel", "", "Only test this channel (slack, email or webhook)")
+	testCmd.Flags().String("title", "golkube test notification", "Notification title")
+	testCmd.Flags().String("message", "If you can read this, notifications are working.", "Notification body")
+	testCmd.Flags().String("severity", string(notify.SeverityInfo), "Notification severity (info, warning, critical)")
+
+	replayCmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Redeliver webhook events from the dead-letter file",
+		Run: func(cmd *cobra.Command, args []string) {
+			list, _ := cmd.Flags().GetBool("list")
+
+			config, err := loadNotifyConfig()
+			if err != nil {
+				log.Fatalf("Error loading notification configuration: %v", err)
+			}
+			webhook, err := notify.NewWebhookNotifier(config.Webhook, config.Retry)
+			if err != nil {
+				log.Fatalf("Error creating webhook notifier: %v", err)
+			}
+
+			if list {
+				entries, err := webhook.DeadLetters()
+				if err != nil {
+					log.Fatalf("Error reading dead letters: %v", err)
+				}
+				if len(entries) == 0 {
+					fmt.Println("No undelivered webhook events.")
+					return
+				}
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "ID\tTYPE\tURL\tFAILED\tERROR")
+				for _, entry := range entries {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", entry.Event.ID, entry.Event.Type, entry.URL,
+						kube.FormatAge(entry.FailedAt), entry.Error)
+				}
+				w.Flush()
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), notificationTimeout)
+			defer cancel()
+			delivered, remaining, err := webhook.Replay(ctx)
+			if err != nil {
+				log.Fatalf("Error replaying dead letters: %v", err)
+			}
+			fmt.Printf("Redelivered %d webhook events, %d still undelivered\n", delivered, len(remaining))
+			for _, entry := range remaining {
+				fmt.Printf("✗ %s %s: %s\n", entry.Event.ID, entry.URL, entry.Error)
+			}
+		},
+	}
+	replayCmd.Flags().Bool("list", false, "List dead-lettered events without redelivering them")
+
+	notifyCmd.AddCommand(testCmd)
+	notifyCmd.AddCommand(replayCmd)
+	RootCmd.AddCommand(notifyCmd)
+}
+
+// loadNotifyConfig reads the "notifications" block of the configuration
+func loadNotifyConfig() (notify.Config, error) {
+	var config notify.Config
+	if err := viper.UnmarshalKey("notifications", &config); err != nil {
+		return config, fmt.Errorf("failed to parse notifications: %w", err)
+	}
+	return config, nil
+}
+
+// loadNotifier builds a notifier for all enabled channels, or returns nil if none are enabled
+func loadNotifier() notify.Notifier {
+	config, err := loadNotifyConfig()
+	if err != nil {
+		log.Printf("Warning: notifications disabled: %v", err)
+		return nil
+	}
+	notifiers, err := notify.FromConfig(config)
+	if err != nil {
+		log.Printf("Warning: notifications disabled: %v", err)
+		return nil
+	}
+	if len(notifiers) == 0 {
+		return nil
+	}
+	return notify.NewMulti(notifiers...)
+}
+
+// sendNotification delivers a message and logs, rather than fails on, delivery errors
+func sendNotification(notifier notify.Notifier, msg notify.Message) {
+	if notifier == nil {
+		return
+	}
+	if msg.Time.IsZero() {
+		msg.Time = time.Now()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notificationTimeout)
+	defer cancel()
+	if err := notifier.Notify(ctx, msg); err != nil {
+		log.Printf("Warning: failed to send notification %q: %v", msg.Title, err)
+	}
+}
+
+// problemStates are the pod health states worth notifying about.
+// Pending and Terminating are routine during rollouts and are left out.
+var problemStates = map[kube.PodHealth]bool{
+	kube.PodHealthNotReady:         true,
+	kube.PodHealthCrashLoopBackOff: true,
+	kube.PodHealthImagePullBackOff: true,
+	kube.PodHealthOOMKilled:        true,
+	kube.PodHealthEvicted:          true,
+	kube.PodHealthInitFailed:       true,
+	kube.PodHealthFailed:           true,
+}
+
+// transitionNotification converts a pod health transition into a notification.
+// Only transitions into or out of a problem state and restarts are reported.
+func transitionNotification(namespace string, transition kube.PodTransition) (notify.Message, bool) {
+	diagnosis := transition.Diagnosis
+	msg := notify.Message{
+		Source: "monitor",
+		Event:  "pod.health",
+		Fields: map[string]string{
+			"namespace": namespace,
+			"pod":       diagnosis.Name,
+			"health":    string(diagnosis.Health),
+		},
+		Body: transition.String(),
+	}
+
+	switch {
+	case transition.Deleted:
+		return msg, false
+	case problemStates[diagnosis.Health] && (transition.New || transition.Previous != diagnosis.Health):
+		msg.Title = fmt.Sprintf("Pod %s is %s", diagnosis.Name, diagnosis.Health)
+		msg.Severity = notify.SeverityWarning
+		if diagnosis.Health == kube.PodHealthCrashLoopBackOff || diagnosis.Health == kube.PodHealthOOMKilled {
+			msg.Severity = notify.SeverityCritical
+		}
+		return msg, true
+	case transition.RestartDelta > 0:
+		msg.Title = fmt.Sprintf("Pod %s restarted %d times", diagnosis.Name, transition.RestartDelta)
+		msg.Severity = notify.SeverityWarning
+		return msg, true
+	case problemStates[transition.Previous] && diagnosis.Healthy():
+		msg.Title = fmt.Sprintf("Pod %s recovered", diagnosis.Name)
+		msg.Severity = notify.SeverityInfo
+		return msg, true
+	}
+	return msg, false
+}
+
+// deployNotification describes a deploy lifecycle phase (started, succeeded or failed)
+func deployNotification(phase string, config kube.DeploymentConfig, err error) notify.Message {
+	msg := notify.Message{
+		Title:    fmt.Sprintf("Deploy of %s %s", config.Name, phase),
+		Severity: notify.SeverityInfo,
+		Source:   "deploy",
+		Event:    "deploy." + phase,
+		Fields: map[string]string{
+			"namespace":  config.Namespace,
+			"deployment": config.Name,
+			"image":      strings.Join(config.Images(), ", "),
+			"replicas":   fmt.Sprintf("%d", config.Replicas),
+		},
+	}
+	if err != nil {
+		msg.Severity = notify.SeverityCritical
+		msg.Body = err.Error()
+	}
+	return msg
+}
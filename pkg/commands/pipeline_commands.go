@@ -5,6 +5,9 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"time"
+
+	"golkube/pkg/notify"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -30,37 +33,54 @@ func RegisterPipelineCommand() {
 			}
 
 			// Read and parse the pipeline file
-			config, err := os.ReadFile(pipelineFile)
+			pipeline, err := loadPipeline(pipelineFile)
 			if err != nil {
-				log.Fatalf("Failed to read pipeline file: %v", err)
-			}
-
-			var pipeline struct {
-				Stages []struct {
-					Name     string   `json:"name"`
-					Commands []string `json:"commands"`
-				} `json:"stages"`
+				log.Fatalf("Failed to load pipeline: %v", err)
 			}
 
-			if err := yaml.Unmarshal(config, &pipeline); err != nil {
-				log.Fatalf("Failed to parse pipeline file: %v", err)
-			}
+			// Pipeline results are sent to any enabled notification channels
+			notifier := loadNotifier()
+			started := time.Now()
+			results := map[string]string{"pipeline": pipelineFile}
 
 			// Execute each stage in the pipeline
 			for _, stage := range pipeline.Stages {
 				fmt.Printf("Executing stage: %s\n", stage.Name)
+				stageStarted := time.Now()
 				for _, command := range stage.Commands {
 					fmt.Printf("Running command: %s\n", command)
 					cmd := exec.Command("sh", "-c", command)
 					cmd.Stdout = os.Stdout
 					cmd.Stderr = os.Stderr
 					if err := cmd.Run(); err != nil {
+						results["stage."+stage.Name] = "failed after " + time.Since(stageStarted).Round(time.Second).String()
+						results["failed_stage"] = stage.Name
+						results["duration"] = time.Since(started).Round(time.Second).String()
+						sendNotification(notifier, notify.Message{
+							Title:    fmt.Sprintf("Pipeline failed at stage %s", stage.Name),
+							Body:     fmt.Sprintf("Command %q failed: %v", command, err),
+							Severity: notify.SeverityCritical,
+							Source:   "pipeline",
+							Event:    "pipeline.failed",
+							Fields:   results,
+						})
 						log.Fatalf("Command failed: %s\nError: %v", command, err)
 					}
 				}
+				results["stage."+stage.Name] = "succeeded in " + time.Since(stageStarted).Round(time.Second).String()
 				fmt.Printf("Stage %s completed successfully.\n", stage.Name)
 			}
 			fmt.Println("Pipeline execution completed successfully.")
+
+			results["duration"] = time.Since(started).Round(time.Second).String()
+			sendNotification(notifier, notify.Message{
+				Title:    "Pipeline completed successfully",
+				Body:     fmt.Sprintf("All %d stages completed.", len(pipeline.Stages)),
+				Severity: notify.SeverityInfo,
+				Source:   "pipeline",
+				Event:    "pipeline.succeeded",
+				Fields:   results,
+			})
 		},
 	}
 
@@ -70,3 +90,25 @@ func RegisterPipelineCommand() {
 	// Add the pipeline command to the root command
 	RootCmd.AddCommand(pipelineCmd)
 }
+
+// pipelineDefinition is the structure of the pipeline file
+type pipelineDefinition struct {
+	Stages []struct {
+		Name     string   `json:"name"`
+		Commands []string `json:"commands"`
+	} `json:"stages"`
+}
+
+// loadPipeline reads and parses a pipeline file
+func loadPipeline(path string) (*pipelineDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pipeline file: %w", err)
+	}
+
+	var pipeline pipelineDefinition
+	if err := yaml.Unmarshal(data, &pipeline); err != nil {
+		return nil, fmt.Errorf("failed to parse pipeline file: %w", err)
+	}
+	return &pipeline, nil
+}
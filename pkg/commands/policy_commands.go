@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"golkube/pkg/kube"
+	"golkube/pkg/policy"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// RegisterPolicyCommands registers the manifest policy commands
+func RegisterPolicyCommands() {
+	policyCmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Check manifests against the workload policy rules",
+	}
+
+	checkCmd := &cobra.Command{
+		Use:   "check -f <file|dir|->",
+		Short: "Check manifests against the policy rules",
+		Long: `Check manifests against the built-in and custom policy rules configured under
+"policy" in the configuration file. Exits non-zero if any error-level rule is broken.
+The same rules are enforced when golkube creates or updates objects.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			files, _ := cmd.Flags().GetStringArray("filename")
+			namespace := viper.GetString("kubernetes.namespace")
+			_, engine := loadPolicy()
+
+			var violations []policy.Violation
+			checked := 0
+			for _, file := range files {
+				objects, err := kube.LoadManifests(file)
+				if err != nil {
+					log.Fatalf("Error loading manifests: %v", err)
+				}
+				for _, obj := range objects {
+					found, err := engine.Check(obj, namespace)
+					if err != nil {
+						log.Fatalf("Error checking %s/%s: %v", obj.GetKind(), obj.GetName(), err)
+					}
+					violations = append(violations, found...)
+					checked++
+				}
+			}
+
+			errors := 0
+			if len(violations) > 0 {
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "SEVERITY\tRULE\tRESOURCE\tNAMESPACE\tMESSAGE")
+				for _, v := range violations {
+					if v.Severity == policy.SeverityError {
+						errors++
+					}
+					fmt.Fprintf(w, "%s\t%s\t%s/%s\t%s\t%s\n", v.Severity, v.Rule, v.Kind, v.Name, v.Namespace, v.Message)
+				}
+				w.Flush()
+				fmt.Println()
+			}
+			fmt.Printf("%d objects checked: %d errors, %d warnings\n", checked, errors, len(violations)-errors)
+			if errors > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+	checkCmd.Flags().StringArrayP("filename", "f", nil, "Manifest file or directory to check; - reads standard input (repeatable)")
+	checkCmd.MarkFlagRequired("filename")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the policy rules and their severity in the namespace",
+		Run: func(cmd *cobra.Command, args []string) {
+			namespace := viper.GetString("kubernetes.namespace")
+			config, engine := loadPolicy()
+			if !config.Enabled {
+				fmt.Println("Policy enforcement is disabled; rules only apply to policy check.")
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "RULE\tSEVERITY\tTYPE\tDESCRIPTION")
+			for _, rule := range engine.Rules(namespace) {
+				ruleType := "built-in"
+				if rule.Custom {
+					ruleType = "cel"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", rule.Name, rule.Severity, ruleType, rule.Description)
+			}
+			w.Flush()
+		},
+	}
+
+	policyCmd.AddCommand(checkCmd, listCmd)
+	RootCmd.AddCommand(policyCmd)
+}
+
+// loadPolicyConfig reads the "policy" block of the configuration file
+func loadPolicyConfig() (policy.Config, error) {
+	var config policy.Config
+	if err := viper.UnmarshalKey("policy", &config); err != nil {
+		return config, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	return config, nil
+}
+
+// loadPolicy builds the policy engine from the configuration, exiting on invalid rules
+func loadPolicy() (policy.Config, *policy.Engine) {
+	config, err := loadPolicyConfig()
+	if err != nil {
+		log.Fatalf("Error loading policy: %v", err)
+	}
+	engine, err := policy.NewEngine(config)
+	if err != nil {
+		log.Fatalf("Error loading policy: %v", err)
+	}
+	return config, engine
+}
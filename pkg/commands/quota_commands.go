@@ -0,0 +1,250 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"golkube/pkg/kube"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// defaultQuotaName names the ResourceQuota and LimitRange objects golkube manages
+const defaultQuotaName = "golkube"
+
+// defaultQuotaThreshold is the usage percentage above which quota status warns
+const defaultQuotaThreshold = 80
+
+// quotaSettings is the "quotas" block of the configuration file
+type quotaSettings struct {
+	Name          string                    `mapstructure:"name"`
+	WarnThreshold float64                   `mapstructure:"warn_threshold"`
+	Namespaces    map[string]namespaceQuota `mapstructure:"namespaces"`
+}
+
+// namespaceQuota is the quota and container limits of one namespace. Hard takes
+// ResourceQuota resource names such as requests.cpu, limits.memory or pods.
+type namespaceQuota struct {
+	Hard      map[string]string `mapstructure:"hard"`
+	Container containerLimits   `mapstructure:"container"`
+}
+
+// containerLimits are the per-container bounds and defaults of a LimitRange
+type containerLimits struct {
+	Default        map[string]string `mapstructure:"default"`
+	DefaultRequest map[string]string `mapstructure:"default_request"`
+	Min            map[string]string `mapstructure:"min"`
+	Max            map[string]string `mapstructure:"max"`
+}
+
+// RegisterQuotaCommands registers the ResourceQuota and LimitRange commands
+func RegisterQuotaCommands(kubeClient *kube.KubeClient) {
+	quotaCmd := &cobra.Command{
+		Use:   "quota",
+		Short: "Manage namespace ResourceQuotas and LimitRanges",
+	}
+
+	applyCmd := &cobra.Command{
+		Use:   "apply [namespace...]",
+		Short: "Create or update the ResourceQuotas and LimitRanges from the configuration",
+		Long: `Create or update a ResourceQuota and a LimitRange in every namespace listed under
+quotas.namespaces in the configuration file, or only in the given namespaces.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			settings, err := loadQuotaSettings()
+			if err != nil {
+				log.Fatalf("Error loading quotas: %v", err)
+			}
+			namespaces := args
+			if len(namespaces) == 0 {
+				for namespace := range settings.Namespaces {
+					namespaces = append(namespaces, namespace)
+				}
+				sort.Strings(namespaces)
+			}
+			if len(namespaces) == 0 {
+				fmt.Println("No namespaces configured under quotas.namespaces.")
+				return
+			}
+
+			for _, namespace := range namespaces {
+				if _, ok := settings.Namespaces[namespace]; !ok {
+					log.Fatalf("Error applying quotas: namespace %s is not configured under quotas.namespaces", namespace)
+				}
+				quota, limitRange, err := settings.objects(namespace)
+				if err != nil {
+					log.Fatalf("Error applying quotas: %v", err)
+				}
+				if quota != nil {
+					if err := kubeClient.ApplyResourceQuota(*quota); err != nil {
+						log.Fatalf("Error applying ResourceQuota: %v", err)
+					}
+				}
+				if limitRange != nil {
+					if err := kubeClient.ApplyLimitRange(*limitRange); err != nil {
+						log.Fatalf("Error applying LimitRange: %v", err)
+					}
+				}
+			}
+		},
+	}
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show quota usage against hard limits",
+		Long: `Show the usage of every resource tracked by the ResourceQuotas in the namespace
+and warn about those above quotas.warn_threshold percent of their hard limit.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			allNamespaces, _ := cmd.Flags().GetBool("all-namespaces")
+			settings, err := loadQuotaSettings()
+			if err != nil {
+				log.Fatalf("Error loading quotas: %v", err)
+			}
+			threshold := settings.WarnThreshold
+			if cmd.Flags().Changed("threshold") {
+				threshold, _ = cmd.Flags().GetFloat64("threshold")
+			}
+
+			namespace := viper.GetString("kubernetes.namespace")
+			if allNamespaces {
+				namespace = ""
+			}
+			usages, err := kubeClient.QuotaStatus(namespace)
+			if err != nil {
+				log.Fatalf("Error fetching quota status: %v", err)
+			}
+			if len(usages) == 0 {
+				fmt.Println("No resource quotas found.")
+				return
+			}
+
+			var warnings []kube.QuotaUsage
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAMESPACE\tQUOTA\tRESOURCE\tUSED\tHARD\tUSED%\tSTATUS")
+			for _, usage := range usages {
+				percent, ok := usage.Percent()
+				status := "ok"
+				if ok && percent >= threshold {
+					status = "WARN"
+					warnings = append(warnings, usage)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", usage.Namespace, usage.Quota, usage.Resource,
+					usage.Used.String(), usage.Hard.String(), formatPercent(percent, ok), status)
+			}
+			w.Flush()
+
+			if len(warnings) > 0 {
+				fmt.Println()
+				for _, usage := range warnings {
+					percent, _ := usage.Percent()
+					fmt.Printf("✗ %s/%s: %s is at %.0f%% of its quota (%s of %s)\n", usage.Namespace, usage.Quota,
+						usage.Resource, percent, usage.Used.String(), usage.Hard.String())
+				}
+			}
+		},
+	}
+	statusCmd.Flags().BoolP("all-namespaces", "A", false, "Show quotas in all namespaces")
+	statusCmd.Flags().Float64("threshold", defaultQuotaThreshold, "Warn when usage reaches this percentage of the hard limit (overrides quotas.warn_threshold)")
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete <namespace>",
+		Short: "Delete the ResourceQuota and LimitRange golkube manages in a namespace",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			settings, err := loadQuotaSettings()
+			if err != nil {
+				log.Fatalf("Error loading quotas: %v", err)
+			}
+			if err := kubeClient.DeleteResourceQuota(settings.Name, args[0]); err != nil {
+				log.Fatalf("Error deleting ResourceQuota: %v", err)
+			}
+			if err := kubeClient.DeleteLimitRange(settings.Name, args[0]); err != nil {
+				log.Fatalf("Error deleting LimitRange: %v", err)
+			}
+		},
+	}
+
+	quotaCmd.AddCommand(applyCmd, statusCmd, deleteCmd)
+	RootCmd.AddCommand(quotaCmd)
+}
+
+// loadQuotaSettings reads the "quotas" block of the configuration file and checks that
+// every quantity in it parses
+func loadQuotaSettings() (quotaSettings, error) {
+	var settings quotaSettings
+	if err := viper.UnmarshalKey("quotas", &settings); err != nil {
+		return settings, fmt.Errorf("failed to parse quotas: %w", err)
+	}
+	if settings.Name == "" {
+		settings.Name = defaultQuotaName
+	}
+	if settings.WarnThreshold <= 0 {
+		settings.WarnThreshold = defaultQuotaThreshold
+	}
+	for namespace := range settings.Namespaces {
+		if _, _, err := settings.objects(namespace); err != nil {
+			return settings, err
+		}
+	}
+	return settings, nil
+}
+
+// objects converts the settings of a namespace to the ResourceQuota and LimitRange to
+// apply. Either is nil when the namespace configures nothing for it.
+func (s quotaSettings) objects(namespace string) (*kube.ResourceQuotaConfig, *kube.LimitRangeConfig, error) {
+	settings := s.Namespaces[namespace]
+	labels := map[string]string{"app.kubernetes.io/managed-by": "golkube"}
+
+	var quota *kube.ResourceQuotaConfig
+	if len(settings.Hard) > 0 {
+		hard, err := parseResourceList(settings.Hard)
+		if err != nil {
+			return nil, nil, fmt.Errorf("quotas.namespaces.%s.hard: %w", namespace, err)
+		}
+		quota = &kube.ResourceQuotaConfig{Name: s.Name, Namespace: namespace, Labels: labels, Hard: hard}
+	}
+
+	var err error
+	item := corev1.LimitRangeItem{Type: corev1.LimitTypeContainer}
+	parse := func(field string, values map[string]string) corev1.ResourceList {
+		list, parseErr := parseResourceList(values)
+		if parseErr != nil && err == nil {
+			err = fmt.Errorf("quotas.namespaces.%s.container.%s: %w", namespace, field, parseErr)
+		}
+		return list
+	}
+	item.Default = parse("default", settings.Container.Default)
+	item.DefaultRequest = parse("default_request", settings.Container.DefaultRequest)
+	item.Min = parse("min", settings.Container.Min)
+	item.Max = parse("max", settings.Container.Max)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var limitRange *kube.LimitRangeConfig
+	if len(item.Default)+len(item.DefaultRequest)+len(item.Min)+len(item.Max) > 0 {
+		limitRange = &kube.LimitRangeConfig{Name: s.Name, Namespace: namespace, Labels: labels, Limits: []corev1.LimitRangeItem{item}}
+	}
+	return quota, limitRange, nil
+}
+
+// parseResourceList parses a map of resource names to quantities such as "500m" or "2Gi"
+func parseResourceList(values map[string]string) (corev1.ResourceList, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	list := make(corev1.ResourceList, len(values))
+	for name, value := range values {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity %q for %s", value, name)
+		}
+		list[corev1.ResourceName(name)] = quantity
+	}
+	return list, nil
+}
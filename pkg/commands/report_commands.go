@@ -0,0 +1,301 @@
+package commands
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"golkube/pkg/kube"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// hoursPerMonth is the average number of hours in a month used for monthly costs
+const hoursPerMonth = 730
+
+// bytesPerGB is the size of the GB that prices are quoted per (a gibibyte)
+const bytesPerGB = 1024 * 1024 * 1024
+
+// reportPricing holds the prices applied to reserved resources, from report.pricing
+type reportPricing struct {
+	Currency               string  `mapstructure:"currency" json:"currency"`
+	CPUCoreHour            float64 `mapstructure:"cpu_core_hour" json:"cpu_core_hour"`
+	MemoryGBHour           float64 `mapstructure:"memory_gb_hour" json:"memory_gb_hour"`
+	EphemeralStorageGBHour float64 `mapstructure:"ephemeral_storage_gb_hour" json:"ephemeral_storage_gb_hour"`
+}
+
+// capacityRow is one line of a capacity report. CPU is in millicores and memory and
+// ephemeral storage in bytes; percentages are requests against node allocatable.
+type capacityRow struct {
+	Namespace               string   `json:"namespace,omitempty"`
+	Name                    string   `json:"name"`
+	Pods                    int      `json:"pods"`
+	CPURequest              int64    `json:"cpu_request_millicores"`
+	CPULimit                int64    `json:"cpu_limit_millicores"`
+	CPUPercent              *float64 `json:"cpu_request_percent,omitempty"`
+	MemoryRequest           int64    `json:"memory_request_bytes"`
+	MemoryLimit             int64    `json:"memory_limit_bytes"`
+	MemoryPercent           *float64 `json:"memory_request_percent,omitempty"`
+	EphemeralStorageRequest int64    `json:"ephemeral_storage_request_bytes"`
+	EphemeralStorageLimit   int64    `json:"ephemeral_storage_limit_bytes"`
+	CostPerHour             float64  `json:"cost_per_hour"`
+	CostPerMonth            float64  `json:"cost_per_month"`
+}
+
+// capacityView is the JSON form of a capacity report
+type capacityView struct {
+	GroupBy     string        `json:"group_by"`
+	Nodes       int           `json:"nodes"`
+	Pricing     reportPricing `json:"pricing"`
+	Groups      []capacityRow `json:"groups"`
+	Total       capacityRow   `json:"total"`
+	Allocatable capacityRow   `json:"allocatable"`
+}
+
+// RegisterReportCommands registers the cluster reporting commands
+func RegisterReportCommands(kubeClient *kube.KubeClient) {
+	reportCmd := &cobra.Command{
+		Use:   "report",
+		Short: "Generate cluster reports",
+	}
+
+	capacityCmd := &cobra.Command{
+		Use:   "capacity",
+		Short: "Total resource requests and limits and compare them with node allocatable",
+		Long: `Total the CPU, memory and ephemeral-storage requests and limits of running pods per
+namespace, workload or label value, and compare the requests with the allocatable
+resources of the schedulable nodes.
+
+Costs are estimated from the requests using the prices under report.pricing in the
+configuration file, or the --cpu-price, --memory-price and --storage-price flags.
+Monthly costs assume 730 hours.
+
+Examples:
+  golkube report capacity --all-namespaces
+  golkube report capacity --by workload
+  golkube report capacity --by label=team -A -o csv`,
+		Run: func(cmd *cobra.Command, args []string) {
+			by, _ := cmd.Flags().GetString("by")
+			output, _ := cmd.Flags().GetString("output")
+			allNamespaces, _ := cmd.Flags().GetBool("all-namespaces")
+
+			if output != "table" && output != "csv" && output != "json" {
+				log.Fatalf("Invalid output format %q: must be table, csv or json", output)
+			}
+			groupBy, labelKey, _ := strings.Cut(by, "=")
+			if groupBy == kube.GroupByLabel && labelKey == "" {
+				log.Fatalf("Invalid --by value %q: expected label=<key>, e.g. label=team", by)
+			}
+
+			pricing, err := loadReportPricing()
+			if err != nil {
+				log.Fatalf("Error loading report pricing: %v", err)
+			}
+			if cmd.Flags().Changed("cpu-price") {
+				pricing.CPUCoreHour, _ = cmd.Flags().GetFloat64("cpu-price")
+			}
+			if cmd.Flags().Changed("memory-price") {
+				pricing.MemoryGBHour, _ = cmd.Flags().GetFloat64("memory-price")
+			}
+			if cmd.Flags().Changed("storage-price") {
+				pricing.EphemeralStorageGBHour, _ = cmd.Flags().GetFloat64("storage-price")
+			}
+
+			namespace := viper.GetString("kubernetes.namespace")
+			if allNamespaces {
+				namespace = ""
+			}
+			report, err := kubeClient.CapacityReport(namespace, groupBy, labelKey)
+			if err != nil {
+				log.Fatalf("Error building capacity report: %v", err)
+			}
+
+			view := capacityView{
+				GroupBy:     by,
+				Nodes:       report.Nodes,
+				Pricing:     pricing,
+				Groups:      make([]capacityRow, 0, len(report.Groups)),
+				Total:       toCapacityRow(report.Total, report.Allocatable, pricing),
+				Allocatable: toCapacityRow(kube.CapacityGroup{Name: "ALLOCATABLE", Requests: report.Allocatable}, nil, pricing),
+			}
+			for _, group := range report.Groups {
+				view.Groups = append(view.Groups, toCapacityRow(group, report.Allocatable, pricing))
+			}
+
+			switch output {
+			case "json":
+				data, err := json.MarshalIndent(view, "", "  ")
+				if err != nil {
+					log.Fatalf("Error encoding capacity report: %v", err)
+				}
+				fmt.Println(string(data))
+			case "csv":
+				printCapacityCSV(view, groupBy == kube.GroupByWorkload)
+			default:
+				printCapacityTable(view, groupBy == kube.GroupByWorkload)
+			}
+		},
+	}
+	capacityCmd.Flags().String("by", kube.GroupByNamespace, "Group by namespace, workload or label=<key> (e.g. label=team)")
+	capacityCmd.Flags().StringP("output", "o", "table", "Output format: table, csv or json")
+	capacityCmd.Flags().BoolP("all-namespaces", "A", false, "Report on pods in all namespaces")
+	capacityCmd.Flags().Float64("cpu-price", 0, "Price per CPU core-hour (overrides report.pricing.cpu_core_hour)")
+	capacityCmd.Flags().Float64("memory-price", 0, "Price per GB-hour of memory (overrides report.pricing.memory_gb_hour)")
+	capacityCmd.Flags().Float64("storage-price", 0, "Price per GB-hour of ephemeral storage (overrides report.pricing.ephemeral_storage_gb_hour)")
+
+	reportCmd.AddCommand(capacityCmd)
+	RootCmd.AddCommand(reportCmd)
+}
+
+// loadReportPricing reads the "report.pricing" block of the configuration file
+func loadReportPricing() (reportPricing, error) {
+	var pricing reportPricing
+	if err := viper.UnmarshalKey("report.pricing", &pricing); err != nil {
+		return pricing, fmt.Errorf("failed to parse report.pricing: %w", err)
+	}
+	if pricing.CPUCoreHour < 0 || pricing.MemoryGBHour < 0 || pricing.EphemeralStorageGBHour < 0 {
+		return pricing, fmt.Errorf("prices in report.pricing must not be negative")
+	}
+	return pricing, nil
+}
+
+// toCapacityRow converts a capacity group to a report row, pricing its requests.
+// Percentages are left out when allocatable is nil.
+func toCapacityRow(group kube.CapacityGroup, allocatable corev1.ResourceList, pricing reportPricing) capacityRow {
+	cpuRequest := group.Requests[corev1.ResourceCPU]
+	memoryRequest := group.Requests[corev1.ResourceMemory]
+	storageRequest := group.Requests[corev1.ResourceEphemeralStorage]
+	cpuLimit := group.Limits[corev1.ResourceCPU]
+	memoryLimit := group.Limits[corev1.ResourceMemory]
+	storageLimit := group.Limits[corev1.ResourceEphemeralStorage]
+
+	row := capacityRow{
+		Namespace:               group.Namespace,
+		Name:                    group.Name,
+		Pods:                    group.Pods,
+		CPURequest:              cpuRequest.MilliValue(),
+		CPULimit:                cpuLimit.MilliValue(),
+		MemoryRequest:           memoryRequest.Value(),
+		MemoryLimit:             memoryLimit.Value(),
+		EphemeralStorageRequest: storageRequest.Value(),
+		EphemeralStorageLimit:   storageLimit.Value(),
+	}
+	if allocatable != nil {
+		if percent, ok := kube.UsagePercent(cpuRequest, allocatable, corev1.ResourceCPU); ok {
+			row.CPUPercent = &percent
+		}
+		if percent, ok := kube.UsagePercent(memoryRequest, allocatable, corev1.ResourceMemory); ok {
+			row.MemoryPercent = &percent
+		}
+	}
+
+	costPerHour := float64(row.CPURequest)/1000*pricing.CPUCoreHour +
+		float64(row.MemoryRequest)/bytesPerGB*pricing.MemoryGBHour +
+		float64(row.EphemeralStorageRequest)/bytesPerGB*pricing.EphemeralStorageGBHour
+	row.CostPerHour = math.Round(costPerHour*10000) / 10000
+	row.CostPerMonth = math.Round(costPerHour*hoursPerMonth*100) / 100
+	return row
+}
+
+// printCapacityTable prints a capacity report followed by its total and the node allocatable
+func printCapacityTable(view capacityView, withNamespace bool) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if withNamespace {
+		fmt.Fprint(w, "NAMESPACE\t")
+	}
+	fmt.Fprintln(w, "NAME\tPODS\tCPU REQ\tCPU LIM\tCPU%\tMEM REQ\tMEM LIM\tMEM%\tEPHEMERAL REQ\tEPHEMERAL LIM\tCOST/HOUR\tCOST/MONTH")
+
+	printRow := func(row capacityRow, allocatable bool) {
+		pods := strconv.Itoa(row.Pods)
+		cpuLimit := formatCPU(*resource.NewMilliQuantity(row.CPULimit, resource.DecimalSI))
+		memoryLimit := formatMemory(*resource.NewQuantity(row.MemoryLimit, resource.BinarySI))
+		storageLimit := formatMemory(*resource.NewQuantity(row.EphemeralStorageLimit, resource.BinarySI))
+		if allocatable {
+			// Nodes have no pods or limits of their own
+			pods, cpuLimit, memoryLimit, storageLimit = "-", "-", "-", "-"
+		}
+		if withNamespace {
+			fmt.Fprintf(w, "%s\t", row.Namespace)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			row.Name, pods,
+			formatCPU(*resource.NewMilliQuantity(row.CPURequest, resource.DecimalSI)), cpuLimit,
+			formatPercentOf(row.CPUPercent),
+			formatMemory(*resource.NewQuantity(row.MemoryRequest, resource.BinarySI)), memoryLimit,
+			formatPercentOf(row.MemoryPercent),
+			formatMemory(*resource.NewQuantity(row.EphemeralStorageRequest, resource.BinarySI)), storageLimit,
+			formatCost(row.CostPerHour, 4, view.Pricing.Currency),
+			formatCost(row.CostPerMonth, 2, view.Pricing.Currency),
+		)
+	}
+	for _, row := range view.Groups {
+		printRow(row, false)
+	}
+	printRow(view.Total, false)
+	printRow(view.Allocatable, true)
+	w.Flush()
+	fmt.Printf("\nSchedulable nodes: %d. Costs are estimated from requests.\n", view.Nodes)
+}
+
+// printCapacityCSV prints a capacity report as CSV with raw values, for spreadsheets
+func printCapacityCSV(view capacityView, withNamespace bool) {
+	w := csv.NewWriter(os.Stdout)
+	header := []string{"name", "pods", "cpu_request_millicores", "cpu_limit_millicores", "cpu_request_percent",
+		"memory_request_bytes", "memory_limit_bytes", "memory_request_percent",
+		"ephemeral_storage_request_bytes", "ephemeral_storage_limit_bytes", "cost_per_hour", "cost_per_month"}
+	if withNamespace {
+		header = append([]string{"namespace"}, header...)
+	}
+	w.Write(header)
+
+	percent := func(value *float64) string {
+		if value == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*value, 'f', 2, 64)
+	}
+	rows := append(append([]capacityRow{}, view.Groups...), view.Total, view.Allocatable)
+	for _, row := range rows {
+		record := []string{
+			row.Name, strconv.Itoa(row.Pods),
+			strconv.FormatInt(row.CPURequest, 10), strconv.FormatInt(row.CPULimit, 10), percent(row.CPUPercent),
+			strconv.FormatInt(row.MemoryRequest, 10), strconv.FormatInt(row.MemoryLimit, 10), percent(row.MemoryPercent),
+			strconv.FormatInt(row.EphemeralStorageRequest, 10), strconv.FormatInt(row.EphemeralStorageLimit, 10),
+			strconv.FormatFloat(row.CostPerHour, 'f', 4, 64), strconv.FormatFloat(row.CostPerMonth, 'f', 2, 64),
+		}
+		if withNamespace {
+			record = append([]string{row.Namespace}, record...)
+		}
+		w.Write(record)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Fatalf("Error writing CSV: %v", err)
+	}
+}
+
+// formatPercentOf renders an optional percentage
+func formatPercentOf(percent *float64) string {
+	if percent == nil {
+		return "-"
+	}
+	return formatPercent(*percent, true)
+}
+
+// formatCost renders a cost with its currency. Hourly costs of single workloads are
+// often fractions of a cent, so callers pick the number of decimals.
+func formatCost(cost float64, decimals int, currency string) string {
+	if currency == "" {
+		return fmt.Sprintf("%.*f", decimals, cost)
+	}
+	return fmt.Sprintf("%.*f %s", decimals, cost, currency)
+}
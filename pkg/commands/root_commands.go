@@ -29,9 +29,54 @@ func RegisterCommands(kubeClient *kube.KubeClient, dockerRegistry *registry.Regi
 	// Register configuration commands
 	RegisterConfigCommands()
 
+	// Register manifest policy commands
+	RegisterPolicyCommands()
+
+	// Register manifest schema validation
+	RegisterValidateCommands(func() (*kube.KubeClient, error) { return kubeClient, nil })
+
 	// Register utility commands like "monitor"
 	RegisterUtilityCommands(kubeClient)
 
+	// Register the cluster event viewer
+	RegisterEventCommands(kubeClient)
+
+	// Register the interactive dashboard
+	RegisterDashboardCommands(kubeClient)
+
+	// Register node maintenance commands
+	RegisterNodeCommands(kubeClient)
+
+	// Register cleanup commands
+	RegisterCleanupCommands(kubeClient)
+
+	// Register namespace backup and restore commands
+	RegisterBackupCommands(kubeClient)
+
+	// Register resource usage commands
+	RegisterTopCommands(kubeClient)
+
+	// Register capacity and cost reports
+	RegisterReportCommands(kubeClient)
+
+	// Register ResourceQuota and LimitRange commands
+	RegisterQuotaCommands(kubeClient)
+
+	// Register the inner-loop development command
+	RegisterDevCommands(kubeClient)
+
+	// Register the one-shot app deploy command
+	RegisterDeployCommands(kubeClient)
+
+	// Register manifest generation from Docker images
+	RegisterScaffoldCommands()
+
+	// Register notification commands
+	RegisterNotifyCommands()
+
+	// Register alert rule and silence commands
+	RegisterAlertCommands()
+
 	// Register pipeline-related commands
 	RegisterPipelineCommand()
 }
@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"golkube/pkg/docker"
+	"golkube/pkg/kube"
+	"golkube/pkg/scaffold"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// RegisterScaffoldCommands registers the command generating manifests from an image
+func RegisterScaffoldCommands() {
+	scaffoldCmd := &cobra.Command{
+		Use:   "scaffold",
+		Short: "Generate a Deployment and Service from a Docker image",
+		Long: `Inspect a Docker image and generate a Deployment and Service for it. Exposed ports
+become container and service ports, a HEALTHCHECK becomes matching exec readiness and
+liveness probes, and the image's env, entrypoint, cmd, user and OCI labels are carried
+over. The manifests are a starting point: review the printed notes, add resource
+requests and limits, check them with "golkube validate" and "golkube policy check",
+then apply them with kubectl.
+
+The image is read from the local Docker daemon and pulled when missing. Images
+referenced without a version tag, or as latest, are pinned to their registry digest.
+
+Examples:
+  golkube scaffold --image nginx:1.27
+  golkube scaffold --image registry.example.com/team/api:2.3 --name api --replicas 3 --out deploy/`,
+		Run: func(cmd *cobra.Command, args []string) {
+			image, _ := cmd.Flags().GetString("image")
+			name, _ := cmd.Flags().GetString("name")
+			replicas, _ := cmd.Flags().GetInt32("replicas")
+			serviceType, _ := cmd.Flags().GetString("service-type")
+			outDir, _ := cmd.Flags().GetString("out")
+			pull, _ := cmd.Flags().GetBool("pull")
+
+			inspect, err := docker.InspectImage(image, pull)
+			if err != nil {
+				log.Fatalf("Error reading image: %v", err)
+			}
+			result, err := scaffold.FromImage(image, inspect, scaffold.Options{
+				Name:        name,
+				Namespace:   viper.GetString("kubernetes.namespace"),
+				Replicas:    replicas,
+				ServiceType: corev1.ServiceType(serviceType),
+			})
+			if err != nil {
+				log.Fatalf("Error generating manifests: %v", err)
+			}
+			manifests, err := result.Manifests()
+			if err != nil {
+				log.Fatalf("Error generating manifests: %v", err)
+			}
+
+			if outDir == "" {
+				for _, manifest := range manifests {
+					data, err := yaml.Marshal(manifest.Object)
+					if err != nil {
+						log.Fatalf("Error encoding %s: %v", manifest.GetKind(), err)
+					}
+					fmt.Printf("---\n%s", data)
+				}
+			} else {
+				for _, manifest := range manifests {
+					path, err := kube.WriteManifest(outDir, manifest)
+					if err != nil {
+						log.Fatalf("Error writing manifest: %v", err)
+					}
+					fmt.Printf("✓ Wrote %s\n", path)
+				}
+			}
+
+			// Notes go to stderr so that the manifests can be piped
+			for _, warning := range result.Warnings {
+				fmt.Fprintf(os.Stderr, "Note: %s\n", warning)
+			}
+		},
+	}
+	scaffoldCmd.Flags().String("image", "", "Image to generate manifests for (required)")
+	scaffoldCmd.Flags().String("name", "", "Name of the Deployment and Service; defaults to the image name")
+	scaffoldCmd.Flags().Int32("replicas", 1, "Number of replicas")
+	scaffoldCmd.Flags().String("service-type", string(corev1.ServiceTypeClusterIP), "Service type: ClusterIP, NodePort or LoadBalancer")
+	scaffoldCmd.Flags().String("out", "", "Directory to write the manifests to; prints them when empty")
+	scaffoldCmd.Flags().Bool("pull", true, "Pull the image when it is not available locally")
+	scaffoldCmd.MarkFlagRequired("image")
+
+	RootCmd.AddCommand(scaffoldCmd)
+}
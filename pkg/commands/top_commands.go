@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"golkube/pkg/kube"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// RegisterTopCommands registers the "top" command for resource usage from metrics.k8s.io.
+func RegisterTopCommands(kubeClient *kube.KubeClient) {
+	topCmd := &cobra.Command{
+		Use:   "top",
+		Short: "Show CPU and memory usage of pods and nodes",
+	}
+
+	topPodsCmd := &cobra.Command{
+		Use:   "pods",
+		Short: "Show pod usage against requests and limits",
+		Long: `Show pod usage against requests and limits.
+
+A pod's limit percentage is shown as "-" unless every container in the pod
+sets a limit for that resource, since a container without one is unbounded.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			selector, _ := cmd.Flags().GetString("selector")
+			showContainers, _ := cmd.Flags().GetBool("containers")
+			namespace := viper.GetString("kubernetes.namespace")
+
+			usages, err := kubeClient.GetPodMetrics(namespace, selector)
+			if err != nil {
+				log.Fatalf("Error fetching pod metrics: %v", err)
+			}
+			if len(usages) == 0 {
+				fmt.Println("No pod metrics found.")
+				return
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			if showContainers {
+				fmt.Fprintln(w, "POD\tCONTAINER\tCPU\tCPU/REQ\tCPU/LIM\tMEMORY\tMEM/REQ\tMEM/LIM")
+				for _, pod := range usages {
+					for _, container := range pod.Containers {
+						fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", pod.Name, container.Name,
+							formatCPU(container.CPU),
+							formatPercent(kube.UsagePercent(container.CPU, container.Requests, corev1.ResourceCPU)),
+							formatPercent(kube.UsagePercent(container.CPU, container.Limits, corev1.ResourceCPU)),
+							formatMemory(container.Memory),
+							formatPercent(kube.UsagePercent(container.Memory, container.Requests, corev1.ResourceMemory)),
+							formatPercent(kube.UsagePercent(container.Memory, container.Limits, corev1.ResourceMemory)),
+						)
+					}
+				}
+			} else {
+				fmt.Fprintln(w, "POD\tCPU\tCPU/REQ\tCPU/LIM\tMEMORY\tMEM/REQ\tMEM/LIM")
+				for _, pod := range usages {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", pod.Name,
+						formatCPU(pod.CPU),
+						formatPercent(pod.RequestPercent(corev1.ResourceCPU)),
+						formatPercent(pod.LimitPercent(corev1.ResourceCPU)),
+						formatMemory(pod.Memory),
+						formatPercent(pod.RequestPercent(corev1.ResourceMemory)),
+						formatPercent(pod.LimitPercent(corev1.ResourceMemory)),
+					)
+				}
+			}
+			w.Flush()
+		},
+	}
+	topPodsCmd.Flags().StringP("selector", "l", "", "Label selector to filter pods")
+	topPodsCmd.Flags().Bool("containers", false, "Show usage per container")
+
+	topNodesCmd := &cobra.Command{
+		Use:   "nodes",
+		Short: "Show node usage against allocatable resources",
+		Run: func(cmd *cobra.Command, args []string) {
+			selector, _ := cmd.Flags().GetString("selector")
+
+			usages, err := kubeClient.GetNodeMetrics(selector)
+			if err != nil {
+				log.Fatalf("Error fetching node metrics: %v", err)
+			}
+			if len(usages) == 0 {
+				fmt.Println("No node metrics found.")
+				return
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NODE\tCPU\tCPU%\tMEMORY\tMEMORY%")
+			for _, node := range usages {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", node.Name,
+					formatCPU(node.CPU),
+					formatPercent(kube.UsagePercent(node.CPU, node.Allocatable, corev1.ResourceCPU)),
+					formatMemory(node.Memory),
+					formatPercent(kube.UsagePercent(node.Memory, node.Allocatable, corev1.ResourceMemory)),
+				)
+			}
+			w.Flush()
+		},
+	}
+	topNodesCmd.Flags().StringP("selector", "l", "", "Label selector to filter nodes")
+
+	topCmd.AddCommand(topPodsCmd)
+	topCmd.AddCommand(topNodesCmd)
+	RootCmd.AddCommand(topCmd)
+}
+
+// formatCPU renders a CPU quantity in millicores
+func formatCPU(quantity resource.Quantity) string {
+	return fmt.Sprintf("%dm", quantity.MilliValue())
+}
+
+// formatMemory renders a memory quantity in mebibytes
+func formatMemory(quantity resource.Quantity) string {
+	return fmt.Sprintf("%dMi", quantity.Value()/(1024*1024))
+}
+
+// formatPercent renders a percentage, or "-" when there is nothing to compare against
+func formatPercent(percent float64, ok bool) string {
+	if !ok {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", percent)
+}
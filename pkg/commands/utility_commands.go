@@ -1,12 +1,20 @@
 package commands
 
 import (
+	"fmt"
 	"log"
+	"os"
+	"text/tabwriter"
+	"time"
 
+	"golkube/pkg/alert"
 	"golkube/pkg/kube"
+	"golkube/pkg/metrics"
+	"golkube/pkg/notify"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
 )
 
 // registerUtilityCommands registers all utility commands to the root command.
@@ -15,18 +23,185 @@ func RegisterUtilityCommands(kubeClient *kube.KubeClient) {
 	monitorCmd := &cobra.Command{
 		Use:   "monitor",
 		Short: "Monitor Kubernetes resources",
+		Long: `Watch pod health in the current namespace and report state changes.
+
+Notifications are sent to the channels configured under notifications when
+alerting.rules fire: once a condition has held for the rule's "for" duration, again every
+repeat_interval while it persists, and never while a matching silence is active.
+
+Setting monitoring.notify_transitions to true also notifies every pod health transition
+(NotReady, CrashLoopBackOff, OOMKilled, ...) as soon as it happens. These notifications
+carry the labels alertname=PodHealthTransition, namespace, pod and health, and are dropped
+while a matching silence is active.`,
 		Run: func(cmd *cobra.Command, args []string) {
 			namespace := viper.GetString("kubernetes.namespace")
 			monitorInterval := viper.GetDuration("monitoring.interval")
+			listenAddr, _ := cmd.Flags().GetString("listen")
+
+			alertConfig := loadAlertConfig()
+
+			// Transition notifications are opt-in; alert rules are the default path
+			var notifier notify.Notifier
+			if viper.GetBool("monitoring.notify_transitions") {
+				notifier = loadNotifier()
+			}
+			silences := alert.NewSilenceStore(alertConfig.SilencesFile)
+
+			var observers []kube.MonitorObserver
+
+			if len(alertConfig.Rules) > 0 {
+				var notifiers []notify.Notifier
+				if config, err := loadNotifyConfig(); err == nil {
+					notifiers, err = notify.FromConfig(config)
+					if err != nil {
+						log.Printf("Warning: alert notifications disabled: %v", err)
+					}
+				}
+				engine, err := alert.NewEngine(alertConfig, notifiers)
+				if err != nil {
+					log.Fatalf("Invalid alert rules: %v", err)
+				}
+				observers = append(observers, engine)
+			}
+
+			if listenAddr != "" {
+				// Serve metrics alongside the monitoring loop
+				exporter := metrics.NewExporter()
+				observers = append(observers, exporter)
+				go func() {
+					if err := exporter.ListenAndServe(listenAddr); err != nil {
+						log.Fatalf("Error serving metrics: %v", err)
+					}
+				}()
+			}
 
 			// Monitoring Kubernetes resources for pod health
-			err := kubeClient.MonitorPodHealth(namespace, "", monitorInterval)
+			err := kubeClient.MonitorPodHealth(kube.PodHealthConfig{
+				Namespace:          namespace,
+				Interval:           monitorInterval,
+				Metrics:            viper.GetStringSlice("monitoring.metrics"),
+				CPULimitPercent:    viper.GetFloat64("monitoring.thresholds.cpu_limit_percent"),
+				MemoryLimitPercent: viper.GetFloat64("monitoring.thresholds.memory_limit_percent"),
+				Observers:          observers,
+				OnTransition: func(transition kube.PodTransition) {
+					if notifier == nil {
+						return
+					}
+					if msg, ok := transitionNotification(namespace, transition); ok && !transitionSilenced(silences, msg) {
+						go sendNotification(notifier, msg)
+					}
+				},
+			})
 			if err != nil {
 				log.Fatalf("Error monitoring resources: %v", err)
 			}
 		},
 	}
 
-	// Add the monitor command to the root command
+	monitorCmd.Flags().String("listen", viper.GetString("monitoring.listen"), "Serve /metrics, /healthz and /readyz on this address (e.g. :9090)")
+
+	// Command to summarize namespace health from the shared cache
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show a health summary of the current namespace",
+		Run: func(cmd *cobra.Command, args []string) {
+			namespace := viper.GetString("kubernetes.namespace")
+
+			clusterCache, err := kubeClient.Cache(namespace, kube.CacheNodes, kube.CacheEvents)
+			if err != nil {
+				log.Fatalf("Error starting cluster cache: %v", err)
+			}
+			printNamespaceStatus(clusterCache, namespace)
+		},
+	}
+
+	// Add the monitor and status commands to the root command
 	RootCmd.AddCommand(monitorCmd)
+	RootCmd.AddCommand(statusCmd)
+}
+
+// transitionSilenced reports whether an active silence matches a transition notification
+func transitionSilenced(silences *alert.SilenceStore, msg notify.Message) bool {
+	labels := map[string]string{"alertname": "PodHealthTransition", "severity": string(msg.Severity)}
+	for key, value := range msg.Fields {
+		labels[key] = value
+	}
+	silence, err := silences.Silenced(labels, time.Now())
+	if err != nil {
+		log.Printf("Warning: failed to read silences: %v", err)
+	}
+	if silence != nil {
+		fmt.Printf("[TRANSITION SILENCED] %s by silence %s\n", msg.Title, silence.ID)
+		return true
+	}
+	return false
+}
+
+// printNamespaceStatus prints deployments, pod phases, node readiness and recent warnings
+func printNamespaceStatus(clusterCache *kube.ClusterCache, namespace string) {
+	deployments, err := clusterCache.ListDeployments("")
+	if err != nil {
+		log.Fatalf("Error listing deployments: %v", err)
+	}
+	pods, err := clusterCache.ListPods("")
+	if err != nil {
+		log.Fatalf("Error listing pods: %v", err)
+	}
+	nodes, err := clusterCache.ListNodes("")
+	if err != nil {
+		log.Fatalf("Error listing nodes: %v", err)
+	}
+	warnings, err := clusterCache.ListEvents(kube.EventFilter{
+		Types: []string{corev1.EventTypeWarning},
+		Since: time.Hour,
+	})
+	if err != nil {
+		log.Fatalf("Error listing events: %v", err)
+	}
+
+	fmt.Printf("Namespace: %s\n\n", namespace)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DEPLOYMENT\tDESIRED\tREADY\tUP-TO-DATE\tAVAILABLE")
+	for _, deployment := range deployments {
+		var desired int32 = 1
+		if deployment.Spec.Replicas != nil {
+			desired = *deployment.Spec.Replicas
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\n", deployment.Name, desired,
+			deployment.Status.ReadyReplicas, deployment.Status.UpdatedReplicas, deployment.Status.AvailableReplicas)
+	}
+	w.Flush()
+
+	phases := make(map[corev1.PodPhase]int)
+	for _, pod := range pods {
+		phases[pod.Status.Phase]++
+	}
+	fmt.Printf("\nPods: %d total", len(pods))
+	for _, phase := range []corev1.PodPhase{corev1.PodRunning, corev1.PodPending, corev1.PodSucceeded, corev1.PodFailed, corev1.PodUnknown} {
+		if phases[phase] > 0 {
+			fmt.Printf(", %d %s", phases[phase], phase)
+		}
+	}
+	fmt.Println()
+
+	readyNodes := 0
+	for _, node := range nodes {
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == corev1.NodeReady && condition.Status == corev1.ConditionTrue {
+				readyNodes++
+			}
+		}
+	}
+	fmt.Printf("Nodes: %d/%d ready\n", readyNodes, len(nodes))
+
+	fmt.Printf("Warning events (last hour): %d\n", len(warnings))
+	for i := range warnings {
+		event := &warnings[i]
+		fmt.Printf("  %s %s %s: %s\n", kube.FormatAge(kube.EventLastSeen(event)), event.Reason, involvedObject(event), event.Message)
+	}
+
+	metrics := clusterCache.Metrics()
+	fmt.Printf("\nCache: synced=%t hits=%d misses=%d hit-rate=%.0f%%\n",
+		clusterCache.HasSynced(), metrics.Hits, metrics.Misses, metrics.HitRate*100)
 }
@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"golkube/pkg/kube"
+	"golkube/pkg/validation"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// defaultSchemaCacheDir is where schemas fetched from clusters are cached
+const defaultSchemaCacheDir = ".golkube/openapi"
+
+// RunValidate registers the validate command and runs it without creating the clients
+// up front, so validating against a schema directory needs no cluster. It returns the
+// process exit code.
+func RunValidate() int {
+	RegisterValidateCommands(connectKubeClient)
+	if err := RootCmd.Execute(); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// RegisterValidateCommands registers manifest schema validation. connect returns the
+// Kubernetes client and is only called when schemas must come from the cluster.
+func RegisterValidateCommands(connect func() (*kube.KubeClient, error)) {
+	validateCmd := &cobra.Command{
+		Use:   "validate -f <file|dir|->",
+		Short: "Validate manifests against the Kubernetes OpenAPI schemas",
+		Long: `Validate manifests against OpenAPI v3 schemas and report unknown or misspelled
+fields, missing required fields, wrong types and unsupported values with their
+field paths.
+
+Schemas come from --schema-dir or validation.schema_dir when set, which works
+offline, and otherwise from the cluster, cached on disk per cluster under
+validation.cache_dir. Without a reachable cluster or cached schemas, the schemas
+built into golkube are used; they cover the core, apps, batch and discovery APIs,
+and other kinds are reported as skipped unless --strict is set.
+CustomResourceDefinitions among the manifests or in the
+schema directory are used to validate their custom resources.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			files, _ := cmd.Flags().GetStringArray("filename")
+			schemaDir, _ := cmd.Flags().GetString("schema-dir")
+			refresh, _ := cmd.Flags().GetBool("refresh")
+			strict, _ := cmd.Flags().GetBool("strict")
+			if schemaDir == "" {
+				schemaDir = viper.GetString("validation.schema_dir")
+			}
+
+			type manifest struct {
+				source string
+				obj    *unstructured.Unstructured
+			}
+			var manifests []manifest
+			for _, file := range files {
+				objects, err := kube.LoadManifests(file)
+				if err != nil {
+					log.Fatalf("Error loading manifests: %v", err)
+				}
+				for _, obj := range objects {
+					manifests = append(manifests, manifest{source: file, obj: obj})
+				}
+			}
+
+			schemas, err := loadSchemas(schemaDir, refresh, connect)
+			if err != nil {
+				log.Fatalf("Error loading schemas: %v", err)
+			}
+			for _, m := range manifests {
+				if validation.IsCRD(m.obj) {
+					if err := schemas.AddCRD(m.obj); err != nil {
+						log.Fatalf("Error loading schema from %s: %v", m.source, err)
+					}
+				}
+			}
+
+			invalid, skipped := 0, 0
+			for _, m := range manifests {
+				// The built-in schemas lack many common kinds, which are not the manifest's fault
+				if schemas.Builtin() && !strict && !schemas.Covers(m.obj) {
+					skipped++
+					fmt.Printf("- %s/%s (%s): no built-in schema for %s, skipped\n", m.obj.GetKind(), m.obj.GetName(), m.source, m.obj.GetAPIVersion())
+					continue
+				}
+				errors := schemas.Validate(m.obj)
+				if len(errors) == 0 {
+					fmt.Printf("✓ %s/%s (%s)\n", m.obj.GetKind(), m.obj.GetName(), m.source)
+					continue
+				}
+				invalid++
+				fmt.Printf("✗ %s/%s (%s)\n", m.obj.GetKind(), m.obj.GetName(), m.source)
+				for _, fieldError := range errors {
+					fmt.Printf("    %s\n", fieldError.Error())
+				}
+			}
+			if skipped > 0 {
+				fmt.Printf("%d objects validated, %d invalid, %d skipped\n", len(manifests)-skipped, invalid, skipped)
+			} else {
+				fmt.Printf("%d objects validated, %d invalid\n", len(manifests), invalid)
+			}
+			if invalid > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+	validateCmd.Flags().StringArrayP("filename", "f", nil, "Manifest file or directory to validate; - reads standard input (repeatable)")
+	validateCmd.Flags().String("schema-dir", "", "Directory of OpenAPI v3 documents and CRDs to validate against instead of the cluster")
+	validateCmd.Flags().Bool("refresh", false, "Download the cluster's schemas again even if the cache is current")
+	validateCmd.Flags().Bool("strict", false, "Fail objects without a schema instead of skipping them when using the built-in schemas")
+	validateCmd.MarkFlagRequired("filename")
+
+	exportCmd := &cobra.Command{
+		Use:   "export-schemas <dir>",
+		Short: "Save the cluster's OpenAPI schemas to a directory for offline validation",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			kc, err := connect()
+			if err != nil {
+				log.Fatalf("Error initializing Kubernetes client: %v", err)
+			}
+			fetched, reused, err := kc.SaveOpenAPISchemas(args[0], false)
+			if err != nil {
+				log.Fatalf("Error exporting schemas: %v", err)
+			}
+			fmt.Printf("Saved %d schema documents to %s (%d unchanged)\n", fetched, args[0], reused)
+		},
+	}
+
+	validateCmd.AddCommand(exportCmd)
+	RootCmd.AddCommand(validateCmd)
+}
+
+// loadSchemas loads schemas from schemaDir when set, and otherwise from the cluster through
+// the on-disk cache. A cluster that cannot be reached falls back to its cached schemas, and
+// without those to the schemas built into golkube.
+func loadSchemas(schemaDir string, refresh bool, connect func() (*kube.KubeClient, error)) (*validation.SchemaSet, error) {
+	if schemaDir != "" {
+		return validation.LoadSchemaDir(schemaDir)
+	}
+
+	kc, err := connect()
+	if err != nil {
+		log.Printf("Warning: %v; using built-in schemas (use --schema-dir to validate against others)", err)
+		return validation.LoadBuiltinSchemas()
+	}
+	cacheDir := viper.GetString("validation.cache_dir")
+	if cacheDir == "" {
+		cacheDir = defaultSchemaCacheDir
+	}
+	cacheDir = filepath.Join(cacheDir, kc.OpenAPICacheKey())
+
+	if _, _, err := kc.SaveOpenAPISchemas(cacheDir, refresh); err != nil {
+		if _, statErr := os.Stat(cacheDir); statErr != nil {
+			log.Printf("Warning: %v; using built-in schemas (use --schema-dir to validate against others)", err)
+			return validation.LoadBuiltinSchemas()
+		}
+		log.Printf("Warning: %v; using cached schemas from %s", err, cacheDir)
+	}
+	return validation.LoadSchemaDir(cacheDir)
+}
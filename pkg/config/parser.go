@@ -3,18 +3,23 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"gopkg.in/yaml.v3"
 )
 
-// Config represents the structure of the configuration file
+// Config represents the structure of the configuration file. Name names the app's
+// resources and defaults to the image name; ConfigMap entries are stored in a ConfigMap
+// and exposed to the app as environment variables.
 type Config struct {
+	Name          string                 `yaml:"name,omitempty" json:"name,omitempty" validate:"omitempty,hostname_rfc1123"`
 	Namespace     string                 `yaml:"namespace" json:"namespace" validate:"required"`
 	Image         string                 `yaml:"image" json:"image" validate:"required"`
 	Replicas      int                    `yaml:"replicas" json:"replicas" validate:"gte=1"`
 	Ports         []int                  `yaml:"ports" json:"ports" validate:"dive,gt=0"`
 	Env           map[string]string      `yaml:"env" json:"env"`
+	ConfigMap     map[string]string      `yaml:"config_map,omitempty" json:"config_map,omitempty"`
 	Notifications map[string]interface{} `yaml:"notifications" json:"notifications"`
 }
 
@@ -37,6 +42,22 @@ func ParseConfig(filePath string) (*Config, error) {
 	return &config, nil
 }
 
+// AppName returns the name of the app's resources: Name if set, otherwise the image
+// repository without registry, path or tag, e.g. "web" for "registry.example.com/team/web:1.2"
+func (c *Config) AppName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	name := c.Image
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.IndexAny(name, ":@"); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
 // validateConfig validates the configuration using struct tags and custom rules
 func validateConfig(config *Config) error {
 	validate := validator.New()
@@ -0,0 +1,182 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/rivo/tview"
+)
+
+// followLogs streams the logs of the pod in the given row into the logs pane,
+// replacing any stream already running
+func (d *Dashboard) followLogs(row int) {
+	if row < 1 || row > len(d.podRows) {
+		return
+	}
+	pod := d.podRows[row-1]
+	if len(pod.Spec.Containers) == 0 {
+		return
+	}
+	container := pod.Spec.Containers[0].Name
+
+	d.stopLogs()
+	ctx, cancel := context.WithCancel(context.Background())
+	d.logCancel = cancel
+	d.logPod = pod.Name
+
+	d.logs.Clear()
+	d.logs.ScrollToEnd()
+	title := fmt.Sprintf(" Logs %s/%s ", pod.Name, container)
+	if len(pod.Spec.Containers) > 1 {
+		title = fmt.Sprintf(" Logs %s/%s (1 of %d containers) ", pod.Name, container, len(pod.Spec.Containers))
+	}
+	d.logs.SetTitle(title)
+	d.renderPods()
+
+	go func() {
+		stream, err := d.kc.PodLogs(ctx, pod.Name, pod.Namespace, container, true, d.config.LogTail)
+		if err != nil {
+			d.app.QueueUpdateDraw(func() { d.setMessage(err.Error()) })
+			return
+		}
+		defer stream.Close()
+
+		// The stream is closed by cancelling ctx, so errors after that are expected
+		if _, err := io.Copy(d.logs, stream); err != nil && ctx.Err() == nil {
+			d.app.QueueUpdateDraw(func() { d.setMessage("log stream ended: " + err.Error()) })
+		}
+	}()
+}
+
+// stopLogs cancels the running log stream, if any
+func (d *Dashboard) stopLogs() {
+	if d.logCancel != nil {
+		d.logCancel()
+		d.logCancel = nil
+	}
+	d.logPod = ""
+}
+
+// confirmRestart asks before restarting the selected deployment or pod. A deployment
+// gets a rolling restart; a pod is deleted so its controller replaces it.
+func (d *Dashboard) confirmRestart() {
+	switch d.app.GetFocus() {
+	case d.deployments:
+		row, _ := d.deployments.GetSelection()
+		if row < 1 || row > len(d.deployRows) {
+			return
+		}
+		deployment := d.deployRows[row-1]
+		d.confirm(fmt.Sprintf("Restart deployment %s?", deployment.Name), func() error {
+			if err := d.kc.RestartDeployment(deployment.Name, deployment.Namespace); err != nil {
+				return err
+			}
+			d.app.QueueUpdateDraw(func() { d.setMessage("restarted deployment " + deployment.Name) })
+			return nil
+		})
+
+	case d.pods:
+		row, _ := d.pods.GetSelection()
+		if row < 1 || row > len(d.podRows) {
+			return
+		}
+		pod := d.podRows[row-1]
+		d.confirm(fmt.Sprintf("Delete pod %s so it is recreated?", pod.Name), func() error {
+			return d.kc.DeletePod(pod.Name, pod.Namespace)
+		})
+	}
+}
+
+// promptScale asks for a new replica count for the selected or drilled-in deployment
+func (d *Dashboard) promptScale() {
+	deployment := d.workload
+	if d.app.GetFocus() == d.deployments {
+		if row, _ := d.deployments.GetSelection(); row >= 1 && row <= len(d.deployRows) {
+			deployment = &d.deployRows[row-1]
+		}
+	}
+	if deployment == nil {
+		return
+	}
+	name, namespace := deployment.Name, deployment.Namespace
+	current := int32(1)
+	if deployment.Spec.Replicas != nil {
+		current = *deployment.Spec.Replicas
+	}
+
+	form := tview.NewForm()
+	form.AddInputField("Replicas", strconv.Itoa(int(current)), 10, tview.InputFieldInteger, nil)
+	form.AddButton("Scale", func() {
+		value := form.GetFormItemByLabel("Replicas").(*tview.InputField).GetText()
+		replicas, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || replicas < 0 {
+			d.setMessage(fmt.Sprintf("invalid replica count %q", value))
+			return
+		}
+		d.closeDialog()
+		d.runAction(func() error {
+			if err := d.kc.ScaleDeployment(name, namespace, int32(replicas)); err != nil {
+				return err
+			}
+			d.app.QueueUpdateDraw(func() { d.setMessage(fmt.Sprintf("scaled %s to %d replicas", name, replicas)) })
+			return nil
+		})
+	})
+	form.AddButton("Cancel", d.closeDialog)
+	form.SetCancelFunc(d.closeDialog)
+	form.SetBorder(true).SetTitle(fmt.Sprintf(" Scale %s ", name))
+
+	d.showDialog(centered(form, 40, 7))
+}
+
+// confirm shows a yes/no dialog and runs action in the background on yes
+func (d *Dashboard) confirm(question string, action func() error) {
+	modal := tview.NewModal().
+		SetText(question).
+		AddButtons([]string{"Yes", "No"}).
+		SetDoneFunc(func(index int, label string) {
+			d.closeDialog()
+			if label == "Yes" {
+				d.runAction(action)
+			}
+		})
+	d.showDialog(modal)
+}
+
+// runAction runs a cluster call off the UI goroutine and reports failures in the status line
+func (d *Dashboard) runAction(action func() error) {
+	go func() {
+		if err := action(); err != nil {
+			d.app.QueueUpdateDraw(func() { d.setMessage(err.Error()) })
+		}
+	}()
+}
+
+// showDialog displays a primitive above the panes
+func (d *Dashboard) showDialog(dialog tview.Primitive) {
+	d.lastFocus = d.app.GetFocus()
+	d.pages.AddPage("dialog", dialog, true, true)
+	d.app.SetFocus(dialog)
+}
+
+// closeDialog removes the dialog and returns focus to the panes
+func (d *Dashboard) closeDialog() {
+	d.pages.RemovePage("dialog")
+	if d.lastFocus == nil {
+		d.lastFocus = d.deployments
+	}
+	d.app.SetFocus(d.lastFocus)
+}
+
+// centered wraps a primitive so it is drawn at a fixed size in the middle of the screen
+func centered(p tview.Primitive, width, height int) tview.Primitive {
+	return tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(p, height, 0, true).
+			AddItem(nil, 0, 1, false), width, 0, true).
+		AddItem(nil, 0, 1, false)
+}
@@ -0,0 +1,497 @@
+package dashboard
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"golkube/pkg/kube"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Config controls what the dashboard shows and how often it refreshes
+type Config struct {
+	Namespace     string
+	LabelSelector string
+	// Refresh is how often the panes are redrawn from the informer cache
+	Refresh time.Duration
+	// EventWindow is how far back Warning events are shown
+	EventWindow time.Duration
+	// LogTail is the number of existing log lines shown when following a pod
+	LogTail int64
+}
+
+// snapshot is the data behind one redraw, read from the cache off the UI goroutine
+type snapshot struct {
+	deployments []appsv1.Deployment
+	pods        []corev1.Pod
+	events      []corev1.Event
+	synced      bool
+	err         error
+}
+
+// Dashboard is a full-screen terminal UI for the health of a namespace.
+// All fields below app are owned by the tview event loop.
+type Dashboard struct {
+	kc     *kube.KubeClient
+	cache  *kube.ClusterCache
+	config Config
+
+	app         *tview.Application
+	pages       *tview.Pages
+	deployments *tview.Table
+	pods        *tview.Table
+	events      *tview.Table
+	logs        *tview.TextView
+	status      *tview.TextView
+	panes       []tview.Primitive
+
+	data       snapshot
+	refreshed  time.Time
+	workload   *appsv1.Deployment
+	podRows    []corev1.Pod
+	deployRows []appsv1.Deployment
+	message    string
+
+	logPod    string
+	logCancel context.CancelFunc
+	lastFocus tview.Primitive
+}
+
+// New creates a dashboard backed by the shared cluster cache for the namespace
+func New(kc *kube.KubeClient, config Config) (*Dashboard, error) {
+	if config.Refresh <= 0 {
+		config.Refresh = 2 * time.Second
+	}
+	if config.EventWindow <= 0 {
+		config.EventWindow = time.Hour
+	}
+	if config.LogTail <= 0 {
+		config.LogTail = 200
+	}
+
+	clusterCache, err := kc.Cache(config.Namespace, kube.CacheEvents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start cluster cache: %w", err)
+	}
+
+	d := &Dashboard{
+		kc:     kc,
+		cache:  clusterCache,
+		config: config,
+		app:    tview.NewApplication(),
+	}
+	d.layout()
+	return d, nil
+}
+
+// layout builds the panes and key bindings
+func (d *Dashboard) layout() {
+	d.deployments = newTable("Deployments")
+	d.pods = newTable("Pods")
+	d.events = newTable("Warning events")
+
+	d.logs = tview.NewTextView().SetScrollable(true).SetMaxLines(2000)
+	d.logs.SetBorder(true).SetTitle(" Logs ")
+	// The view keeps tailing while scrolled to the end; scrolling up pauses it
+	d.logs.SetChangedFunc(func() { d.app.Draw() })
+
+	d.status = tview.NewTextView().SetDynamicColors(true)
+	help := tview.NewTextView().SetDynamicColors(true).SetText(
+		"[yellow]Tab[-] pane  [yellow]Enter[-] drill in / logs  [yellow]Esc[-] back  [yellow]l[-] logs  " +
+			"[yellow]f[-] tail logs  [yellow]r[-] restart  [yellow]s[-] scale  [yellow]q[-] quit")
+
+	d.deployments.SetSelectedFunc(func(row, column int) { d.drillIn(row) })
+	d.pods.SetSelectedFunc(func(row, column int) { d.followLogs(row) })
+
+	top := tview.NewFlex().
+		AddItem(d.deployments, 0, 2, true).
+		AddItem(d.events, 0, 3, false)
+	main := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(d.status, 1, 0, false).
+		AddItem(top, 0, 1, true).
+		AddItem(d.pods, 0, 1, false).
+		AddItem(d.logs, 0, 1, false).
+		AddItem(help, 1, 0, false)
+
+	d.panes = []tview.Primitive{d.deployments, d.pods, d.events, d.logs}
+	d.pages = tview.NewPages().AddPage("main", main, true, true)
+	d.app.SetRoot(d.pages, true).SetFocus(d.deployments)
+	d.app.SetInputCapture(d.handleKey)
+}
+
+// newTable creates a bordered, row-selectable table
+func newTable(title string) *tview.Table {
+	table := tview.NewTable().SetSelectable(true, false).SetFixed(1, 0)
+	table.SetBorder(true).SetTitle(" " + title + " ")
+	return table
+}
+
+// Run shows the dashboard until the user quits. Output that KubeClient methods
+// print to stdout and stderr is captured into the status line so it cannot
+// corrupt the screen.
+func (d *Dashboard) Run() error {
+	restore, err := d.captureOutput()
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer d.stopLogs()
+
+	go d.refreshLoop(ctx)
+	return d.app.Run()
+}
+
+// captureOutput redirects os.Stdout, os.Stderr and the standard logger into the status line
+func (d *Dashboard) captureOutput() (func(), error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture output: %w", err)
+	}
+
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = writer, writer
+	log.SetOutput(writer)
+
+	go func() {
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line != "" {
+				d.app.QueueUpdateDraw(func() { d.setMessage(line) })
+			}
+		}
+	}()
+
+	return func() {
+		os.Stdout, os.Stderr = stdout, stderr
+		log.SetOutput(stderr)
+		writer.Close()
+	}, nil
+}
+
+// refreshLoop periodically reads the cache and redraws the panes
+func (d *Dashboard) refreshLoop(ctx context.Context) {
+	ticker := time.NewTicker(d.config.Refresh)
+	defer ticker.Stop()
+
+	for {
+		data := d.read()
+		d.app.QueueUpdateDraw(func() { d.render(data) })
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// read takes a snapshot of the namespace from the cache
+func (d *Dashboard) read() snapshot {
+	data := snapshot{synced: d.cache.HasSynced()}
+
+	if data.deployments, data.err = d.cache.ListDeployments(d.config.LabelSelector); data.err != nil {
+		return data
+	}
+	if data.pods, data.err = d.cache.ListPods(""); data.err != nil {
+		return data
+	}
+	data.events, data.err = d.cache.ListEvents(kube.EventFilter{
+		Types: []string{corev1.EventTypeWarning},
+		Since: d.config.EventWindow,
+	})
+	return data
+}
+
+// render redraws every pane from the latest snapshot
+func (d *Dashboard) render(data snapshot) {
+	d.data = data
+	d.refreshed = time.Now()
+	if data.err != nil {
+		d.message = "[red]" + tview.Escape(data.err.Error())
+	}
+
+	// Keep the drilled-in workload current, or drop it if it was deleted
+	if d.workload != nil {
+		var current *appsv1.Deployment
+		for i := range data.deployments {
+			if data.deployments[i].Name == d.workload.Name {
+				current = &data.deployments[i]
+			}
+		}
+		d.workload = current
+	}
+
+	d.renderDeployments()
+	d.renderPods()
+	d.renderEvents()
+	d.renderStatus()
+}
+
+// renderDeployments fills the deployments table
+func (d *Dashboard) renderDeployments() {
+	selected := selectedName(d.deployments, len(d.deployRows), func(i int) string { return d.deployRows[i].Name })
+
+	d.deployRows = d.data.deployments
+	d.deployments.Clear()
+	setHeader(d.deployments, "NAME", "READY", "UP-TO-DATE", "AVAILABLE", "AGE")
+	for i, deployment := range d.deployRows {
+		desired := int32(1)
+		if deployment.Spec.Replicas != nil {
+			desired = *deployment.Spec.Replicas
+		}
+		color := tcell.ColorGreen
+		if deployment.Status.ReadyReplicas < desired {
+			color = tcell.ColorYellow
+		}
+		if deployment.Status.ReadyReplicas == 0 && desired > 0 {
+			color = tcell.ColorRed
+		}
+
+		name := deployment.Name
+		if d.workload != nil && d.workload.Name == deployment.Name {
+			name = "▶ " + name
+		}
+		row := i + 1
+		d.deployments.SetCell(row, 0, tview.NewTableCell(name).SetTextColor(color))
+		d.deployments.SetCell(row, 1, tview.NewTableCell(fmt.Sprintf("%d/%d", deployment.Status.ReadyReplicas, desired)).SetTextColor(color))
+		d.deployments.SetCell(row, 2, tview.NewTableCell(fmt.Sprint(deployment.Status.UpdatedReplicas)))
+		d.deployments.SetCell(row, 3, tview.NewTableCell(fmt.Sprint(deployment.Status.AvailableReplicas)))
+		d.deployments.SetCell(row, 4, tview.NewTableCell(kube.FormatAge(deployment.CreationTimestamp.Time)))
+	}
+
+	restoreSelection(d.deployments, len(d.deployRows), selected, func(i int) string { return d.deployRows[i].Name })
+}
+
+// renderPods fills the pods table, limited to the drilled-in workload if any
+func (d *Dashboard) renderPods() {
+	selected := selectedName(d.pods, len(d.podRows), func(i int) string { return d.podRows[i].Name })
+
+	d.podRows = d.podRows[:0]
+	selector := labels.Everything()
+	if d.workload != nil {
+		if workloadSelector, err := metav1.LabelSelectorAsSelector(d.workload.Spec.Selector); err == nil {
+			selector = workloadSelector
+		}
+	} else if d.config.LabelSelector != "" {
+		if configSelector, err := labels.Parse(d.config.LabelSelector); err == nil {
+			selector = configSelector
+		}
+	}
+	for _, pod := range d.data.pods {
+		if selector.Matches(labels.Set(pod.Labels)) {
+			d.podRows = append(d.podRows, pod)
+		}
+	}
+
+	title := " Pods "
+	if d.workload != nil {
+		title = fmt.Sprintf(" Pods of %s ", d.workload.Name)
+	}
+	d.pods.SetTitle(title)
+
+	d.pods.Clear()
+	setHeader(d.pods, "NAME", "STATUS", "READY", "RESTARTS", "NODE", "AGE")
+	for i := range d.podRows {
+		pod := &d.podRows[i]
+		diagnosis := kube.DiagnosePod(pod)
+
+		ready := 0
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Ready {
+				ready++
+			}
+		}
+
+		color := tcell.ColorRed
+		switch {
+		case diagnosis.Healthy():
+			color = tcell.ColorGreen
+		case diagnosis.Health == kube.PodHealthPending || diagnosis.Health == kube.PodHealthTerminating:
+			color = tcell.ColorYellow
+		}
+
+		name := pod.Name
+		if pod.Name == d.logPod {
+			name = "≡ " + name
+		}
+		row := i + 1
+		d.pods.SetCell(row, 0, tview.NewTableCell(name))
+		d.pods.SetCell(row, 1, tview.NewTableCell(diagnosis.String()).SetTextColor(color))
+		d.pods.SetCell(row, 2, tview.NewTableCell(fmt.Sprintf("%d/%d", ready, len(pod.Spec.Containers))))
+		d.pods.SetCell(row, 3, tview.NewTableCell(fmt.Sprint(diagnosis.Restarts)))
+		d.pods.SetCell(row, 4, tview.NewTableCell(pod.Spec.NodeName))
+		d.pods.SetCell(row, 5, tview.NewTableCell(kube.FormatAge(pod.CreationTimestamp.Time)))
+	}
+
+	restoreSelection(d.pods, len(d.podRows), selected, func(i int) string { return d.podRows[i].Name })
+}
+
+// renderEvents fills the events table, newest first. When drilled in, only events
+// for the workload and objects named after it (ReplicaSets, Pods) are shown.
+func (d *Dashboard) renderEvents() {
+	d.events.Clear()
+	setHeader(d.events, "LAST SEEN", "REASON", "OBJECT", "MESSAGE")
+
+	row := 1
+	for i := len(d.data.events) - 1; i >= 0; i-- {
+		event := &d.data.events[i]
+		name := event.InvolvedObject.Name
+		if d.workload != nil && name != d.workload.Name && !strings.HasPrefix(name, d.workload.Name+"-") {
+			continue
+		}
+		d.events.SetCell(row, 0, tview.NewTableCell(kube.FormatAge(kube.EventLastSeen(event))))
+		d.events.SetCell(row, 1, tview.NewTableCell(event.Reason).SetTextColor(tcell.ColorYellow))
+		d.events.SetCell(row, 2, tview.NewTableCell(strings.ToLower(event.InvolvedObject.Kind)+"/"+name))
+		d.events.SetCell(row, 3, tview.NewTableCell(tview.Escape(strings.TrimSpace(event.Message))).SetExpansion(1))
+		row++
+	}
+}
+
+// renderStatus shows the namespace, cache state and the latest message
+func (d *Dashboard) renderStatus() {
+	namespace := d.config.Namespace
+	if namespace == "" {
+		namespace = "all namespaces"
+	}
+	sync := "[green]synced[-]"
+	if !d.data.synced {
+		sync = "[yellow]syncing[-]"
+	}
+	text := fmt.Sprintf(" [::b]golkube[::-] %s  %s  %s", tview.Escape(namespace), sync, d.refreshed.Format("15:04:05"))
+	if d.workload != nil {
+		text += "  workload: [::b]" + tview.Escape(d.workload.Name) + "[::-]"
+	}
+	if d.message != "" {
+		text += "  │ " + d.message
+	}
+	d.status.SetText(text)
+}
+
+// setMessage shows a message in the status line until the next one
+func (d *Dashboard) setMessage(message string) {
+	d.message = tview.Escape(message)
+	d.renderStatus()
+}
+
+// handleKey implements the global key bindings
+func (d *Dashboard) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	// Leave keys alone while a dialog is open
+	if name, _ := d.pages.GetFrontPage(); name != "main" {
+		return event
+	}
+
+	switch event.Key() {
+	case tcell.KeyTab:
+		d.cycleFocus(1)
+		return nil
+	case tcell.KeyBacktab:
+		d.cycleFocus(-1)
+		return nil
+	case tcell.KeyEscape:
+		d.drillOut()
+		return nil
+	}
+
+	switch event.Rune() {
+	case 'q':
+		d.app.Stop()
+		return nil
+	case 'l':
+		if row, _ := d.pods.GetSelection(); d.app.GetFocus() == d.pods {
+			d.followLogs(row)
+		}
+		return nil
+	case 'f':
+		d.logs.ScrollToEnd()
+		return nil
+	case 'r':
+		d.confirmRestart()
+		return nil
+	case 's':
+		d.promptScale()
+		return nil
+	}
+	return event
+}
+
+// cycleFocus moves focus to the next or previous pane
+func (d *Dashboard) cycleFocus(step int) {
+	current := d.app.GetFocus()
+	for i, pane := range d.panes {
+		if pane == current {
+			next := (i + step + len(d.panes)) % len(d.panes)
+			d.app.SetFocus(d.panes[next])
+			return
+		}
+	}
+	d.app.SetFocus(d.panes[0])
+}
+
+// drillIn limits the pods and events panes to the deployment in the given row
+func (d *Dashboard) drillIn(row int) {
+	if row < 1 || row > len(d.deployRows) {
+		return
+	}
+	deployment := d.deployRows[row-1]
+	d.workload = &deployment
+	d.render(d.data)
+	d.pods.Select(1, 0)
+	d.app.SetFocus(d.pods)
+}
+
+// drillOut returns to the namespace-wide view
+func (d *Dashboard) drillOut() {
+	if d.workload == nil {
+		return
+	}
+	d.workload = nil
+	d.render(d.data)
+	d.app.SetFocus(d.deployments)
+}
+
+// setHeader writes a bold, unselectable header row
+func setHeader(table *tview.Table, columns ...string) {
+	for i, column := range columns {
+		table.SetCell(0, i, tview.NewTableCell(column).
+			SetTextColor(tcell.ColorYellow).
+			SetAttributes(tcell.AttrBold).
+			SetSelectable(false))
+	}
+}
+
+// selectedName returns the name behind the selected table row
+func selectedName(table *tview.Table, rows int, name func(int) string) string {
+	row, _ := table.GetSelection()
+	if row < 1 || row > rows {
+		return ""
+	}
+	return name(row - 1)
+}
+
+// restoreSelection keeps the same object selected across redraws
+func restoreSelection(table *tview.Table, rows int, selected string, name func(int) string) {
+	for i := 0; i < rows; i++ {
+		if name(i) == selected {
+			table.Select(i+1, 0)
+			return
+		}
+	}
+	if row, _ := table.GetSelection(); row < 1 || row > rows {
+		table.Select(1, 0)
+	}
+}
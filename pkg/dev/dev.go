@@ -0,0 +1,283 @@
+package dev
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golkube/pkg/docker"
+	"golkube/pkg/kube"
+)
+
+// Defaults for settings left empty in the configuration
+const (
+	DefaultDebounce       = 500 * time.Millisecond
+	DefaultRolloutTimeout = 5 * time.Minute
+)
+
+// Config mirrors the "dev" block of the configuration file
+type Config struct {
+	// Deployment is the Deployment whose image is replaced on every rebuild
+	Deployment string `mapstructure:"deployment"`
+	// Container selects the container to update; empty means the first one
+	Container string `mapstructure:"container"`
+	// Image is the repository images are tagged with; the tag is the build context hash
+	Image      string `mapstructure:"image"`
+	Context    string `mapstructure:"context"`
+	Dockerfile string `mapstructure:"dockerfile"`
+	// Push pushes each image to the registry, for clusters that cannot use local images
+	Push bool `mapstructure:"push"`
+	// RegistryURL is the registry pushed images go to, taken from registry.url
+	RegistryURL    string        `mapstructure:"-"`
+	Debounce       time.Duration `mapstructure:"debounce"`
+	RolloutTimeout time.Duration `mapstructure:"rollout_timeout"`
+	// Ignore lists file patterns that never trigger a rebuild or sync
+	Ignore []string `mapstructure:"ignore"`
+	// Sync copies changes under these directories into the running containers instead
+	// of rebuilding, for interpreted languages that reload their sources
+	Sync []SyncRule `mapstructure:"sync"`
+	// Logs streams the logs of the new pods after each rollout
+	Logs bool `mapstructure:"logs"`
+}
+
+// SyncRule maps a directory of the build context to a directory in the container
+type SyncRule struct {
+	Src  string `mapstructure:"src"`
+	Dest string `mapstructure:"dest"`
+}
+
+// Loop rebuilds and redeploys an image whenever its build context changes
+type Loop struct {
+	kc        *kube.KubeClient
+	config    Config
+	namespace string
+
+	lastHash   string
+	stopLogs   context.CancelFunc
+	logStreams sync.WaitGroup
+}
+
+// NewLoop checks the configuration and fills in defaults
+func NewLoop(kc *kube.KubeClient, config Config, namespace string) (*Loop, error) {
+	if config.Deployment == "" {
+		return nil, fmt.Errorf("no deployment to update; set dev.deployment or pass --deployment")
+	}
+	if config.Context == "" {
+		config.Context = "."
+	}
+	if config.Dockerfile == "" {
+		config.Dockerfile = "Dockerfile"
+	}
+	if config.Image == "" {
+		config.Image = config.Deployment
+	}
+	if strings.Contains(filepath.Base(config.Image), ":") {
+		return nil, fmt.Errorf("image %s must not have a tag; dev tags each build with its content hash", config.Image)
+	}
+	if config.Debounce <= 0 {
+		config.Debounce = DefaultDebounce
+	}
+	if config.RolloutTimeout <= 0 {
+		config.RolloutTimeout = DefaultRolloutTimeout
+	}
+	if info, err := os.Stat(config.Context); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("build context %s is not a directory", config.Context)
+	}
+	for i, rule := range config.Sync {
+		if rule.Src == "" || !strings.HasPrefix(rule.Dest, "/") {
+			return nil, fmt.Errorf("sync rule %d needs a src directory and an absolute dest", i+1)
+		}
+		config.Sync[i].Src = filepath.Clean(rule.Src)
+	}
+	return &Loop{kc: kc, config: config, namespace: namespace}, nil
+}
+
+// Run builds and deploys the current sources, then watches the build context until ctx
+// is cancelled. Failed builds and rollouts are reported and the loop keeps watching.
+func (l *Loop) Run(ctx context.Context) error {
+	changes, err := watchTree(ctx, l.config.Context, l.config.Ignore, l.config.Debounce)
+	if err != nil {
+		return err
+	}
+	defer l.stopLogStreams()
+
+	l.rebuild(ctx)
+	fmt.Printf("Watching %s for changes (Ctrl+C to stop)\n", l.config.Context)
+	for batch := range changes {
+		fmt.Printf("\n%d files changed: %s\n", len(batch), summarize(batch))
+		if l.syncable(batch) {
+			err := l.sync(ctx, batch)
+			if err == nil {
+				continue
+			}
+			fmt.Printf("✗ Sync failed, rebuilding instead: %v\n", err)
+		}
+		l.rebuild(ctx)
+	}
+	return nil
+}
+
+// rebuild builds an image tagged with the build context hash and rolls it out
+func (l *Loop) rebuild(ctx context.Context) {
+	if err := l.deploy(ctx); err != nil {
+		if ctx.Err() == nil {
+			fmt.Printf("✗ %v\n", err)
+		}
+	}
+}
+
+// deploy builds, rolls out and starts streaming logs of the new pods
+func (l *Loop) deploy(ctx context.Context) error {
+	hash, err := docker.ContextHash(l.config.Context, l.config.Dockerfile)
+	if err != nil {
+		return err
+	}
+	if hash == l.lastHash {
+		fmt.Println("Build context unchanged; nothing to deploy.")
+		return nil
+	}
+
+	tag := fmt.Sprintf("%s:%s", l.config.Image, hash[:12])
+	started := time.Now()
+	err = docker.BuildImage(docker.BuildConfig{
+		Tag:         tag,
+		ContextDir:  l.config.Context,
+		Dockerfile:  l.config.Dockerfile,
+		Push:        l.config.Push,
+		RegistryURL: l.config.RegistryURL,
+	})
+	if err != nil {
+		return fmt.Errorf("build failed: %w", err)
+	}
+	image := tag
+	if l.config.Push {
+		image = docker.PushTag(tag)
+	}
+
+	l.stopLogStreams()
+	if err := l.kc.SetDeploymentImage(l.config.Deployment, l.namespace, l.config.Container, image); err != nil {
+		return err
+	}
+	fmt.Printf("Waiting for deployment %s to roll out...\n", l.config.Deployment)
+	if err := l.kc.WaitForRollout(ctx, l.config.Deployment, l.namespace, l.config.RolloutTimeout); err != nil {
+		return err
+	}
+	l.lastHash = hash
+	fmt.Printf("✓ Deployed %s in %s\n", image, time.Since(started).Round(100*time.Millisecond))
+
+	if l.config.Logs {
+		l.streamLogs(ctx)
+	}
+	return nil
+}
+
+// syncable reports whether every changed file lies under a sync rule's source directory.
+// Nothing is synced until an image built by this loop is running.
+func (l *Loop) syncable(batch []string) bool {
+	if len(l.config.Sync) == 0 || l.lastHash == "" {
+		return false
+	}
+	for _, path := range batch {
+		if _, _, ok := l.syncRule(path); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// syncRule returns the rule covering a changed path and the path relative to its source
+func (l *Loop) syncRule(path string) (SyncRule, string, bool) {
+	for _, rule := range l.config.Sync {
+		rel, err := filepath.Rel(rule.Src, path)
+		if err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			return rule, rel, true
+		}
+	}
+	return SyncRule{}, "", false
+}
+
+// sync copies changed files into the container of every pod of the current rollout
+func (l *Loop) sync(ctx context.Context, batch []string) error {
+	pods, err := l.kc.RolloutPods(l.config.Deployment, l.namespace)
+	if err != nil {
+		return err
+	}
+	if len(pods) == 0 {
+		return fmt.Errorf("deployment %s has no running pods", l.config.Deployment)
+	}
+
+	byRule := make(map[SyncRule][]string)
+	for _, path := range batch {
+		rule, rel, _ := l.syncRule(path)
+		byRule[rule] = append(byRule[rule], rel)
+	}
+	for _, pod := range pods {
+		container := l.config.Container
+		if container == "" {
+			container = pod.Spec.Containers[0].Name
+		}
+		for rule, paths := range byRule {
+			localDir := filepath.Join(l.config.Context, rule.Src)
+			if err := l.kc.SyncFilesToPod(ctx, pod.Name, l.namespace, container, localDir, rule.Dest, paths); err != nil {
+				return fmt.Errorf("pod %s: %w", pod.Name, err)
+			}
+		}
+	}
+	fmt.Printf("✓ Synced %d files to %d pods\n", len(batch), len(pods))
+	return nil
+}
+
+// streamLogs follows the logs of the pods of the current rollout until the next rollout
+func (l *Loop) streamLogs(ctx context.Context) {
+	pods, err := l.kc.RolloutPods(l.config.Deployment, l.namespace)
+	if err != nil {
+		fmt.Printf("✗ Cannot stream logs: %v\n", err)
+		return
+	}
+
+	logCtx, cancel := context.WithCancel(ctx)
+	l.stopLogs = cancel
+	for _, pod := range pods {
+		container := l.config.Container
+		if container == "" {
+			container = pod.Spec.Containers[0].Name
+		}
+		stream, err := l.kc.PodLogs(logCtx, pod.Name, l.namespace, container, true, 0)
+		if err != nil {
+			fmt.Printf("✗ %v\n", err)
+			continue
+		}
+		l.logStreams.Add(1)
+		go func(name string) {
+			defer l.logStreams.Done()
+			defer stream.Close()
+			scanner := bufio.NewScanner(stream)
+			for scanner.Scan() {
+				fmt.Printf("[%s] %s\n", name, scanner.Text())
+			}
+		}(pod.Name)
+	}
+}
+
+// stopLogStreams ends the log streams of the previous rollout
+func (l *Loop) stopLogStreams() {
+	if l.stopLogs != nil {
+		l.stopLogs()
+		l.stopLogs = nil
+	}
+	l.logStreams.Wait()
+}
+
+// summarize lists the first few changed paths
+func summarize(paths []string) string {
+	const shown = 3
+	if len(paths) <= shown {
+		return strings.Join(paths, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(paths[:shown], ", "), len(paths)-shown)
+}
@@ -0,0 +1,163 @@
+package dev
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// treeWatcher watches a directory tree and reports changed files in debounced batches
+type treeWatcher struct {
+	root     string
+	ignore   []string
+	debounce time.Duration
+	watcher  *fsnotify.Watcher
+}
+
+// watchTree starts watching root recursively. Each batch sent on the returned channel
+// holds the paths, relative to root, that changed during a burst of changes, sent once
+// no further change has been seen for debounce. The channel closes when ctx is done.
+func watchTree(ctx context.Context, root string, ignore []string, debounce time.Duration) (<-chan []string, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	tw := &treeWatcher{root: root, ignore: ignore, debounce: debounce, watcher: watcher}
+	if err := tw.addTree(root); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	batches := make(chan []string)
+	go tw.run(ctx, batches)
+	return batches, nil
+}
+
+// addTree watches dir and every directory below it that is not ignored
+func (tw *treeWatcher) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if path != tw.root && tw.ignored(path) {
+			return filepath.SkipDir
+		}
+		if err := tw.watcher.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// run collects events into batches until ctx is done
+func (tw *treeWatcher) run(ctx context.Context, batches chan<- []string) {
+	defer close(batches)
+	defer tw.watcher.Close()
+
+	pending := make(map[string]bool)
+	timer := time.NewTimer(tw.debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-tw.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op == fsnotify.Chmod || tw.ignored(event.Name) {
+				continue
+			}
+			if event.Op.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					// Watch the new directory and report the files it was created with
+					if err := tw.addTree(event.Name); err != nil {
+						log.Printf("Warning: %v", err)
+					}
+					tw.addFiles(event.Name, pending)
+					timer.Reset(tw.debounce)
+					continue
+				}
+			}
+			if rel, err := filepath.Rel(tw.root, event.Name); err == nil {
+				pending[rel] = true
+			}
+			timer.Reset(tw.debounce)
+		case err, ok := <-tw.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("Warning: file watcher error: %v", err)
+		case <-timer.C:
+			if len(pending) == 0 {
+				continue
+			}
+			batch := make([]string, 0, len(pending))
+			for path := range pending {
+				batch = append(batch, path)
+			}
+			sort.Strings(batch)
+			pending = make(map[string]bool)
+			select {
+			case batches <- batch:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// addFiles marks every file below dir that is not ignored as changed
+func (tw *treeWatcher) addFiles(dir string, pending map[string]bool) {
+	filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if tw.ignored(path) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.IsDir() {
+			if rel, err := filepath.Rel(tw.root, path); err == nil {
+				pending[rel] = true
+			}
+		}
+		return nil
+	})
+}
+
+// ignored reports whether a path matches an ignore pattern. Patterns are matched against
+// the path relative to the root and against each of its elements, so "node_modules" and
+// "*.pyc" apply at any depth.
+func (tw *treeWatcher) ignored(path string) bool {
+	rel, err := filepath.Rel(tw.root, path)
+	if err != nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range tw.ignore {
+		if matched, _ := filepath.Match(pattern, rel); matched {
+			return true
+		}
+		for _, element := range strings.Split(rel, "/") {
+			if matched, _ := filepath.Match(pattern, element); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
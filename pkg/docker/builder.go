@@ -4,15 +4,20 @@ import (
 	"archive/tar"
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/fileutils"
+	"github.com/docker/docker/pkg/jsonmessage"
 )
 
 // BuildConfig holds the configuration for building a Docker image
@@ -68,20 +73,23 @@ func BuildImage(config BuildConfig) error {
 	}
 	defer resp.Body.Close()
 
-	if _, err := io.Copy(os.Stdout, resp.Body); err != nil {
-		return fmt.Errorf("failed to display build output: %w", err)
+	// The daemon reports build failures inside the output stream rather than as an error response
+	if err := jsonmessage.DisplayJSONMessagesStream(resp.Body, os.Stdout, 0, false, nil); err != nil {
+		return fmt.Errorf("image build failed: %w", err)
 	}
 
 	fmt.Printf("Image %s built successfully\n", config.Tag)
 
 	// Push the image if required
 	if config.Push {
-		dockerHubTag := fmt.Sprintf("%s/%s", os.Getenv("DOCKER_USERNAME"), config.Tag)
-		if err := TagImage(cli, config.Tag, dockerHubTag); err != nil {
-			return fmt.Errorf("failed to tag image: %w", err)
+		pushTag := PushTag(config.Tag)
+		if pushTag != config.Tag {
+			if err := TagImage(cli, config.Tag, pushTag); err != nil {
+				return fmt.Errorf("failed to tag image: %w", err)
+			}
 		}
 
-		if err := PushImage(cli, dockerHubTag, config.RegistryURL); err != nil {
+		if err := PushImage(cli, pushTag, config.RegistryURL); err != nil {
 			return fmt.Errorf("failed to push image: %w", err)
 		}
 	}
@@ -89,6 +97,16 @@ func BuildImage(config BuildConfig) error {
 	return nil
 }
 
+// PushTag returns the tag an image is pushed under. Names that already carry a registry
+// or namespace, such as ghcr.io/org/app or org/app, are pushed as they are; bare names go
+// to the Docker Hub account of DOCKER_USERNAME.
+func PushTag(tag string) string {
+	if strings.Contains(tag, "/") {
+		return tag
+	}
+	return fmt.Sprintf("%s/%s", os.Getenv("DOCKER_USERNAME"), tag)
+}
+
 // createBuildContext creates a tarball of the Docker build context
 func createBuildContext(contextDir, dockerfile string) (*bytes.Buffer, error) {
 	buf := new(bytes.Buffer)
@@ -146,6 +164,88 @@ func createBuildContext(contextDir, dockerfile string) (*bytes.Buffer, error) {
 	return buf, nil
 }
 
+// ContextHash returns a SHA-256 digest of the build context, covering the Dockerfile and
+// the path, mode and content of every file, so unchanged sources produce the same hash.
+// The .git directory and paths excluded by .dockerignore are left out, as they never
+// reach the image.
+func ContextHash(contextDir, dockerfile string) (string, error) {
+	ignored, err := dockerignoreMatcher(contextDir)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "dockerfile %s\n", dockerfile)
+	err = filepath.Walk(contextDir, func(file string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(contextDir, file)
+		if err != nil {
+			return err
+		}
+		if relPath != "." && relPath != filepath.Clean(dockerfile) && relPath != ".dockerignore" {
+			skip := relPath == ".git"
+			if !skip {
+				if skip, err = ignored.Matches(relPath); err != nil {
+					return err
+				}
+			}
+			// Exclusion patterns may re-include files below an ignored directory
+			if skip && fi.IsDir() && (relPath == ".git" || !ignored.Exclusions()) {
+				return filepath.SkipDir
+			}
+			if skip {
+				return nil
+			}
+		}
+		fmt.Fprintf(hash, "%s %s\n", filepath.ToSlash(relPath), fi.Mode())
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(hash, f)
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash build context: %w", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// dockerignoreMatcher reads the patterns of the context's .dockerignore file, if any
+func dockerignoreMatcher(contextDir string) (*fileutils.PatternMatcher, error) {
+	data, err := os.ReadFile(filepath.Join(contextDir, ".dockerignore"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read .dockerignore: %w", err)
+	}
+
+	var patterns []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// Patterns are relative to the context root, with or without a leading slash
+		if strings.HasPrefix(line, "!") {
+			line = "!" + strings.TrimPrefix(line[1:], "/")
+		} else {
+			line = strings.TrimPrefix(line, "/")
+		}
+		patterns = append(patterns, line)
+	}
+
+	matcher, err := fileutils.NewPatternMatcher(patterns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid .dockerignore pattern: %w", err)
+	}
+	return matcher, nil
+}
+
 // TagImage tags a Docker image
 func TagImage(cli *client.Client, sourceTag, targetTag string) error {
 	fmt.Printf("Tagging image %s as %s...\n", sourceTag, targetTag)
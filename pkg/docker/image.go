@@ -0,0 +1,49 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// InspectImage returns the metadata of a local image: its exposed ports, env, entrypoint,
+// cmd, user, healthcheck and labels. When pull is set, an image missing locally is pulled
+// first, with progress written to stderr.
+func InspectImage(ref string, pull bool) (types.ImageInspect, error) {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return types.ImageInspect{}, fmt.Errorf("failed to create Docker client: %w", err)
+	}
+	defer cli.Close()
+
+	inspect, _, err := cli.ImageInspectWithRaw(context.Background(), ref)
+	if client.IsErrNotFound(err) && pull {
+		if err := pullImage(cli, ref); err != nil {
+			return types.ImageInspect{}, err
+		}
+		inspect, _, err = cli.ImageInspectWithRaw(context.Background(), ref)
+	}
+	if err != nil {
+		return types.ImageInspect{}, fmt.Errorf("failed to inspect image %s: %w", ref, err)
+	}
+	return inspect, nil
+}
+
+// pullImage pulls an image from its registry
+func pullImage(cli *client.Client, ref string) error {
+	fmt.Fprintf(os.Stderr, "Pulling image %s...\n", ref)
+	resp, err := cli.ImagePull(context.Background(), ref, types.ImagePullOptions{})
+	if err != nil {
+		return fmt.Errorf("image pull failed: %w", err)
+	}
+	defer resp.Close()
+
+	if err := jsonmessage.DisplayJSONMessagesStream(resp, os.Stderr, 0, false, nil); err != nil {
+		return fmt.Errorf("image pull failed: %w", err)
+	}
+	return nil
+}
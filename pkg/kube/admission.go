@@ -0,0 +1,34 @@
+package kube
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Admitter checks objects before golkube creates or updates them, so that policy is
+// enforced on every apply and deploy path
+type Admitter interface {
+	// Admit returns an error if the object must not be applied to the namespace
+	Admit(obj *unstructured.Unstructured, namespace string) error
+}
+
+// admit runs the configured Admitter, if any, on an unstructured object
+func (kc *KubeClient) admit(obj *unstructured.Unstructured, namespace string) error {
+	if kc.Admission == nil {
+		return nil
+	}
+	return kc.Admission.Admit(obj, namespace)
+}
+
+// admitTyped runs the configured Admitter, if any, on a typed object
+func (kc *KubeClient) admitTyped(obj runtime.Object, gvk schema.GroupVersionKind, namespace string) error {
+	if kc.Admission == nil {
+		return nil
+	}
+	u, err := ToUnstructured(obj, gvk)
+	if err != nil {
+		return err
+	}
+	return kc.Admission.Admit(u, namespace)
+}
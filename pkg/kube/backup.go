@@ -0,0 +1,375 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/restmapper"
+	"sigs.k8s.io/yaml"
+)
+
+// skippedBackupResources hold runtime state rather than configuration and are not exported
+var skippedBackupResources = sets.New(
+	"events",
+	"events.events.k8s.io",
+	"leases.coordination.k8s.io",
+	"endpointslices.discovery.k8s.io",
+)
+
+// restoreOrder ranks kinds so dependencies are created before the objects that use them.
+// Kinds not listed (including custom resources) are restored last.
+var restoreOrder = map[string]int{
+	"Namespace":               0,
+	"ResourceQuota":           1,
+	"LimitRange":              1,
+	"ServiceAccount":          2,
+	"Secret":                  3,
+	"ConfigMap":               3,
+	"PersistentVolumeClaim":   4,
+	"Role":                    5,
+	"RoleBinding":             6,
+	"Service":                 7,
+	"Endpoints":               7,
+	"Deployment":              8,
+	"StatefulSet":             8,
+	"DaemonSet":               8,
+	"ReplicaSet":              8,
+	"ReplicationController":   8,
+	"Job":                     8,
+	"CronJob":                 8,
+	"Pod":                     8,
+	"HorizontalPodAutoscaler": 9,
+	"PodDisruptionBudget":     9,
+	"Ingress":                 9,
+	"NetworkPolicy":           9,
+}
+
+// restoreOrderDefault is used for kinds without an entry in restoreOrder
+const restoreOrderDefault = 10
+
+// RestoreResult summarises a restore
+type RestoreResult struct {
+	Created int
+	Skipped int
+	Failed  int
+}
+
+// BackupNamespace exports every namespaced resource to outDir, one YAML file per object.
+// Objects created by controllers (those with a controlling owner), service account
+// tokens and other cluster-generated objects are left out so a restore recreates them.
+func (kc *KubeClient) BackupNamespace(namespace, outDir string) (int, error) {
+	ctx := context.TODO()
+
+	namespaceObj, err := kc.Clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get namespace %s: %w", namespace, err)
+	}
+
+	resources, err := kc.Clientset.Discovery().ServerPreferredNamespacedResources()
+	if err != nil {
+		// Unavailable aggregated APIs should not prevent backing up everything else
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			return 0, fmt.Errorf("failed to discover resources: %w", err)
+		}
+		fmt.Printf("Warning: some API groups could not be discovered: %v\n", err)
+	}
+
+	var objects []*unstructured.Unstructured
+	for _, list := range resources {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range list.APIResources {
+			gvr := gv.WithResource(resource.Name)
+			if strings.Contains(resource.Name, "/") || skippedBackupResources.Has(gvr.GroupResource().String()) {
+				continue
+			}
+			verbs := sets.New(resource.Verbs...)
+			if !verbs.HasAll("list", "create") {
+				continue
+			}
+
+			items, err := kc.listAll(ctx, gvr, namespace)
+			if err != nil {
+				return 0, err
+			}
+			objects = append(objects, items...)
+		}
+	}
+
+	// Endpoints are only kept for Services without a selector; the others are generated
+	selectorless := sets.New[string]()
+	for _, obj := range objects {
+		if obj.GetKind() == "Service" {
+			if selector, _, _ := unstructured.NestedMap(obj.Object, "spec", "selector"); len(selector) == 0 {
+				selectorless.Insert(obj.GetName())
+			}
+		}
+	}
+
+	u, err := ToUnstructured(namespaceObj, schema.GroupVersionKind{Version: "v1", Kind: "Namespace"})
+	if err != nil {
+		return 0, err
+	}
+	unstructured.RemoveNestedField(u.Object, "spec", "finalizers")
+	StripServerFields(u)
+	if _, err := WriteManifest(outDir, u); err != nil {
+		return 0, err
+	}
+	written := 1
+
+	for _, obj := range objects {
+		if skipBackupObject(obj, selectorless) {
+			continue
+		}
+		StripServerFields(obj)
+		stripForRestore(obj)
+		if _, err := WriteManifest(outDir, obj); err != nil {
+			return written, err
+		}
+		written++
+	}
+
+	fmt.Printf("Backed up %d objects from namespace %s to %s\n", written, namespace, outDir)
+	return written, nil
+}
+
+// listAll lists every object of a resource in a namespace, following continue tokens
+func (kc *KubeClient) listAll(ctx context.Context, gvr schema.GroupVersionResource, namespace string) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	options := metav1.ListOptions{Limit: 500}
+	for {
+		list, err := kc.DynamicClient.Resource(gvr).Namespace(namespace).List(ctx, options)
+		if err != nil {
+			if k8sErrors.IsForbidden(err) || k8sErrors.IsNotFound(err) || k8sErrors.IsMethodNotSupported(err) {
+				fmt.Printf("Warning: skipping %s: %v\n", gvr.GroupResource(), err)
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to list %s: %w", gvr.GroupResource(), err)
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+		if list.GetContinue() == "" {
+			return objects, nil
+		}
+		options.Continue = list.GetContinue()
+	}
+}
+
+// skipBackupObject reports whether an object is generated by the cluster or a controller
+func skipBackupObject(obj *unstructured.Unstructured, selectorlessServices sets.Set[string]) bool {
+	if owner := metav1.GetControllerOfNoCopy(obj); owner != nil {
+		return true
+	}
+
+	switch obj.GetKind() {
+	case "Secret":
+		secretType, _, _ := unstructured.NestedString(obj.Object, "type")
+		return secretType == "kubernetes.io/service-account-token"
+	case "ConfigMap":
+		return ignoredConfigMaps[obj.GetName()]
+	case "Endpoints":
+		return !selectorlessServices.Has(obj.GetName())
+	}
+	return false
+}
+
+// stripForRestore removes fields that are allocated per object or per cluster
+// and would make re-creating the object fail or conflict
+func stripForRestore(obj *unstructured.Unstructured) {
+	annotations := obj.GetAnnotations()
+	for key := range annotations {
+		if strings.HasPrefix(key, "pv.kubernetes.io/") ||
+			strings.HasPrefix(key, "volume.kubernetes.io/") ||
+			strings.HasPrefix(key, "volume.beta.kubernetes.io/") ||
+			key == "deployment.kubernetes.io/revision" {
+			delete(annotations, key)
+		}
+	}
+	if len(annotations) == 0 {
+		unstructured.RemoveNestedField(obj.Object, "metadata", "annotations")
+	} else {
+		obj.SetAnnotations(annotations)
+	}
+
+	switch obj.GetKind() {
+	case "Service":
+		// Headless Services keep clusterIP None, which is part of their definition
+		if clusterIP, _, _ := unstructured.NestedString(obj.Object, "spec", "clusterIP"); clusterIP != corev1.ClusterIPNone {
+			unstructured.RemoveNestedField(obj.Object, "spec", "clusterIP")
+			unstructured.RemoveNestedField(obj.Object, "spec", "clusterIPs")
+		}
+		// Node ports are cluster-wide and would collide when cloning a namespace
+		if ports, ok, _ := unstructured.NestedSlice(obj.Object, "spec", "ports"); ok {
+			for _, port := range ports {
+				if p, ok := port.(map[string]interface{}); ok {
+					delete(p, "nodePort")
+				}
+			}
+			_ = unstructured.SetNestedSlice(obj.Object, ports, "spec", "ports")
+		}
+	case "PersistentVolumeClaim":
+		unstructured.RemoveNestedField(obj.Object, "spec", "volumeName")
+	case "Pod":
+		unstructured.RemoveNestedField(obj.Object, "spec", "nodeName")
+	case "Job":
+		// The selector and its labels are generated from the Job's uid
+		unstructured.RemoveNestedField(obj.Object, "spec", "selector")
+		for _, key := range []string{"controller-uid", "batch.kubernetes.io/controller-uid"} {
+			unstructured.RemoveNestedField(obj.Object, "spec", "template", "metadata", "labels", key)
+		}
+	}
+}
+
+// RestoreNamespace re-creates the objects in a backup directory in dependency order.
+// If targetNamespace is set, objects are restored into it instead of their original
+// namespace, which clones the environment. Existing objects are left untouched.
+// The objects were accepted by the cluster when backed up, so policy admission only
+// runs when enforcePolicy is set.
+func (kc *KubeClient) RestoreNamespace(dir, targetNamespace string, enforcePolicy bool) (RestoreResult, error) {
+	var result RestoreResult
+	ctx := context.TODO()
+
+	objects, err := readManifests(dir)
+	if err != nil {
+		return result, err
+	}
+	if len(objects) == 0 {
+		return result, fmt.Errorf("no manifests found in %s", dir)
+	}
+
+	sort.SliceStable(objects, func(i, j int) bool {
+		return kindOrder(objects[i].GetKind()) < kindOrder(objects[j].GetKind())
+	})
+
+	sourceNamespace := ""
+	for _, obj := range objects {
+		if obj.GetKind() == "Namespace" {
+			sourceNamespace = obj.GetName()
+		} else if sourceNamespace == "" {
+			sourceNamespace = obj.GetNamespace()
+		}
+	}
+	if targetNamespace != "" && targetNamespace != sourceNamespace {
+		for _, obj := range objects {
+			retargetNamespace(obj, sourceNamespace, targetNamespace)
+		}
+	}
+
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(kc.Clientset.Discovery()))
+	for _, obj := range objects {
+		gvk := obj.GroupVersionKind()
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			fmt.Printf("✗ %s %s: unknown kind: %v\n", gvk.Kind, obj.GetName(), err)
+			result.Failed++
+			continue
+		}
+
+		if enforcePolicy {
+			if err := kc.admit(obj, obj.GetNamespace()); err != nil {
+				fmt.Printf("✗ %s %s: %v\n", gvk.Kind, obj.GetName(), err)
+				result.Failed++
+				continue
+			}
+		}
+
+		resource := kc.DynamicClient.Resource(mapping.Resource)
+		var createErr error
+		if obj.GetNamespace() == "" {
+			_, createErr = resource.Create(ctx, obj, metav1.CreateOptions{})
+		} else {
+			_, createErr = resource.Namespace(obj.GetNamespace()).Create(ctx, obj, metav1.CreateOptions{})
+		}
+
+		switch {
+		case createErr == nil:
+			fmt.Printf("✓ %s %s created\n", gvk.Kind, obj.GetName())
+			result.Created++
+		case k8sErrors.IsAlreadyExists(createErr):
+			fmt.Printf("- %s %s already exists, skipped\n", gvk.Kind, obj.GetName())
+			result.Skipped++
+		default:
+			fmt.Printf("✗ %s %s: %v\n", gvk.Kind, obj.GetName(), createErr)
+			result.Failed++
+		}
+	}
+
+	return result, nil
+}
+
+// readManifests decodes every YAML file in a directory
+func readManifests(dir string) ([]*unstructured.Unstructured, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	var objects []*unstructured.Unstructured
+	for _, entry := range entries {
+		if entry.IsDir() || (filepath.Ext(entry.Name()) != ".yaml" && filepath.Ext(entry.Name()) != ".yml") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal(data, &obj.Object); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if obj.GetKind() == "" || obj.GetName() == "" {
+			return nil, fmt.Errorf("%s is not a Kubernetes object", path)
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+// kindOrder returns the restore rank of a kind
+func kindOrder(kind string) int {
+	if order, ok := restoreOrder[kind]; ok {
+		return order
+	}
+	return restoreOrderDefault
+}
+
+// retargetNamespace moves an object from the source to the target namespace,
+// including RoleBinding subjects that point at service accounts in the source
+func retargetNamespace(obj *unstructured.Unstructured, source, target string) {
+	if obj.GetKind() == "Namespace" {
+		obj.SetName(target)
+		return
+	}
+	obj.SetNamespace(target)
+
+	if obj.GetKind() != "RoleBinding" {
+		return
+	}
+	subjects, ok, _ := unstructured.NestedSlice(obj.Object, "subjects")
+	if !ok {
+		return
+	}
+	for _, subject := range subjects {
+		if s, ok := subject.(map[string]interface{}); ok && s["namespace"] == source {
+			s["namespace"] = target
+		}
+	}
+	_ = unstructured.SetNestedSlice(obj.Object, subjects, "subjects")
+}
@@ -0,0 +1,411 @@
+package kube
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Resources a ClusterCache can hold. Pods, Deployments and ReplicaSets are always cached;
+// Nodes and Events are only watched by caches started for them.
+const (
+	CachePods        = "pods"
+	CacheDeployments = "deployments"
+	CacheReplicaSets = "replicasets"
+	CacheNodes       = "nodes"
+	CacheEvents      = "events"
+)
+
+// CacheSyncTimeout bounds the wait for the initial list of a cached resource
+const CacheSyncTimeout = 30 * time.Second
+
+// ClusterCache is a long-running, informer-backed cache of the objects golkube
+// reads most often. Reads are served from local listers once the informers have
+// synced and fall back to the API server until then, or when the resource is not cached.
+type ClusterCache struct {
+	kc        *KubeClient
+	namespace string
+	factory   informers.SharedInformerFactory
+
+	pods        corelisters.PodLister
+	deployments appslisters.DeploymentLister
+	replicaSets appslisters.ReplicaSetLister
+	nodes       corelisters.NodeLister
+	events      corelisters.EventLister
+
+	syncedMu sync.RWMutex
+	synced   map[string]cache.InformerSynced
+
+	startMu sync.Mutex
+	stopCh  chan struct{}
+	started time.Time
+
+	hits   atomic.Int64
+	misses atomic.Int64
+
+	watchErrorMu       sync.Mutex
+	watchErrorHandlers []func(resource string, err error)
+	lastWatchErrors    map[string]error
+}
+
+// CacheMetrics reports how many reads were served from the cache
+type CacheMetrics struct {
+	Hits    int64
+	Misses  int64
+	HitRate float64
+	Uptime  time.Duration
+}
+
+// Cache returns the shared ClusterCache for a namespace, creating and starting it on first use.
+// Nodes and Events are only watched when named in resources; a cache shared with an earlier
+// caller starts any it does not watch yet. An empty namespace caches objects from all namespaces.
+func (kc *KubeClient) Cache(namespace string, resources ...string) (*ClusterCache, error) {
+	kc.cacheMu.Lock()
+	defer kc.cacheMu.Unlock()
+
+	if kc.caches == nil {
+		kc.caches = make(map[string]*ClusterCache)
+	}
+	if existing, ok := kc.caches[namespace]; ok {
+		if err := existing.Start(resources...); err != nil {
+			return nil, err
+		}
+		return existing, nil
+	}
+
+	clusterCache := kc.NewClusterCache(namespace, DefaultResyncPeriod)
+	if err := clusterCache.Start(resources...); err != nil {
+		clusterCache.Stop()
+		return nil, err
+	}
+	kc.caches[namespace] = clusterCache
+	return clusterCache, nil
+}
+
+// NewClusterCache creates an unstarted cache. Informers are registered by Start.
+func (kc *KubeClient) NewClusterCache(namespace string, resync time.Duration) *ClusterCache {
+	return &ClusterCache{
+		kc:              kc,
+		namespace:       namespace,
+		factory:         informers.NewSharedInformerFactoryWithOptions(kc.Clientset, resync, informers.WithNamespace(namespace)),
+		synced:          make(map[string]cache.InformerSynced),
+		stopCh:          make(chan struct{}),
+		lastWatchErrors: make(map[string]error),
+	}
+}
+
+// watch registers the informer for a resource with the factory, once
+func (cc *ClusterCache) watch(resource string) error {
+	cc.syncedMu.Lock()
+	defer cc.syncedMu.Unlock()
+	if _, ok := cc.synced[resource]; ok {
+		return nil
+	}
+
+	var informer cache.SharedIndexInformer
+	switch resource {
+	case CachePods:
+		podInformer := cc.factory.Core().V1().Pods()
+		cc.pods, informer = podInformer.Lister(), podInformer.Informer()
+	case CacheDeployments:
+		deploymentInformer := cc.factory.Apps().V1().Deployments()
+		cc.deployments, informer = deploymentInformer.Lister(), deploymentInformer.Informer()
+	case CacheReplicaSets:
+		replicaSetInformer := cc.factory.Apps().V1().ReplicaSets()
+		cc.replicaSets, informer = replicaSetInformer.Lister(), replicaSetInformer.Informer()
+	case CacheNodes:
+		nodeInformer := cc.factory.Core().V1().Nodes()
+		cc.nodes, informer = nodeInformer.Lister(), nodeInformer.Informer()
+	case CacheEvents:
+		eventInformer := cc.factory.Core().V1().Events()
+		cc.events, informer = eventInformer.Lister(), eventInformer.Informer()
+	default:
+		return fmt.Errorf("unknown cache resource %q", resource)
+	}
+
+	// Route watch failures to registered handlers while keeping the reflector's default handling
+	_ = informer.SetWatchErrorHandler(func(r *cache.Reflector, err error) {
+		cache.DefaultWatchErrorHandler(r, err)
+		cc.watchError(resource, err)
+	})
+	cc.synced[resource] = informer.HasSynced
+	return nil
+}
+
+// OnWatchError registers a handler that is called whenever a cached resource's watch fails
+func (cc *ClusterCache) OnWatchError(handler func(resource string, err error)) {
+	cc.watchErrorMu.Lock()
+	defer cc.watchErrorMu.Unlock()
+	cc.watchErrorHandlers = append(cc.watchErrorHandlers, handler)
+}
+
+// watchError records a watch failure and dispatches it to the registered handlers
+func (cc *ClusterCache) watchError(resource string, err error) {
+	cc.watchErrorMu.Lock()
+	cc.lastWatchErrors[resource] = err
+	handlers := append([]func(string, error){}, cc.watchErrorHandlers...)
+	cc.watchErrorMu.Unlock()
+
+	for _, handler := range handlers {
+		handler(resource, err)
+	}
+}
+
+// Start starts the informers for Pods, Deployments, ReplicaSets and the given resources,
+// and waits up to CacheSyncTimeout for their initial sync. Calling it again starts only
+// the informers that are not running yet.
+func (cc *ClusterCache) Start(resources ...string) error {
+	cc.startMu.Lock()
+	defer cc.startMu.Unlock()
+
+	for _, resource := range append([]string{CachePods, CacheDeployments, CacheReplicaSets}, resources...) {
+		if err := cc.watch(resource); err != nil {
+			return err
+		}
+	}
+	if cc.started.IsZero() {
+		cc.started = time.Now()
+	}
+	cc.factory.Start(cc.stopCh)
+
+	unsynced := cc.unsynced()
+	if len(unsynced) == 0 {
+		return nil
+	}
+	log.Printf("Waiting for cluster cache to sync %s in namespace %q", strings.Join(unsynced, ", "), cc.namespace)
+	start := time.Now()
+
+	timeout := time.NewTimer(CacheSyncTimeout)
+	defer timeout.Stop()
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for len(cc.unsynced()) > 0 {
+		select {
+		case <-timeout.C:
+			return cc.syncError()
+		case <-cc.stopCh:
+			return fmt.Errorf("cluster cache stopped before it synced")
+		case <-ticker.C:
+		}
+	}
+	log.Printf("Cluster cache synced in %s", time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// syncError explains why resources did not sync, naming the permission when a list was forbidden
+func (cc *ClusterCache) syncError() error {
+	cc.watchErrorMu.Lock()
+	defer cc.watchErrorMu.Unlock()
+
+	var problems []string
+	for _, resource := range cc.unsynced() {
+		err := cc.lastWatchErrors[resource]
+		switch {
+		case k8sErrors.IsForbidden(err):
+			problems = append(problems, fmt.Sprintf("missing permission to list and watch %s %s", resource, cc.scope(resource)))
+		case err != nil:
+			problems = append(problems, fmt.Sprintf("%s: %v", resource, err))
+		default:
+			problems = append(problems, fmt.Sprintf("%s did not sync within %s", resource, CacheSyncTimeout))
+		}
+	}
+	return fmt.Errorf("failed to sync cluster cache: %s", strings.Join(problems, "; "))
+}
+
+// scope describes where a resource is read from, for permission errors
+func (cc *ClusterCache) scope(resource string) string {
+	switch {
+	case resource == CacheNodes:
+		return "cluster-wide"
+	case cc.namespace == "":
+		return "in all namespaces"
+	default:
+		return fmt.Sprintf("in namespace %s", cc.namespace)
+	}
+}
+
+// unsynced returns the watched resources that have not completed their initial list, sorted
+func (cc *ClusterCache) unsynced() []string {
+	var resources []string
+	for resource, synced := range cc.SyncStatus() {
+		if !synced {
+			resources = append(resources, resource)
+		}
+	}
+	sort.Strings(resources)
+	return resources
+}
+
+// Stop shuts down the informers backing the cache
+func (cc *ClusterCache) Stop() {
+	select {
+	case <-cc.stopCh:
+	default:
+		close(cc.stopCh)
+	}
+	cc.factory.Shutdown()
+}
+
+// SyncStatus reports whether each cached resource has completed its initial list
+func (cc *ClusterCache) SyncStatus() map[string]bool {
+	cc.syncedMu.RLock()
+	defer cc.syncedMu.RUnlock()
+
+	status := make(map[string]bool, len(cc.synced))
+	for resource, synced := range cc.synced {
+		status[resource] = synced()
+	}
+	return status
+}
+
+// HasSynced reports whether every cached resource has synced
+func (cc *ClusterCache) HasSynced() bool {
+	return len(cc.unsynced()) == 0
+}
+
+// Metrics returns cache-hit statistics
+func (cc *ClusterCache) Metrics() CacheMetrics {
+	hits, misses := cc.hits.Load(), cc.misses.Load()
+	metrics := CacheMetrics{Hits: hits, Misses: misses}
+	if total := hits + misses; total > 0 {
+		metrics.HitRate = float64(hits) / float64(total)
+	}
+	if !cc.started.IsZero() {
+		metrics.Uptime = time.Since(cc.started)
+	}
+	return metrics
+}
+
+// served records whether a read for resource could be served from the cache. Resources
+// the cache does not watch are always read from the API server.
+func (cc *ClusterCache) served(resource string) bool {
+	cc.syncedMu.RLock()
+	synced, ok := cc.synced[resource]
+	cc.syncedMu.RUnlock()
+
+	if ok && synced() {
+		cc.hits.Add(1)
+		return true
+	}
+	cc.misses.Add(1)
+	return false
+}
+
+// ListPods lists Pods in the cached namespace matching the label selector
+func (cc *ClusterCache) ListPods(labelSelector string) ([]corev1.Pod, error) {
+	selector, err := labels.Parse(labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector: %w", err)
+	}
+	if !cc.served(CachePods) {
+		return cc.kc.ListPods(cc.namespace, labelSelector)
+	}
+
+	pods, err := cc.pods.Pods(cc.namespace).List(selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods from cache: %w", err)
+	}
+	result := make([]corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		result = append(result, *pod)
+	}
+	return result, nil
+}
+
+// ListDeployments lists Deployments in the cached namespace matching the label selector
+func (cc *ClusterCache) ListDeployments(labelSelector string) ([]appsv1.Deployment, error) {
+	selector, err := labels.Parse(labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector: %w", err)
+	}
+	if !cc.served(CacheDeployments) {
+		return cc.kc.ListDeployments(cc.namespace, labelSelector)
+	}
+
+	deployments, err := cc.deployments.Deployments(cc.namespace).List(selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments from cache: %w", err)
+	}
+	result := make([]appsv1.Deployment, 0, len(deployments))
+	for _, deployment := range deployments {
+		result = append(result, *deployment)
+	}
+	return result, nil
+}
+
+// ListReplicaSets lists ReplicaSets in the cached namespace matching the label selector
+func (cc *ClusterCache) ListReplicaSets(labelSelector string) ([]appsv1.ReplicaSet, error) {
+	selector, err := labels.Parse(labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector: %w", err)
+	}
+	if !cc.served(CacheReplicaSets) {
+		return cc.kc.ListReplicaSets(cc.namespace, labelSelector)
+	}
+
+	replicaSets, err := cc.replicaSets.ReplicaSets(cc.namespace).List(selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replicasets from cache: %w", err)
+	}
+	result := make([]appsv1.ReplicaSet, 0, len(replicaSets))
+	for _, replicaSet := range replicaSets {
+		result = append(result, *replicaSet)
+	}
+	return result, nil
+}
+
+// ListNodes lists cluster Nodes matching the label selector
+func (cc *ClusterCache) ListNodes(labelSelector string) ([]corev1.Node, error) {
+	selector, err := labels.Parse(labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector: %w", err)
+	}
+	if !cc.served(CacheNodes) {
+		return cc.kc.ListNodes(labelSelector)
+	}
+
+	nodes, err := cc.nodes.List(selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes from cache: %w", err)
+	}
+	result := make([]corev1.Node, 0, len(nodes))
+	for _, node := range nodes {
+		result = append(result, *node)
+	}
+	return result, nil
+}
+
+// ListEvents lists cached Events matching the filter, sorted from oldest to newest.
+// The filter's namespace is ignored in favour of the cache's namespace.
+func (cc *ClusterCache) ListEvents(filter EventFilter) ([]corev1.Event, error) {
+	filter.Namespace = cc.namespace
+	if !cc.served(CacheEvents) {
+		return cc.kc.ListEvents(filter)
+	}
+
+	events, err := cc.events.Events(cc.namespace).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events from cache: %w", err)
+	}
+	var result []corev1.Event
+	for _, event := range events {
+		if filter.Matches(event) {
+			result = append(result, *event)
+		}
+	}
+	SortEventsByTime(result)
+	return result, nil
+}
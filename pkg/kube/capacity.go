@@ -0,0 +1,205 @@
+package kube
+
+import (
+	"fmt"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// CapacityResources are the resources totalled by capacity reports
+var CapacityResources = []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage}
+
+// Capacity groupings
+const (
+	GroupByNamespace = "namespace"
+	GroupByWorkload  = "workload"
+	GroupByLabel     = "label"
+)
+
+// CapacityGroup totals the requests and limits of the pods sharing a namespace, workload or label value
+type CapacityGroup struct {
+	Name      string
+	Namespace string
+	Pods      int
+	Requests  corev1.ResourceList
+	Limits    corev1.ResourceList
+}
+
+// CapacityReport compares the requests and limits of running pods with node allocatable
+type CapacityReport struct {
+	Nodes       int
+	Allocatable corev1.ResourceList
+	Groups      []CapacityGroup
+	Total       CapacityGroup
+}
+
+// CapacityReport totals pod requests and limits by namespace, workload or the value of
+// labelKey, and sums the allocatable resources of the schedulable nodes. An empty
+// namespace reports on all namespaces. Pods that have finished are not counted.
+func (kc *KubeClient) CapacityReport(namespace, groupBy, labelKey string) (*CapacityReport, error) {
+	if groupBy == GroupByLabel && labelKey == "" {
+		return nil, fmt.Errorf("grouping by label requires a label key")
+	}
+	if groupBy != GroupByNamespace && groupBy != GroupByWorkload && groupBy != GroupByLabel {
+		return nil, fmt.Errorf("unknown grouping %q", groupBy)
+	}
+
+	// A report is a one-off read, so the objects are listed directly rather than through
+	// informers that would keep watching after the report is printed
+	pods, err := kc.ListPods(namespace, "")
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := kc.ListNodes("")
+	if err != nil {
+		return nil, err
+	}
+	var replicaSets []appsv1.ReplicaSet
+	if groupBy == GroupByWorkload {
+		if replicaSets, err = kc.ListReplicaSets(namespace, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &CapacityReport{
+		Allocatable: corev1.ResourceList{},
+		Total:       CapacityGroup{Name: "TOTAL", Requests: corev1.ResourceList{}, Limits: corev1.ResourceList{}},
+	}
+	for _, node := range nodes {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		report.Nodes++
+		addResources(report.Allocatable, capacityResources(node.Status.Allocatable))
+	}
+
+	deploymentOf := make(map[string]string)
+	for _, rs := range replicaSets {
+		for _, owner := range rs.OwnerReferences {
+			if owner.Controller != nil && *owner.Controller && owner.Kind == "Deployment" {
+				deploymentOf[rs.Namespace+"/"+rs.Name] = owner.Name
+			}
+		}
+	}
+
+	groups := make(map[string]*CapacityGroup)
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+
+		var name, groupNamespace string
+		switch groupBy {
+		case GroupByNamespace:
+			name = pod.Namespace
+		case GroupByWorkload:
+			name, groupNamespace = podWorkload(pod, deploymentOf), pod.Namespace
+		case GroupByLabel:
+			name = pod.Labels[labelKey]
+			if name == "" {
+				name = "<none>"
+			}
+		}
+
+		key := groupNamespace + "/" + name
+		group, ok := groups[key]
+		if !ok {
+			group = &CapacityGroup{Name: name, Namespace: groupNamespace, Requests: corev1.ResourceList{}, Limits: corev1.ResourceList{}}
+			groups[key] = group
+		}
+		requests, limits := PodReservation(pod)
+		group.Pods++
+		addResources(group.Requests, requests)
+		addResources(group.Limits, limits)
+		report.Total.Pods++
+		addResources(report.Total.Requests, requests)
+		addResources(report.Total.Limits, limits)
+	}
+
+	for _, group := range groups {
+		report.Groups = append(report.Groups, *group)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		if report.Groups[i].Namespace != report.Groups[j].Namespace {
+			return report.Groups[i].Namespace < report.Groups[j].Namespace
+		}
+		return report.Groups[i].Name < report.Groups[j].Name
+	})
+	return report, nil
+}
+
+// PodReservation returns the requests and limits a pod reserves the way the scheduler
+// counts them: the larger of its containers' sum and its largest init container, plus
+// the pod overhead. Init containers that keep running as sidecars are added to the sum.
+func PodReservation(pod *corev1.Pod) (corev1.ResourceList, corev1.ResourceList) {
+	return podResources(pod, func(r corev1.ResourceRequirements) corev1.ResourceList { return r.Requests }),
+		podResources(pod, func(r corev1.ResourceRequirements) corev1.ResourceList { return r.Limits })
+}
+
+// podResources computes the effective requests or limits of a pod, as picked by get
+func podResources(pod *corev1.Pod, get func(corev1.ResourceRequirements) corev1.ResourceList) corev1.ResourceList {
+	total := corev1.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		addResources(total, capacityResources(get(container.Resources)))
+	}
+
+	// A regular init container runs alongside the sidecars started before it
+	sidecars := corev1.ResourceList{}
+	initPeak := corev1.ResourceList{}
+	for _, container := range pod.Spec.InitContainers {
+		resources := capacityResources(get(container.Resources))
+		if container.RestartPolicy != nil && *container.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			addResources(total, resources)
+			addResources(sidecars, resources)
+			continue
+		}
+		running := corev1.ResourceList{}
+		addResources(running, sidecars)
+		addResources(running, resources)
+		maxResources(initPeak, running)
+	}
+	maxResources(total, initPeak)
+
+	addResources(total, capacityResources(pod.Spec.Overhead))
+	return total
+}
+
+// maxResources raises every quantity in dst to at least the one in src
+func maxResources(dst, src corev1.ResourceList) {
+	for name, quantity := range src {
+		if current, ok := dst[name]; !ok || quantity.Cmp(current) > 0 {
+			dst[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+// capacityResources keeps the resources totalled by capacity reports
+func capacityResources(list corev1.ResourceList) corev1.ResourceList {
+	result := corev1.ResourceList{}
+	for _, name := range CapacityResources {
+		if quantity, ok := list[name]; ok {
+			result[name] = quantity.DeepCopy()
+		}
+	}
+	return result
+}
+
+// podWorkload names the workload that owns a pod as Kind/name, following ReplicaSets to
+// their Deployment. Pods without a controller are reported as Pod/name.
+func podWorkload(pod *corev1.Pod, deploymentOf map[string]string) string {
+	for _, owner := range pod.OwnerReferences {
+		if owner.Controller == nil || !*owner.Controller {
+			continue
+		}
+		if owner.Kind == "ReplicaSet" {
+			if deployment, ok := deploymentOf[pod.Namespace+"/"+owner.Name]; ok {
+				return "Deployment/" + deployment
+			}
+		}
+		return owner.Kind + "/" + owner.Name
+	}
+	return "Pod/" + pod.Name
+}
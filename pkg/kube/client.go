@@ -6,8 +6,12 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
+	authorizationv1 "k8s.io/api/authorization/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -24,38 +28,34 @@ type KubeClient struct {
 	Clientset     *kubernetes.Clientset
 	DynamicClient dynamic.Interface
 	RESTConfig    *rest.Config
+	// Admission, when set, checks objects before they are created or updated
+	Admission Admitter
+
+	cacheMu sync.Mutex
+	caches  map[string]*ClusterCache
 }
 
 // NewKubeClient initializes a Kubernetes client with typed, dynamic, and REST clients.
 func NewKubeClient(kubeconfigPath string) (*KubeClient, error) {
-	log.Printf("Initializing Kubernetes client with kubeconfig: %s", kubeconfigPath)
+	return NewKubeClientForContext(kubeconfigPath, "")
+}
 
-	// Expand the tilde to the home directory if present
-	if kubeconfigPath[:2] == "~/" {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to determine home directory: %w", err)
-		}
-		kubeconfigPath = filepath.Join(homeDir, kubeconfigPath[2:])
-	}
-	log.Printf("Resolved kubeconfig path to: %s", kubeconfigPath)
+// NewKubeClientForContext initializes a Kubernetes client for a named kubeconfig context.
+// An empty context uses the kubeconfig's current context.
+func NewKubeClientForContext(kubeconfigPath, contextName string) (*KubeClient, error) {
+	log.Printf("Initializing Kubernetes client with kubeconfig: %s", kubeconfigPath)
 
-	// Ensure the path is absolute
-	absolutePath, err := filepath.Abs(kubeconfigPath)
+	absolutePath, err := ResolveKubeconfigPath(kubeconfigPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve kubeconfig path: %w", err)
-	}
-
-	// Check if kubeconfig file exists
-	if _, err := os.Stat(absolutePath); err != nil {
-		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("kubeconfig file does not exist: %s", absolutePath)
-		}
-		return nil, fmt.Errorf("error accessing kubeconfig file: %w", err)
+		return nil, err
 	}
+	log.Printf("Resolved kubeconfig path to: %s", absolutePath)
 
 	// Load Kubernetes configuration
-	config, err := clientcmd.BuildConfigFromFlags("", absolutePath)
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: absolutePath},
+		&clientcmd.ConfigOverrides{CurrentContext: contextName},
+	).ClientConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
 	}
@@ -80,6 +80,52 @@ func NewKubeClient(kubeconfigPath string) (*KubeClient, error) {
 	}, nil
 }
 
+// ResolveKubeconfigPath expands a leading "~/" and checks that the kubeconfig file exists
+func ResolveKubeconfigPath(kubeconfigPath string) (string, error) {
+	// Expand the tilde to the home directory if present
+	if strings.HasPrefix(kubeconfigPath, "~/") {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to determine home directory: %w", err)
+		}
+		kubeconfigPath = filepath.Join(homeDir, kubeconfigPath[2:])
+	}
+
+	// Ensure the path is absolute
+	absolutePath, err := filepath.Abs(kubeconfigPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve kubeconfig path: %w", err)
+	}
+
+	// Check if kubeconfig file exists
+	if _, err := os.Stat(absolutePath); err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("kubeconfig file does not exist: %s", absolutePath)
+		}
+		return "", fmt.Errorf("error accessing kubeconfig file: %w", err)
+	}
+	return absolutePath, nil
+}
+
+// ListContexts returns the context names in a kubeconfig, sorted, and its current context
+func ListContexts(kubeconfigPath string) ([]string, string, error) {
+	absolutePath, err := ResolveKubeconfigPath(kubeconfigPath)
+	if err != nil {
+		return nil, "", err
+	}
+	config, err := clientcmd.LoadFromFile(absolutePath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	names := make([]string, 0, len(config.Contexts))
+	for name := range config.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, config.CurrentContext, nil
+}
+
 // TestConnection validates connectivity to the Kubernetes API server.
 func (kc *KubeClient) TestConnection() error {
 	version, err := kc.Clientset.ServerVersion()
@@ -90,8 +136,23 @@ func (kc *KubeClient) TestConnection() error {
 	return nil
 }
 
+// CanI reports whether the current user may perform an action, using a SelfSubjectAccessReview.
+func (kc *KubeClient) CanI(attributes authorizationv1.ResourceAttributes) (bool, error) {
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attributes},
+	}
+	result, err := kc.Clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(context.TODO(), review, v1.CreateOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to review access: %w", err)
+	}
+	return result.Status.Allowed, nil
+}
+
 // CreateResource creates a Kubernetes resource dynamically.
 func (kc *KubeClient) CreateResource(resource *unstructured.Unstructured, gvr schema.GroupVersionResource, namespace string) error {
+	if err := kc.admit(resource, namespace); err != nil {
+		return err
+	}
 	_, err := kc.DynamicClient.Resource(gvr).Namespace(namespace).Create(context.TODO(), resource, v1.CreateOptions{})
 	if err != nil {
 		if k8sErrors.IsAlreadyExists(err) {
@@ -105,6 +166,9 @@ func (kc *KubeClient) CreateResource(resource *unstructured.Unstructured, gvr sc
 
 // UpdateResource updates an existing Kubernetes resource dynamically.
 func (kc *KubeClient) UpdateResource(resource *unstructured.Unstructured, gvr schema.GroupVersionResource, namespace string) error {
+	if err := kc.admit(resource, namespace); err != nil {
+		return err
+	}
 	_, err := kc.DynamicClient.Resource(gvr).Namespace(namespace).Update(context.TODO(), resource, v1.UpdateOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to update resource: %w", err)
@@ -5,7 +5,9 @@ import (
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
 )
 
 // ConfigMapConfig holds the configuration for creating/updating a Kubernetes ConfigMap
@@ -44,25 +46,65 @@ func (kc *KubeClient) CreateConfigMap(config ConfigMapConfig) error {
 	return nil
 }
 
-// UpdateConfigMap updates an existing ConfigMap based on the provided ConfigMapConfig
-func (kc *KubeClient) UpdateConfigMap(config ConfigMapConfig) error {
+// ApplyConfigMap creates the ConfigMap described by config, or replaces the data of the
+// existing one and merges in its labels and annotations. Updates that conflict with a
+// concurrent change are retried.
+func (kc *KubeClient) ApplyConfigMap(config ConfigMapConfig) error {
 	configMapsClient := kc.Clientset.CoreV1().ConfigMaps(config.Namespace)
 
-	// Fetch the existing ConfigMap
-	existingConfigMap, err := configMapsClient.Get(context.TODO(), config.Name, metav1.GetOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to fetch configmap: %w", err)
+	created := false
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := configMapsClient.Get(context.TODO(), config.Name, metav1.GetOptions{})
+		if k8sErrors.IsNotFound(err) {
+			created = true
+			return kc.CreateConfigMap(config)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to fetch configmap: %w", err)
+		}
+
+		existing.Labels = mergeStringMaps(existing.Labels, config.Labels)
+		existing.Annotations = mergeStringMaps(existing.Annotations, config.Annotations)
+		existing.Data = config.Data
+		existing.BinaryData = config.BinaryData
+		if _, err := configMapsClient.Update(context.TODO(), existing, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update configmap: %w", err)
+		}
+		return nil
+	})
+	if err != nil || created {
+		return err
 	}
 
-	// Update fields
-	existingConfigMap.Data = config.Data
-	existingConfigMap.BinaryData = config.BinaryData
-	existingConfigMap.Annotations = config.Annotations
+	fmt.Printf("ConfigMap %s updated successfully in namespace %s\n", config.Name, config.Namespace)
+	return nil
+}
+
+// UpdateConfigMap updates an existing ConfigMap based on the provided ConfigMapConfig.
+// Updates that conflict with a concurrent change are retried.
+func (kc *KubeClient) UpdateConfigMap(config ConfigMapConfig) error {
+	configMapsClient := kc.Clientset.CoreV1().ConfigMaps(config.Namespace)
 
-	// Update the ConfigMap
-	_, err = configMapsClient.Update(context.TODO(), existingConfigMap, metav1.UpdateOptions{})
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		// Fetch the existing ConfigMap
+		existingConfigMap, err := configMapsClient.Get(context.TODO(), config.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to fetch configmap: %w", err)
+		}
+
+		// Update fields
+		existingConfigMap.Data = config.Data
+		existingConfigMap.BinaryData = config.BinaryData
+		existingConfigMap.Annotations = config.Annotations
+
+		// Update the ConfigMap
+		if _, err := configMapsClient.Update(context.TODO(), existingConfigMap, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update configmap: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to update configmap: %w", err)
+		return err
 	}
 
 	fmt.Printf("ConfigMap %s updated successfully in namespace %s\n", config.Name, config.Namespace)
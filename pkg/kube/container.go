@@ -0,0 +1,157 @@
+package kube
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ContainerConfig holds the configuration of one container of a Pod or pod template
+type ContainerConfig struct {
+	Name            string
+	Image           string
+	ImagePullPolicy corev1.PullPolicy
+	Command         []string
+	Args            []string
+	WorkingDir      string
+	Ports           []corev1.ContainerPort
+	Env             []corev1.EnvVar
+	EnvFrom         []corev1.EnvFromSource
+	Resources       corev1.ResourceRequirements
+	VolumeMounts    []corev1.VolumeMount
+	LivenessProbe   *corev1.Probe
+	ReadinessProbe  *corev1.Probe
+	StartupProbe    *corev1.Probe
+	Lifecycle       *corev1.Lifecycle
+	SecurityContext *corev1.SecurityContext
+	// Sidecar makes an init container a native sidecar (restartPolicy Always): it starts
+	// before the app containers and keeps running alongside them. Needs Kubernetes 1.29+.
+	Sidecar bool
+}
+
+// ContainerPort declares a named TCP container port
+func ContainerPort(name string, port int32) corev1.ContainerPort {
+	return corev1.ContainerPort{Name: name, ContainerPort: port, Protocol: corev1.ProtocolTCP}
+}
+
+// container builds the Kubernetes container described by a ContainerConfig
+func (c ContainerConfig) container() corev1.Container {
+	container := corev1.Container{
+		Name:            c.Name,
+		Image:           c.Image,
+		ImagePullPolicy: c.ImagePullPolicy,
+		Command:         c.Command,
+		Args:            c.Args,
+		WorkingDir:      c.WorkingDir,
+		Ports:           c.Ports,
+		Env:             c.Env,
+		EnvFrom:         c.EnvFrom,
+		Resources:       c.Resources,
+		VolumeMounts:    c.VolumeMounts,
+		LivenessProbe:   c.LivenessProbe,
+		ReadinessProbe:  c.ReadinessProbe,
+		StartupProbe:    c.StartupProbe,
+		Lifecycle:       c.Lifecycle,
+		SecurityContext: c.SecurityContext,
+	}
+	if c.Sidecar {
+		always := corev1.ContainerRestartPolicyAlways
+		container.RestartPolicy = &always
+	}
+	return container
+}
+
+// podContainers builds the app and init containers of a pod. When no containers are
+// listed, the single container described by the legacy fields is used instead.
+func podContainers(single ContainerConfig, containers, initContainers []ContainerConfig) ([]corev1.Container, []corev1.Container, error) {
+	if len(containers) == 0 {
+		containers = []ContainerConfig{single}
+	}
+
+	seen := make(map[string]bool)
+	check := func(c ContainerConfig) error {
+		if c.Name == "" {
+			return fmt.Errorf("every container needs a name")
+		}
+		if seen[c.Name] {
+			return fmt.Errorf("container name %s is used twice", c.Name)
+		}
+		seen[c.Name] = true
+		if c.Image == "" {
+			return fmt.Errorf("container %s has no image", c.Name)
+		}
+		return nil
+	}
+
+	var app, init []corev1.Container
+	for _, c := range initContainers {
+		if err := check(c); err != nil {
+			return nil, nil, err
+		}
+		init = append(init, c.container())
+	}
+	for _, c := range containers {
+		if err := check(c); err != nil {
+			return nil, nil, err
+		}
+		if c.Sidecar {
+			return nil, nil, fmt.Errorf("container %s: only init containers can be sidecars", c.Name)
+		}
+		app = append(app, c.container())
+	}
+	return app, init, nil
+}
+
+// updateContainers applies the image, resources and env of each configured container to
+// the container of the same name in a pod spec. A single unnamed container may update the
+// only container of the spec.
+func updateContainers(spec *corev1.PodSpec, containers []ContainerConfig) error {
+	for _, c := range containers {
+		target, err := findContainer(spec, c.Name)
+		if err != nil {
+			return err
+		}
+		if c.Image != "" {
+			target.Image = c.Image
+		}
+		if len(c.Resources.Requests) > 0 || len(c.Resources.Limits) > 0 {
+			target.Resources = c.Resources
+		}
+		if len(c.Env) > 0 {
+			target.Env = c.Env
+		}
+	}
+	return nil
+}
+
+// findContainer returns the app or init container with the given name. An empty name
+// selects the only app container of a single-container pod.
+func findContainer(spec *corev1.PodSpec, name string) (*corev1.Container, error) {
+	if name == "" {
+		if len(spec.Containers) != 1 {
+			return nil, fmt.Errorf("pod has %d containers; name the container to update", len(spec.Containers))
+		}
+		return &spec.Containers[0], nil
+	}
+	for i := range spec.Containers {
+		if spec.Containers[i].Name == name {
+			return &spec.Containers[i], nil
+		}
+	}
+	for i := range spec.InitContainers {
+		if spec.InitContainers[i].Name == name {
+			return &spec.InitContainers[i], nil
+		}
+	}
+	return nil, fmt.Errorf("pod has no container named %s", name)
+}
+
+// isInitContainer reports whether name is an init or sidecar container of a pod spec
+func isInitContainer(spec *corev1.PodSpec, name string) bool {
+	for _, container := range spec.InitContainers {
+		if container.Name == name {
+			return true
+		}
+	}
+	return false
+}
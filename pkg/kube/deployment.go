@@ -3,13 +3,19 @@ package kube
 import (
 	"context"
 	"fmt"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
 )
 
-// DeploymentConfig holds the configuration for creating/updating a Kubernetes Deployment
+// DeploymentConfig holds the configuration for creating/updating a Kubernetes Deployment.
+// Image, ContainerName, ContainerPort, Env, Resources, VolumeMounts and the probes describe
+// a single container; set Containers instead for a multi-container pod.
 type DeploymentConfig struct {
 	Name             string
 	Namespace        string
@@ -17,6 +23,8 @@ type DeploymentConfig struct {
 	Image            string
 	ContainerName    string
 	ContainerPort    int32
+	Containers       []ContainerConfig
+	InitContainers   []ContainerConfig
 	Labels           map[string]string
 	Annotations      map[string]string
 	NodeSelector     map[string]string
@@ -37,9 +45,36 @@ type DeploymentConfig struct {
 // CreateDeployment creates a Deployment based on the provided DeploymentConfig
 func (kc *KubeClient) CreateDeployment(config DeploymentConfig) error {
 	deploymentsClient := kc.Clientset.AppsV1().Deployments(config.Namespace)
+	deployment, err := newDeployment(config)
+	if err != nil {
+		return err
+	}
+
+	if err := kc.admitTyped(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment"), config.Namespace); err != nil {
+		return err
+	}
+	if err := kc.checkDeploymentQuota(deployment); err != nil {
+		return err
+	}
+
+	// Create the Deployment
+	_, err = deploymentsClient.Create(context.TODO(), deployment, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create deployment: %w", err)
+	}
+
+	fmt.Printf("Deployment %s created successfully in namespace %s\n", config.Name, config.Namespace)
+	return nil
+}
 
-	// Define the Deployment spec
-	deployment := &appsv1.Deployment{
+// newDeployment builds the Deployment object described by a DeploymentConfig
+func newDeployment(config DeploymentConfig) (*appsv1.Deployment, error) {
+	containers, initContainers, err := podContainers(config.singleContainer(), config.Containers, config.InitContainers)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deployment %s: %w", config.Name, err)
+	}
+
+	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        config.Name,
 			Namespace:   config.Namespace,
@@ -57,22 +92,8 @@ func (kc *KubeClient) CreateDeployment(config DeploymentConfig) error {
 					Annotations: config.Annotations,
 				},
 				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{
-						{
-							Name:  config.ContainerName,
-							Image: config.Image,
-							Ports: []corev1.ContainerPort{
-								{
-									ContainerPort: config.ContainerPort,
-								},
-							},
-							Env:            config.Env,
-							Resources:      config.Resources,
-							VolumeMounts:   config.VolumeMounts,
-							LivenessProbe:  config.LivenessProbe,
-							ReadinessProbe: config.ReadinessProbe,
-						},
-					},
+					InitContainers:                initContainers,
+					Containers:                    containers,
 					NodeSelector:                  config.NodeSelector,
 					Affinity:                      config.Affinity,
 					Tolerations:                   config.Tolerations,
@@ -84,43 +105,177 @@ func (kc *KubeClient) CreateDeployment(config DeploymentConfig) error {
 				},
 			},
 		},
+	}, nil
+}
+
+// singleContainer describes the container of a single-container DeploymentConfig
+func (config DeploymentConfig) singleContainer() ContainerConfig {
+	return ContainerConfig{
+		Name:           config.ContainerName,
+		Image:          config.Image,
+		Ports:          containerPorts(config.ContainerPort),
+		Env:            config.Env,
+		Resources:      config.Resources,
+		VolumeMounts:   config.VolumeMounts,
+		LivenessProbe:  config.LivenessProbe,
+		ReadinessProbe: config.ReadinessProbe,
 	}
+}
 
-	// Create the Deployment
-	_, err := deploymentsClient.Create(context.TODO(), deployment, metav1.CreateOptions{})
+// Images lists the images of the app containers described by config
+func (config DeploymentConfig) Images() []string {
+	if len(config.Containers) == 0 {
+		return []string{config.Image}
+	}
+	images := make([]string, 0, len(config.Containers))
+	for _, container := range config.Containers {
+		images = append(images, container.Image)
+	}
+	return images
+}
+
+// ApplyDeployment creates the Deployment described by config, or replaces the replica
+// count and pod spec of the existing one and merges in its labels and annotations. The
+// selector is kept, since it cannot change. Updates that conflict with a concurrent change
+// are retried.
+func (kc *KubeClient) ApplyDeployment(config DeploymentConfig) error {
+	deploymentsClient := kc.Clientset.AppsV1().Deployments(config.Namespace)
+
+	desired, err := newDeployment(config)
 	if err != nil {
-		return fmt.Errorf("failed to create deployment: %w", err)
+		return err
 	}
 
-	fmt.Printf("Deployment %s created successfully in namespace %s\n", config.Name, config.Namespace)
+	created := false
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := deploymentsClient.Get(context.TODO(), config.Name, metav1.GetOptions{})
+		if k8sErrors.IsNotFound(err) {
+			created = true
+			return kc.CreateDeployment(config)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to fetch deployment: %w", err)
+		}
+
+		// Labels and annotations set by controllers and other tools, such as the revision
+		// and kubectl's restartedAt, are kept so that an unchanged config changes nothing
+		existing.Labels = mergeStringMaps(existing.Labels, desired.Labels)
+		existing.Annotations = mergeStringMaps(existing.Annotations, desired.Annotations)
+		existing.Spec.Replicas = desired.Spec.Replicas
+		existing.Spec.Template.Labels = mergeStringMaps(existing.Spec.Template.Labels, desired.Spec.Template.Labels)
+		existing.Spec.Template.Annotations = mergeStringMaps(existing.Spec.Template.Annotations, desired.Spec.Template.Annotations)
+		existing.Spec.Template.Spec = desired.Spec.Template.Spec
+
+		if err := kc.admitTyped(existing, appsv1.SchemeGroupVersion.WithKind("Deployment"), config.Namespace); err != nil {
+			return err
+		}
+		if _, err := deploymentsClient.Update(context.TODO(), existing, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update deployment: %w", err)
+		}
+		return nil
+	})
+	if err != nil || created {
+		return err
+	}
+
+	fmt.Printf("Deployment %s updated successfully in namespace %s\n", config.Name, config.Namespace)
 	return nil
 }
 
-// UpdateDeployment updates an existing Deployment based on the provided DeploymentConfig
+// mergeStringMaps sets the entries of desired on existing, keeping the other existing entries
+func mergeStringMaps(existing, desired map[string]string) map[string]string {
+	if len(desired) == 0 {
+		return existing
+	}
+	if existing == nil {
+		existing = make(map[string]string, len(desired))
+	}
+	for key, value := range desired {
+		existing[key] = value
+	}
+	return existing
+}
+
+// containerPorts declares the container port when one is set
+func containerPorts(port int32) []corev1.ContainerPort {
+	if port == 0 {
+		return nil
+	}
+	return []corev1.ContainerPort{{ContainerPort: port}}
+}
+
+// UpdateDeployment sets the replica count of an existing Deployment and the image,
+// resources and env of its containers, matched by name. Fields left empty keep their
+// current value. Updates that conflict with a concurrent change are retried.
 func (kc *KubeClient) UpdateDeployment(config DeploymentConfig) error {
 	deploymentsClient := kc.Clientset.AppsV1().Deployments(config.Namespace)
 
-	// Fetch the existing Deployment
-	existingDeployment, err := deploymentsClient.Get(context.TODO(), config.Name, metav1.GetOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to fetch deployment: %w", err)
+	containers := append(append([]ContainerConfig{}, config.Containers...), config.InitContainers...)
+	if len(containers) == 0 {
+		containers = []ContainerConfig{{Name: config.ContainerName, Image: config.Image, Resources: config.Resources}}
 	}
 
-	// Update fields
-	existingDeployment.Spec.Replicas = &config.Replicas
-	existingDeployment.Spec.Template.Spec.Containers[0].Image = config.Image
-	existingDeployment.Spec.Template.Spec.Containers[0].Resources = config.Resources
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		// Fetch the existing Deployment
+		existingDeployment, err := deploymentsClient.Get(context.TODO(), config.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to fetch deployment: %w", err)
+		}
+
+		// Update fields
+		existingDeployment.Spec.Replicas = &config.Replicas
+		if err := updateContainers(&existingDeployment.Spec.Template.Spec, containers); err != nil {
+			return fmt.Errorf("failed to update deployment %s: %w", config.Name, err)
+		}
 
-	// Update the Deployment
-	_, err = deploymentsClient.Update(context.TODO(), existingDeployment, metav1.UpdateOptions{})
+		if err := kc.admitTyped(existingDeployment, appsv1.SchemeGroupVersion.WithKind("Deployment"), config.Namespace); err != nil {
+			return err
+		}
+
+		// Update the Deployment
+		if _, err := deploymentsClient.Update(context.TODO(), existingDeployment, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update deployment: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to update deployment: %w", err)
+		return err
 	}
 
 	fmt.Printf("Deployment %s updated successfully in namespace %s\n", config.Name, config.Namespace)
 	return nil
 }
 
+// ScaleDeployment sets the replica count of a Deployment through its scale subresource
+func (kc *KubeClient) ScaleDeployment(name, namespace string, replicas int32) error {
+	deploymentsClient := kc.Clientset.AppsV1().Deployments(namespace)
+
+	scale, err := deploymentsClient.GetScale(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to fetch deployment scale: %w", err)
+	}
+	scale.Spec.Replicas = replicas
+
+	if _, err := deploymentsClient.UpdateScale(context.TODO(), name, scale, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to scale deployment: %w", err)
+	}
+	return nil
+}
+
+// RestartDeployment triggers a rolling restart by stamping the pod template,
+// the same way "kubectl rollout restart" does
+func (kc *KubeClient) RestartDeployment(name, namespace string) error {
+	deploymentsClient := kc.Clientset.AppsV1().Deployments(namespace)
+
+	patch := fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{"kubectl.kubernetes.io/restartedAt":%q}}}}}`,
+		time.Now().Format(time.RFC3339))
+	_, err := deploymentsClient.Patch(context.TODO(), name, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to restart deployment: %w", err)
+	}
+	return nil
+}
+
 // ListDeployments lists all Deployments in the specified namespace
 func (kc *KubeClient) ListDeployments(namespace string, labelSelector string) ([]appsv1.Deployment, error) {
 	deploymentsClient := kc.Clientset.AppsV1().Deployments(namespace)
@@ -137,6 +292,22 @@ func (kc *KubeClient) ListDeployments(namespace string, labelSelector string) ([
 	return deployments.Items, nil
 }
 
+// ListReplicaSets lists all ReplicaSets in the specified namespace
+func (kc *KubeClient) ListReplicaSets(namespace string, labelSelector string) ([]appsv1.ReplicaSet, error) {
+	replicaSetsClient := kc.Clientset.AppsV1().ReplicaSets(namespace)
+
+	// Fetch replicasets
+	listOptions := metav1.ListOptions{
+		LabelSelector: labelSelector,
+	}
+	replicaSets, err := replicaSetsClient.List(context.TODO(), listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replicasets: %w", err)
+	}
+
+	return replicaSets.Items, nil
+}
+
 // DeleteDeployment deletes a Deployment by name in the specified namespace
 func (kc *KubeClient) DeleteDeployment(name, namespace string) error {
 	deploymentsClient := kc.Clientset.AppsV1().Deployments(namespace)
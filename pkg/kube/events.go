@@ -4,43 +4,34 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sort"
+	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/apimachinery/pkg/watch"
-	"k8s.io/client-go/util/retry"
 )
 
+// eventsResource is the GroupVersionResource for core/v1 Events
+var eventsResource = schema.GroupVersionResource{Version: "v1", Resource: "events"}
+
 // StreamEvents streams Kubernetes resource events in real-time
 func (kc *KubeClient) StreamEvents(namespace string) error {
-	client := kc.Clientset.CoreV1().Events(namespace)
-
-	// Watch for resource events
-	watcher, err := client.Watch(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to start event watcher: %w", err)
-	}
-	defer watcher.Stop()
-
 	fmt.Printf("Streaming events in namespace %s\n", namespace)
 
-	// Process events from the watcher channel
-	for event := range watcher.ResultChan() {
-		switch event.Type {
-		case watch.Added:
-			describeEvent("ADDED", event.Object)
-		case watch.Modified:
-			describeEvent("MODIFIED", event.Object)
-		case watch.Deleted:
-			describeEvent("DELETED", event.Object)
-		default:
-			log.Printf("Unknown event type: %v\n", event.Type)
-		}
-	}
-	return nil
+	// Process events from the watcher until the process exits
+	return kc.WatchEvents(EventWatcherConfig{
+		Namespace:    namespace,
+		ResourceType: eventsResource,
+		OnAdd:        func(obj runtime.Object) { describeEvent("ADDED", toTypedEvent(obj)) },
+		OnModify:     func(obj runtime.Object) { describeEvent("MODIFIED", toTypedEvent(obj)) },
+		OnDelete:     func(obj runtime.Object) { describeEvent("DELETED", toTypedEvent(obj)) },
+	})
 }
 
 // EventWatcherConfig holds the configuration for watching Kubernetes events
@@ -49,72 +40,56 @@ type EventWatcherConfig struct {
 	ResourceType  schema.GroupVersionResource
 	LabelSelector string
 	FieldSelector string
-	RetryInterval time.Duration
-	RetryTimeout  time.Duration
-	OnAdd         func(runtime.Object)
-	OnModify      func(runtime.Object)
-	OnDelete      func(runtime.Object)
+	// ResyncPeriod controls how often every cached object is re-delivered to OnResync.
+	// Defaults to DefaultResyncPeriod.
+	ResyncPeriod time.Duration
+	// StopCh stops the watch when closed; a nil channel watches until the process exits.
+	StopCh   <-chan struct{}
+	OnAdd    func(runtime.Object)
+	OnModify func(runtime.Object)
+	// OnResync receives unchanged objects on every resync; OnModify only sees real changes
+	OnResync     func(runtime.Object)
+	OnDelete     func(runtime.Object)
+	OnWatchError func(error)
 }
 
-// WatchEvents continuously streams Kubernetes resource events
+// WatchEvents continuously streams Kubernetes resource events.
+// It blocks until config.StopCh is closed, reconnecting and relisting as needed.
 func (kc *KubeClient) WatchEvents(config EventWatcherConfig) error {
-	client := kc.DynamicClient.Resource(config.ResourceType).Namespace(config.Namespace)
-
-	// Retry mechanism for transient errors
-	err := retry.OnError(retry.DefaultRetry, func(err error) bool {
-		log.Printf("Retrying due to error: %v\n", err)
-		return true // Always retry for this example; can be customized
-	}, func() error {
-		ctx, cancel := context.WithTimeout(context.Background(), config.RetryTimeout)
-		defer cancel()
-
-		// Start watching events
-		watcher, err := client.Watch(ctx, metav1.ListOptions{
-			LabelSelector: config.LabelSelector,
-			FieldSelector: config.FieldSelector,
-		})
-		if err != nil {
-			return fmt.Errorf("failed to watch events for %s: %w", config.ResourceType.Resource, err)
-		}
-		defer watcher.Stop()
-
-		fmt.Printf("Watching events for %s in namespace %s\n", config.ResourceType.Resource, config.Namespace)
+	watcher, err := kc.NewResourceWatcher(config)
+	if err != nil {
+		return fmt.Errorf("failed to create watcher for %s: %w", config.ResourceType.Resource, err)
+	}
 
-		// Process events
-		for event := range watcher.ResultChan() {
-			processEvent(event, config)
-		}
-		return nil
-	})
+	fmt.Printf("Watching events for %s in namespace %s\n", config.ResourceType.Resource, config.Namespace)
 
-	if err != nil {
+	if err := watcher.Run(config.StopCh); err != nil {
 		return fmt.Errorf("event watcher failed: %w", err)
 	}
 	return nil
 }
 
-// processEvent processes a single Kubernetes event
-func processEvent(event watch.Event, config EventWatcherConfig) {
-	switch event.Type {
-	case watch.Added:
-		if config.OnAdd != nil {
-			config.OnAdd(event.Object)
-		}
-	case watch.Modified:
-		if config.OnModify != nil {
-			config.OnModify(event.Object)
-		}
-	case watch.Deleted:
-		if config.OnDelete != nil {
-			config.OnDelete(event.Object)
-		}
-	default:
-		log.Printf("Unknown event type: %v\n", event.Type)
+// toTypedEvent converts an unstructured core/v1 Event into its typed form.
+// Objects that cannot be converted are returned unchanged.
+func toTypedEvent(obj runtime.Object) runtime.Object {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return obj
+	}
+	event := &corev1.Event{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, event); err != nil {
+		return obj
 	}
+	return event
 }
 
 // describeEvent provides a detailed log for a Kubernetes resource event
 func describeEvent(eventType string, obj runtime.Object) {
+	if event, ok := obj.(*corev1.Event); ok {
+		fmt.Printf("[%s] %s %s %s/%s: %s\n",
+			eventType, event.Type, event.Reason, event.InvolvedObject.Kind, event.InvolvedObject.Name, event.Message)
+		return
+	}
 	metaObj, err := meta.Accessor(obj)
 	if err != nil {
 		log.Printf("[%s] Unable to access object metadata: %v", eventType, err)
@@ -123,3 +98,162 @@ func describeEvent(eventType string, obj runtime.Object) {
 	fmt.Printf("[%s] Name: %s, Namespace: %s, Labels: %v\n",
 		eventType, metaObj.GetName(), metaObj.GetNamespace(), metaObj.GetLabels())
 }
+
+// EventFilter narrows down the core/v1 Events returned by ListEvents and WatchClusterEvents
+type EventFilter struct {
+	Namespace    string
+	InvolvedKind string
+	InvolvedName string
+	Types        []string
+	Since        time.Duration
+}
+
+// fieldSelector builds the server-side field selector for the filter
+func (f EventFilter) fieldSelector() string {
+	var selectors []fields.Selector
+	if f.InvolvedKind != "" {
+		selectors = append(selectors, fields.OneTermEqualSelector("involvedObject.kind", f.InvolvedKind))
+	}
+	if f.InvolvedName != "" {
+		selectors = append(selectors, fields.OneTermEqualSelector("involvedObject.name", f.InvolvedName))
+	}
+	// The API server only accepts a single value per field, so multiple types are filtered client-side
+	if len(f.Types) == 1 {
+		selectors = append(selectors, fields.OneTermEqualSelector("type", f.Types[0]))
+	}
+	return fields.AndSelectors(selectors...).String()
+}
+
+// Matches reports whether an event satisfies the filter
+func (f EventFilter) Matches(event *corev1.Event) bool {
+	if f.InvolvedKind != "" && !strings.EqualFold(event.InvolvedObject.Kind, f.InvolvedKind) {
+		return false
+	}
+	if f.InvolvedName != "" && event.InvolvedObject.Name != f.InvolvedName {
+		return false
+	}
+	if len(f.Types) > 0 {
+		matched := false
+		for _, eventType := range f.Types {
+			if strings.EqualFold(event.Type, eventType) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if f.Since > 0 && EventLastSeen(event).Before(time.Now().Add(-f.Since)) {
+		return false
+	}
+	return true
+}
+
+// ListEvents lists core/v1 Events matching the filter, sorted from oldest to newest
+func (kc *KubeClient) ListEvents(filter EventFilter) ([]corev1.Event, error) {
+	eventsClient := kc.Clientset.CoreV1().Events(filter.Namespace)
+
+	events, err := eventsClient.List(context.TODO(), metav1.ListOptions{
+		FieldSelector: filter.fieldSelector(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	var matched []corev1.Event
+	for i := range events.Items {
+		if filter.Matches(&events.Items[i]) {
+			matched = append(matched, events.Items[i])
+		}
+	}
+	SortEventsByTime(matched)
+	return matched, nil
+}
+
+// WatchClusterEvents streams core/v1 Events matching the filter to the handler.
+// The initial list is delivered first, followed by every subsequent add or update.
+func (kc *KubeClient) WatchClusterEvents(filter EventFilter, handler func(*corev1.Event)) error {
+	deliver := func(obj runtime.Object) {
+		clusterEvent, ok := toTypedEvent(obj).(*corev1.Event)
+		if !ok || !filter.Matches(clusterEvent) {
+			return
+		}
+		handler(clusterEvent)
+	}
+
+	watcher, err := kc.NewResourceWatcher(EventWatcherConfig{
+		Namespace:     filter.Namespace,
+		ResourceType:  eventsResource,
+		FieldSelector: filter.fieldSelector(),
+		OnAdd:         deliver,
+		OnModify:      deliver,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start event watcher: %w", err)
+	}
+	return watcher.Run(nil)
+}
+
+// EventFirstSeen returns the time an event was first observed
+func EventFirstSeen(event *corev1.Event) time.Time {
+	switch {
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
+
+// EventLastSeen returns the time an event was most recently observed
+func EventLastSeen(event *corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case event.Series != nil && !event.Series.LastObservedTime.IsZero():
+		return event.Series.LastObservedTime.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return EventFirstSeen(event)
+	}
+}
+
+// FormatAge renders the time elapsed since t in the compact style used by kubectl
+// (e.g. 45s, 5m, 2h, 3d); a zero time is rendered as <unknown>
+func FormatAge(t time.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	age := time.Since(t)
+	switch {
+	case age < time.Minute:
+		return fmt.Sprintf("%ds", int(age.Seconds()))
+	case age < time.Hour:
+		return fmt.Sprintf("%dm", int(age.Minutes()))
+	case age < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(age.Hours()/24))
+	}
+}
+
+// EventCount returns how many times an event occurred, accounting for event series
+func EventCount(event *corev1.Event) int32 {
+	if event.Series != nil && event.Series.Count > event.Count {
+		return event.Series.Count
+	}
+	if event.Count == 0 {
+		return 1
+	}
+	return event.Count
+}
+
+// SortEventsByTime sorts events by their last-seen time, oldest first
+func SortEventsByTime(events []corev1.Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return EventLastSeen(&events[i]).Before(EventLastSeen(&events[j]))
+	})
+}
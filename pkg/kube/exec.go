@@ -0,0 +1,112 @@
+package kube
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// ExecInPod runs a command in a Pod container, feeding it stdin when set. Output is
+// returned, and the error includes stderr when the command fails.
+func (kc *KubeClient) ExecInPod(ctx context.Context, podName, namespace, container string, command []string, stdin io.Reader) (string, error) {
+	request := kc.Clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(namespace).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdin:     stdin != nil,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(kc.RESTConfig, "POST", request.URL())
+	if err != nil {
+		return "", fmt.Errorf("failed to create executor: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	err = executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return stdout.String(), fmt.Errorf("command failed in pod %s: %w: %s", podName, err, message)
+		}
+		return stdout.String(), fmt.Errorf("command failed in pod %s: %w", podName, err)
+	}
+	return stdout.String(), nil
+}
+
+// SyncFilesToPod copies files from localDir into remoteDir of a Pod container, keeping
+// their paths relative to the directories. Files in relPaths that no longer exist
+// locally are deleted from the container. The container image must provide tar.
+func (kc *KubeClient) SyncFilesToPod(ctx context.Context, podName, namespace, container, localDir, remoteDir string, relPaths []string) error {
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	var removed []string
+	copied := 0
+
+	for _, relPath := range relPaths {
+		file := filepath.Join(localDir, relPath)
+		info, err := os.Stat(file)
+		if os.IsNotExist(err) {
+			removed = append(removed, path.Join(remoteDir, filepath.ToSlash(relPath)))
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(relPath)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+		copied++
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	if copied > 0 {
+		command := []string{"tar", "-xmf", "-", "-C", remoteDir}
+		if _, err := kc.ExecInPod(ctx, podName, namespace, container, command, &archive); err != nil {
+			return fmt.Errorf("failed to copy files: %w", err)
+		}
+	}
+	if len(removed) > 0 {
+		command := append([]string{"rm", "-f", "--"}, removed...)
+		if _, err := kc.ExecInPod(ctx, podName, namespace, container, command, nil); err != nil {
+			return fmt.Errorf("failed to delete files: %w", err)
+		}
+	}
+	return nil
+}
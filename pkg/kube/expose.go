@@ -0,0 +1,231 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// ExposeConfig describes a Service to create for the pods of a Deployment or StatefulSet
+type ExposeConfig struct {
+	// Kind is "deployment" or "statefulset"; short names such as deploy and sts work too
+	Kind      string
+	Workload  string
+	Namespace string
+	// Name of the Service; defaults to the workload name
+	Name     string
+	Type     corev1.ServiceType
+	Headless bool
+	// Ports overrides the ports derived from the pod template's container ports
+	Ports []corev1.ServicePort
+}
+
+// ServiceForWorkload builds a Service for a Deployment or StatefulSet: its selector is the
+// workload's selector and its ports are the container ports declared in the pod template,
+// targeted by name where the container names them
+func (kc *KubeClient) ServiceForWorkload(config ExposeConfig) (ServiceConfig, error) {
+	kind, selector, template, workloadLabels, err := kc.workloadTemplate(config.Kind, config.Workload, config.Namespace)
+	if err != nil {
+		return ServiceConfig{}, err
+	}
+	if selector == nil || len(selector.MatchExpressions) > 0 || len(selector.MatchLabels) == 0 {
+		return ServiceConfig{}, fmt.Errorf("%s %s has a selector a Service cannot express; Services only match labels exactly", kind, config.Workload)
+	}
+
+	name := config.Name
+	if name == "" {
+		name = config.Workload
+	}
+	serviceType := config.Type
+	if serviceType == "" {
+		serviceType = corev1.ServiceTypeClusterIP
+	}
+	service := ServiceConfig{
+		Name:      name,
+		Namespace: config.Namespace,
+		Labels:    workloadLabels,
+		Selector:  selector.MatchLabels,
+		Type:      serviceType,
+		Ports:     config.Ports,
+	}
+	if config.Headless {
+		if serviceType != corev1.ServiceTypeClusterIP {
+			return ServiceConfig{}, fmt.Errorf("a headless Service must be of type ClusterIP, not %s", serviceType)
+		}
+		service.ClusterIP = corev1.ClusterIPNone
+	}
+
+	if len(service.Ports) == 0 {
+		service.Ports = templatePorts(template.Spec)
+	}
+	if len(service.Ports) == 0 {
+		return ServiceConfig{}, fmt.Errorf("%s %s declares no container ports; pass the ports to expose", kind, config.Workload)
+	}
+	return service, nil
+}
+
+// workloadTemplate returns the kind, selector, pod template and labels of a workload
+func (kc *KubeClient) workloadTemplate(kind, name, namespace string) (string, *metav1.LabelSelector, *corev1.PodTemplateSpec, map[string]string, error) {
+	switch strings.ToLower(kind) {
+	case "deployment", "deployments", "deploy":
+		deployment, err := kc.Clientset.AppsV1().Deployments(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return "", nil, nil, nil, fmt.Errorf("failed to fetch deployment: %w", err)
+		}
+		return "deployment", deployment.Spec.Selector, &deployment.Spec.Template, deployment.Labels, nil
+	case "statefulset", "statefulsets", "sts":
+		statefulSet, err := kc.Clientset.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return "", nil, nil, nil, fmt.Errorf("failed to fetch statefulset: %w", err)
+		}
+		return "statefulset", statefulSet.Spec.Selector, &statefulSet.Spec.Template, statefulSet.Labels, nil
+	default:
+		return "", nil, nil, nil, fmt.Errorf("cannot expose %q: only deployments and statefulsets are supported", kind)
+	}
+}
+
+// templatePorts converts the container ports of the app and sidecar containers of a pod
+// spec to Service ports. Unnamed ports are named after their protocol and number, since
+// a Service with several ports needs every port named. A name that several containers
+// give to different ports is ambiguous as a target, so the later ports are targeted by
+// number under the generated name instead.
+func templatePorts(spec corev1.PodSpec) []corev1.ServicePort {
+	containers := append([]corev1.Container{}, spec.Containers...)
+	for _, container := range spec.InitContainers {
+		if container.RestartPolicy != nil && *container.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			containers = append(containers, container)
+		}
+	}
+
+	var ports []corev1.ServicePort
+	seen := make(map[string]bool)
+	seenNames := make(map[string]bool)
+	for _, container := range containers {
+		for _, port := range container.Ports {
+			protocol := port.Protocol
+			if protocol == "" {
+				protocol = corev1.ProtocolTCP
+			}
+			key := fmt.Sprintf("%d/%s", port.ContainerPort, protocol)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			servicePort := corev1.ServicePort{
+				Name:       port.Name,
+				Port:       port.ContainerPort,
+				Protocol:   protocol,
+				TargetPort: intstr.FromInt32(port.ContainerPort),
+			}
+			if port.Name != "" && !seenNames[port.Name] {
+				servicePort.TargetPort = intstr.FromString(port.Name)
+			} else {
+				servicePort.Name = fmt.Sprintf("%s-%d", strings.ToLower(string(protocol)), port.ContainerPort)
+			}
+			if seenNames[servicePort.Name] {
+				// Only possible when a container names a port after another port's number
+				continue
+			}
+			seenNames[servicePort.Name] = true
+			ports = append(ports, servicePort)
+		}
+	}
+	return ports
+}
+
+// WaitForServiceEndpoints watches the EndpointSlices of a Service until it has a ready
+// endpoint and returns the ready addresses as "ip:port"
+func (kc *KubeClient) WaitForServiceEndpoints(ctx context.Context, name, namespace string, timeout time.Duration) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	slicesClient := kc.Clientset.DiscoveryV1().EndpointSlices(namespace)
+	listOptions := metav1.ListOptions{LabelSelector: discoveryv1.LabelServiceName + "=" + name}
+	for {
+		slices, err := slicesClient.List(ctx, listOptions)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("service %s has no ready endpoints after %s", name, timeout)
+			}
+			return nil, fmt.Errorf("failed to list endpointslices: %w", err)
+		}
+		if addresses := readyAddresses(slices.Items); len(addresses) > 0 {
+			return addresses, nil
+		}
+
+		// Watch from the listed version so that no change between the list and the watch is missed
+		watchOptions := listOptions
+		watchOptions.ResourceVersion = slices.ResourceVersion
+		watcher, err := slicesClient.Watch(ctx, watchOptions)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("service %s has no ready endpoints after %s", name, timeout)
+			}
+			if k8sErrors.IsGone(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to watch endpointslices: %w", err)
+		}
+		ready, err := waitForReadySlice(ctx, watcher)
+		watcher.Stop()
+		if err != nil {
+			return nil, fmt.Errorf("service %s has no ready endpoints after %s", name, timeout)
+		}
+		if ready {
+			// Report the ready endpoints of every slice, not only the one that changed
+			return kc.ServiceEndpoints(name, namespace)
+		}
+		// The watch ended early; list again and resume after a pause
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("service %s has no ready endpoints after %s", name, timeout)
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// waitForReadySlice reads watch events until an EndpointSlice with a ready endpoint
+// appears. It returns false when the watch closes, and an error when ctx is done.
+func waitForReadySlice(ctx context.Context, watcher watch.Interface) (bool, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return false, nil
+			}
+			if event.Type != watch.Added && event.Type != watch.Modified {
+				continue
+			}
+			if slice, ok := event.Object.(*discoveryv1.EndpointSlice); ok {
+				if len(readyAddresses([]discoveryv1.EndpointSlice{*slice})) > 0 {
+					return true, nil
+				}
+			}
+		}
+	}
+}
+
+// DiagnoseSelectedPods diagnoses the pods a Service selector matches
+func (kc *KubeClient) DiagnoseSelectedPods(selector map[string]string, namespace string) ([]PodDiagnosis, error) {
+	pods, err := kc.ListPods(namespace, labels.SelectorFromSet(selector).String())
+	if err != nil {
+		return nil, err
+	}
+	diagnoses := make([]PodDiagnosis, 0, len(pods))
+	for i := range pods {
+		diagnoses = append(diagnoses, DiagnosePod(&pods[i]))
+	}
+	return diagnoses, nil
+}
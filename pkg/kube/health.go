@@ -0,0 +1,306 @@
+package kube
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PodHealth classifies the overall health of a Pod
+type PodHealth string
+
+const (
+	PodHealthReady            PodHealth = "Ready"
+	PodHealthNotReady         PodHealth = "NotReady"
+	PodHealthPending          PodHealth = "Pending"
+	PodHealthCrashLoopBackOff PodHealth = "CrashLoopBackOff"
+	PodHealthImagePullBackOff PodHealth = "ImagePullBackOff"
+	PodHealthOOMKilled        PodHealth = "OOMKilled"
+	PodHealthEvicted          PodHealth = "Evicted"
+	PodHealthInitFailed       PodHealth = "InitFailed"
+	PodHealthTerminating      PodHealth = "Terminating"
+	PodHealthSucceeded        PodHealth = "Succeeded"
+	PodHealthFailed           PodHealth = "Failed"
+	PodHealthUnknown          PodHealth = "Unknown"
+)
+
+// imagePullReasons are container waiting reasons caused by image pull failures
+var imagePullReasons = map[string]bool{
+	"ImagePullBackOff":  true,
+	"ErrImagePull":      true,
+	"ErrImageNeverPull": true,
+	"InvalidImageName":  true,
+}
+
+// PodDiagnosis describes the health of a single Pod
+type PodDiagnosis struct {
+	Name      string
+	Namespace string
+	Health    PodHealth
+	Reason    string
+	Message   string
+	Container string
+	Restarts  int32
+}
+
+// Healthy reports whether the diagnosis needs no attention
+func (d PodDiagnosis) Healthy() bool {
+	return d.Health == PodHealthReady || d.Health == PodHealthSucceeded
+}
+
+// String renders the diagnosis as a single human-readable line
+func (d PodDiagnosis) String() string {
+	parts := []string{string(d.Health)}
+	var details []string
+	if d.Container != "" {
+		details = append(details, "container "+d.Container)
+	}
+	if d.Reason != "" && d.Reason != string(d.Health) {
+		details = append(details, d.Reason)
+	}
+	if d.Message != "" {
+		details = append(details, d.Message)
+	}
+	if len(details) > 0 {
+		parts = append(parts, "("+strings.Join(details, ": ")+")")
+	}
+	return strings.Join(parts, " ")
+}
+
+// DiagnosePod classifies a Pod's health from its phase, conditions and container statuses
+func DiagnosePod(pod *corev1.Pod) PodDiagnosis {
+	diagnosis := PodDiagnosis{
+		Name:      pod.Name,
+		Namespace: pod.Namespace,
+		Restarts:  podRestarts(pod),
+	}
+
+	if pod.DeletionTimestamp != nil {
+		diagnosis.Health = PodHealthTerminating
+		return diagnosis
+	}
+
+	if pod.Status.Phase == corev1.PodFailed && pod.Status.Reason == "Evicted" {
+		diagnosis.Health = PodHealthEvicted
+		diagnosis.Reason = pod.Status.Reason
+		diagnosis.Message = pod.Status.Message
+		return diagnosis
+	}
+
+	// Init containers run before anything else, so their failures explain everything downstream
+	for _, status := range pod.Status.InitContainerStatuses {
+		if status.State.Waiting != nil {
+			reason := status.State.Waiting.Reason
+			switch {
+			case imagePullReasons[reason]:
+				return withContainer(diagnosis, PodHealthImagePullBackOff, status.Name, reason, status.State.Waiting.Message)
+			case reason == "CrashLoopBackOff":
+				return withContainer(diagnosis, PodHealthInitFailed, status.Name, "Init:CrashLoopBackOff", lastTermination(status))
+			}
+		}
+		if terminated := status.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+			message := fmt.Sprintf("exited with code %d", terminated.ExitCode)
+			return withContainer(diagnosis, PodHealthInitFailed, status.Name, "Init:"+terminated.Reason, message)
+		}
+	}
+
+	for _, status := range pod.Status.ContainerStatuses {
+		if waiting := status.State.Waiting; waiting != nil {
+			switch {
+			case imagePullReasons[waiting.Reason]:
+				return withContainer(diagnosis, PodHealthImagePullBackOff, status.Name, waiting.Reason, waiting.Message)
+			case waiting.Reason == "CrashLoopBackOff":
+				return withContainer(diagnosis, PodHealthCrashLoopBackOff, status.Name, waiting.Reason, lastTermination(status))
+			}
+		}
+		if terminated := status.State.Terminated; terminated != nil && terminated.Reason == "OOMKilled" {
+			return withContainer(diagnosis, PodHealthOOMKilled, status.Name, terminated.Reason, "")
+		}
+		// A container restarting after an OOM kill stays OOMKilled until it is ready again
+		if last := status.LastTerminationState.Terminated; last != nil && last.Reason == "OOMKilled" && !status.Ready {
+			return withContainer(diagnosis, PodHealthOOMKilled, status.Name, last.Reason, "restarted after running out of memory")
+		}
+	}
+
+	switch pod.Status.Phase {
+	case corev1.PodPending:
+		diagnosis.Health = PodHealthPending
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse {
+				diagnosis.Reason = condition.Reason
+				diagnosis.Message = condition.Message
+				return diagnosis
+			}
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Waiting != nil {
+				diagnosis.Container = status.Name
+				diagnosis.Reason = status.State.Waiting.Reason
+				diagnosis.Message = status.State.Waiting.Message
+				return diagnosis
+			}
+		}
+		return diagnosis
+	case corev1.PodSucceeded:
+		diagnosis.Health = PodHealthSucceeded
+		return diagnosis
+	case corev1.PodFailed:
+		diagnosis.Health = PodHealthFailed
+		diagnosis.Reason = pod.Status.Reason
+		diagnosis.Message = pod.Status.Message
+		return diagnosis
+	}
+
+	diagnosis.Health = PodHealthUnknown
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type != corev1.PodReady {
+			continue
+		}
+		if condition.Status == corev1.ConditionTrue {
+			diagnosis.Health = PodHealthReady
+		} else if condition.Status == corev1.ConditionFalse {
+			diagnosis.Health = PodHealthNotReady
+			diagnosis.Reason = condition.Reason
+			diagnosis.Message = condition.Message
+		}
+	}
+	return diagnosis
+}
+
+// withContainer fills in a container-level diagnosis
+func withContainer(diagnosis PodDiagnosis, health PodHealth, container, reason, message string) PodDiagnosis {
+	diagnosis.Health = health
+	diagnosis.Container = container
+	diagnosis.Reason = reason
+	diagnosis.Message = message
+	return diagnosis
+}
+
+// lastTermination describes why a container last terminated, e.g. "last terminated: OOMKilled (exit 137)"
+func lastTermination(status corev1.ContainerStatus) string {
+	last := status.LastTerminationState.Terminated
+	if last == nil {
+		return ""
+	}
+	return fmt.Sprintf("last terminated: %s (exit %d)", last.Reason, last.ExitCode)
+}
+
+// lastOOMKill returns the container whose last termination was the most recent OOM kill,
+// and when it finished
+func lastOOMKill(pod *corev1.Pod) (string, time.Time) {
+	var container string
+	var finishedAt time.Time
+	for _, status := range pod.Status.ContainerStatuses {
+		last := status.LastTerminationState.Terminated
+		if last != nil && last.Reason == "OOMKilled" && last.FinishedAt.Time.After(finishedAt) {
+			container, finishedAt = status.Name, last.FinishedAt.Time
+		}
+	}
+	return container, finishedAt
+}
+
+// podRestarts sums the restart counts of all init and app containers
+func podRestarts(pod *corev1.Pod) int32 {
+	var restarts int32
+	for _, status := range pod.Status.InitContainerStatuses {
+		restarts += status.RestartCount
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		restarts += status.RestartCount
+	}
+	return restarts
+}
+
+// PodTransition describes a change in a Pod's health between two observations
+type PodTransition struct {
+	Diagnosis    PodDiagnosis
+	Previous     PodHealth
+	RestartDelta int32
+	New          bool
+	Deleted      bool
+}
+
+// String renders the transition as a single human-readable line
+func (t PodTransition) String() string {
+	switch {
+	case t.Deleted:
+		return fmt.Sprintf("Pod %s: deleted (was %s)", t.Diagnosis.Name, t.Previous)
+	case t.New:
+		return fmt.Sprintf("Pod %s: %s", t.Diagnosis.Name, t.Diagnosis)
+	}
+
+	line := fmt.Sprintf("Pod %s: ", t.Diagnosis.Name)
+	if t.Previous != t.Diagnosis.Health {
+		line += fmt.Sprintf("%s -> ", t.Previous)
+	}
+	line += t.Diagnosis.String()
+	if t.RestartDelta > 0 {
+		line += fmt.Sprintf(" [+%d restarts, %d total]", t.RestartDelta, t.Diagnosis.Restarts)
+	}
+	return line
+}
+
+// PodHealthTracker remembers each Pod's last diagnosis so only changes are reported
+type PodHealthTracker struct {
+	last map[string]PodDiagnosis
+	// oomKills holds when each Pod's most recent OOM kill finished
+	oomKills map[string]time.Time
+}
+
+// NewPodHealthTracker creates an empty PodHealthTracker
+func NewPodHealthTracker() *PodHealthTracker {
+	return &PodHealthTracker{last: make(map[string]PodDiagnosis), oomKills: make(map[string]time.Time)}
+}
+
+// Observe diagnoses the given pods and returns the transitions since the previous call.
+// A transition is reported when a pod appears, disappears, changes health or restarts.
+// A pod whose container was OOM killed since the previous call is reported as OOMKilled
+// once, even if it was ready again by the time it was observed.
+func (t *PodHealthTracker) Observe(pods []corev1.Pod) []PodTransition {
+	var transitions []PodTransition
+	current := make(map[string]PodDiagnosis, len(pods))
+	oomKills := make(map[string]time.Time, len(pods))
+
+	for i := range pods {
+		diagnosis := DiagnosePod(&pods[i])
+		key := diagnosis.Namespace + "/" + diagnosis.Name
+
+		container, oomKilledAt := lastOOMKill(&pods[i])
+		oomKills[key] = oomKilledAt
+		if _, seen := t.last[key]; seen && oomKilledAt.After(t.oomKills[key]) && diagnosis.Healthy() {
+			diagnosis = withContainer(diagnosis, PodHealthOOMKilled, container, "OOMKilled", "restarted after running out of memory")
+		}
+		current[key] = diagnosis
+
+		previous, seen := t.last[key]
+		if !seen {
+			transitions = append(transitions, PodTransition{Diagnosis: diagnosis, New: true})
+			continue
+		}
+
+		delta := diagnosis.Restarts - previous.Restarts
+		if previous.Health != diagnosis.Health || previous.Reason != diagnosis.Reason || delta > 0 {
+			transitions = append(transitions, PodTransition{
+				Diagnosis:    diagnosis,
+				Previous:     previous.Health,
+				RestartDelta: max(delta, 0),
+			})
+		}
+	}
+
+	for key, previous := range t.last {
+		if _, ok := current[key]; !ok {
+			transitions = append(transitions, PodTransition{Diagnosis: previous, Previous: previous.Health, Deleted: true})
+		}
+	}
+
+	t.last = current
+	t.oomKills = oomKills
+	sort.SliceStable(transitions, func(i, j int) bool {
+		return transitions[i].Diagnosis.Name < transitions[j].Diagnosis.Name
+	})
+	return transitions
+}
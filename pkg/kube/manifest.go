@@ -0,0 +1,185 @@
+package kube
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// serverPopulatedFields are removed from exported objects so they can be re-created elsewhere
+var serverPopulatedFields = [][]string{
+	{"metadata", "uid"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "generation"},
+	{"metadata", "creationTimestamp"},
+	{"metadata", "deletionTimestamp"},
+	{"metadata", "deletionGracePeriodSeconds"},
+	{"metadata", "selfLink"},
+	{"metadata", "managedFields"},
+	{"metadata", "ownerReferences"},
+	{"status"},
+}
+
+// ToUnstructured converts a typed object to unstructured form with its apiVersion and kind set.
+// Objects returned by typed List calls carry no TypeMeta, so the kind must be given.
+func ToUnstructured(obj runtime.Object, gvk schema.GroupVersionKind) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %s: %w", gvk.Kind, err)
+	}
+	u := &unstructured.Unstructured{Object: content}
+	u.SetGroupVersionKind(gvk)
+	return u, nil
+}
+
+// DeploymentManifest returns the Deployment described by a DeploymentConfig as a manifest
+// ready to be written or applied
+func DeploymentManifest(config DeploymentConfig) (*unstructured.Unstructured, error) {
+	deployment, err := newDeployment(config)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := ToUnstructured(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment"))
+	if err != nil {
+		return nil, err
+	}
+	StripServerFields(obj)
+	unstructured.RemoveNestedField(obj.Object, "spec", "template", "metadata", "creationTimestamp")
+	return obj, nil
+}
+
+// ServiceManifest returns the Service described by a ServiceConfig as a manifest ready to
+// be written or applied
+func ServiceManifest(config ServiceConfig) (*unstructured.Unstructured, error) {
+	obj, err := ToUnstructured(newService(config), corev1.SchemeGroupVersion.WithKind("Service"))
+	if err != nil {
+		return nil, err
+	}
+	StripServerFields(obj)
+	return obj, nil
+}
+
+// StripServerFields removes fields populated by the API server (uid, resourceVersion,
+// managedFields, status, ...) so the object can be applied again
+func StripServerFields(obj *unstructured.Unstructured) {
+	for _, path := range serverPopulatedFields {
+		unstructured.RemoveNestedField(obj.Object, path...)
+	}
+}
+
+// ManifestFileName returns the file name used for an object: kind[.group]_name.yaml
+func ManifestFileName(obj *unstructured.Unstructured) string {
+	gvk := obj.GroupVersionKind()
+	kind := strings.ToLower(gvk.Kind)
+	if gvk.Group != "" {
+		kind += "." + gvk.Group
+	}
+	return kind + "_" + obj.GetName() + ".yaml"
+}
+
+// WriteManifest writes an object as YAML into dir and returns the file path
+func WriteManifest(dir string, obj *unstructured.Unstructured) (string, error) {
+	data, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	path := filepath.Join(dir, ManifestFileName(obj))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// LoadManifests reads Kubernetes objects from a YAML or JSON file, every .yaml, .yml and
+// .json file in a directory, or standard input when path is "-". Files may hold several
+// documents, and List objects are expanded into their items.
+func LoadManifests(path string) ([]*unstructured.Unstructured, error) {
+	if path == "-" {
+		return decodeManifests(os.Stdin, "stdin")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if !info.IsDir() {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		defer file.Close()
+		return decodeManifests(file, path)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", path, err)
+	}
+	var objects []*unstructured.Unstructured
+	for _, entry := range entries {
+		switch filepath.Ext(entry.Name()) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		if entry.IsDir() {
+			continue
+		}
+		loaded, err := LoadManifests(filepath.Join(path, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, loaded...)
+	}
+	return objects, nil
+}
+
+// decodeManifests decodes every document in a YAML or JSON stream
+func decodeManifests(r io.Reader, source string) ([]*unstructured.Unstructured, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+	var objects []*unstructured.Unstructured
+	for {
+		var content map[string]interface{}
+		if err := decoder.Decode(&content); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+			return nil, fmt.Errorf("failed to parse %s: %w", source, err)
+		}
+		// Empty documents, e.g. a trailing "---", decode to nothing
+		if len(content) == 0 {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{Object: content}
+		if obj.IsList() {
+			list, err := obj.ToList()
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", source, err)
+			}
+			for i := range list.Items {
+				objects = append(objects, &list.Items[i])
+			}
+			continue
+		}
+		if obj.GetKind() == "" || obj.GetAPIVersion() == "" {
+			return nil, fmt.Errorf("%s: document %d is not a Kubernetes object (missing apiVersion or kind)", source, len(objects)+1)
+		}
+		objects = append(objects, obj)
+	}
+}
@@ -0,0 +1,220 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	// podMetricsResource is the metrics.k8s.io resource for pod usage
+	podMetricsResource = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+	// nodeMetricsResource is the metrics.k8s.io resource for node usage
+	nodeMetricsResource = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "nodes"}
+)
+
+// ContainerUsage holds the current usage of a single container alongside its requests and limits
+type ContainerUsage struct {
+	Name     string
+	CPU      resource.Quantity
+	Memory   resource.Quantity
+	Requests corev1.ResourceList
+	Limits   corev1.ResourceList
+}
+
+// PodUsage holds the current usage of a Pod, summed across its containers
+type PodUsage struct {
+	Name       string
+	Namespace  string
+	Containers []ContainerUsage
+	CPU        resource.Quantity
+	Memory     resource.Quantity
+	Requests   corev1.ResourceList
+	Limits     corev1.ResourceList
+}
+
+// NodeUsage holds the current usage of a Node alongside its allocatable resources
+type NodeUsage struct {
+	Name        string
+	CPU         resource.Quantity
+	Memory      resource.Quantity
+	Allocatable corev1.ResourceList
+}
+
+// UsagePercent returns usage as a percentage of total, and false when total is unset or zero
+func UsagePercent(usage resource.Quantity, total corev1.ResourceList, name corev1.ResourceName) (float64, bool) {
+	quantity, ok := total[name]
+	if !ok || quantity.IsZero() {
+		return 0, false
+	}
+	return float64(usage.MilliValue()) / float64(quantity.MilliValue()) * 100, true
+}
+
+// usageOf returns the current usage of a Pod for the named resource
+func (u PodUsage) usageOf(name corev1.ResourceName) resource.Quantity {
+	if name == corev1.ResourceCPU {
+		return u.CPU
+	}
+	return u.Memory
+}
+
+// LimitPercent returns the Pod's usage of a resource as a percentage of its limit.
+// A container without a limit can use the whole node, so the Pod has no effective
+// limit and false is returned unless every container sets one.
+func (u PodUsage) LimitPercent(name corev1.ResourceName) (float64, bool) {
+	if len(u.Containers) == 0 {
+		return 0, false
+	}
+	for _, container := range u.Containers {
+		if quantity, ok := container.Limits[name]; !ok || quantity.IsZero() {
+			return 0, false
+		}
+	}
+	return UsagePercent(u.usageOf(name), u.Limits, name)
+}
+
+// RequestPercent returns the Pod's usage of a resource as a percentage of its request
+func (u PodUsage) RequestPercent(name corev1.ResourceName) (float64, bool) {
+	return UsagePercent(u.usageOf(name), u.Requests, name)
+}
+
+// LimitPercent returns the container's usage of a resource as a percentage of its limit
+func (c ContainerUsage) LimitPercent(name corev1.ResourceName) (float64, bool) {
+	usage := c.Memory
+	if name == corev1.ResourceCPU {
+		usage = c.CPU
+	}
+	return UsagePercent(usage, c.Limits, name)
+}
+
+// GetPodMetrics retrieves pod and container usage from metrics.k8s.io and joins it with pod requests and limits
+func (kc *KubeClient) GetPodMetrics(namespace, labelSelector string) ([]PodUsage, error) {
+	pods, err := kc.ListPods(namespace, labelSelector)
+	if err != nil {
+		return nil, err
+	}
+	return kc.podUsage(namespace, labelSelector, pods)
+}
+
+// podUsage joins pod metrics with the requests and limits of the given pods
+func (kc *KubeClient) podUsage(namespace, labelSelector string, pods []corev1.Pod) ([]PodUsage, error) {
+	metricsList, err := kc.DynamicClient.Resource(podMetricsResource).Namespace(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pod metrics (is metrics-server installed?): %w", err)
+	}
+
+	podSpecs := make(map[string]*corev1.Pod, len(pods))
+	for i := range pods {
+		podSpecs[pods[i].Namespace+"/"+pods[i].Name] = &pods[i]
+	}
+
+	var usages []PodUsage
+	for _, item := range metricsList.Items {
+		usage := PodUsage{
+			Name:      item.GetName(),
+			Namespace: item.GetNamespace(),
+			Requests:  corev1.ResourceList{},
+			Limits:    corev1.ResourceList{},
+		}
+		pod := podSpecs[usage.Namespace+"/"+usage.Name]
+
+		containers, _, _ := unstructured.NestedSlice(item.Object, "containers")
+		for _, raw := range containers {
+			containerMetrics, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _, _ := unstructured.NestedString(containerMetrics, "name")
+			container := ContainerUsage{
+				Name:   name,
+				CPU:    parseUsage(containerMetrics, "cpu"),
+				Memory: parseUsage(containerMetrics, "memory"),
+			}
+			if pod != nil {
+				for _, spec := range pod.Spec.Containers {
+					if spec.Name == name {
+						container.Requests = spec.Resources.Requests
+						container.Limits = spec.Resources.Limits
+					}
+				}
+			}
+
+			usage.CPU.Add(container.CPU)
+			usage.Memory.Add(container.Memory)
+			addResources(usage.Requests, container.Requests)
+			addResources(usage.Limits, container.Limits)
+			usage.Containers = append(usage.Containers, container)
+		}
+		usages = append(usages, usage)
+	}
+
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].Namespace != usages[j].Namespace {
+			return usages[i].Namespace < usages[j].Namespace
+		}
+		return usages[i].Name < usages[j].Name
+	})
+	return usages, nil
+}
+
+// GetNodeMetrics retrieves node usage from metrics.k8s.io and joins it with node allocatable resources
+func (kc *KubeClient) GetNodeMetrics(labelSelector string) ([]NodeUsage, error) {
+	metricsList, err := kc.DynamicClient.Resource(nodeMetricsResource).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch node metrics (is metrics-server installed?): %w", err)
+	}
+
+	nodes, err := kc.ListNodes(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+	allocatable := make(map[string]corev1.ResourceList, len(nodes))
+	for _, node := range nodes {
+		allocatable[node.Name] = node.Status.Allocatable
+	}
+
+	var usages []NodeUsage
+	for _, item := range metricsList.Items {
+		usages = append(usages, NodeUsage{
+			Name:        item.GetName(),
+			CPU:         parseUsage(item.Object, "cpu"),
+			Memory:      parseUsage(item.Object, "memory"),
+			Allocatable: allocatable[item.GetName()],
+		})
+	}
+
+	sort.Slice(usages, func(i, j int) bool { return usages[i].Name < usages[j].Name })
+	return usages, nil
+}
+
+// parseUsage reads usage.<name> from a metrics object as a Quantity
+func parseUsage(obj map[string]interface{}, name string) resource.Quantity {
+	value, found, err := unstructured.NestedString(obj, "usage", name)
+	if err != nil || !found {
+		return resource.Quantity{}
+	}
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return resource.Quantity{}
+	}
+	return quantity
+}
+
+// addResources adds every quantity in src to dst
+func addResources(dst, src corev1.ResourceList) {
+	for name, quantity := range src {
+		total := dst[name]
+		total.Add(quantity)
+		dst[name] = total
+	}
+}
@@ -1,16 +1,17 @@
 package kube
 
 import (
-	"context"
 	"fmt"
+	"log"
+	"sort"
+	"strings"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/apimachinery/pkg/watch"
 )
 
 // MonitorConfig holds the configuration for monitoring resources
@@ -21,58 +22,123 @@ type MonitorConfig struct {
 	Timeout       time.Duration
 }
 
-// MonitorEvents streams resource events (e.g., Pods, Deployments) in real-time
+// MonitorEvents streams resource events (e.g., Pods, Deployments) in real-time.
+// It keeps watching across API server disconnects and stops after config.Timeout when set.
 func (kc *KubeClient) MonitorEvents(config MonitorConfig) error {
-	client := kc.DynamicClient.Resource(config.ResourceType).Namespace(config.Namespace)
+	var stopCh chan struct{}
+	if config.Timeout > 0 {
+		stopCh = make(chan struct{})
+		timer := time.AfterFunc(config.Timeout, func() { close(stopCh) })
+		defer timer.Stop()
+	}
+
+	fmt.Printf("Monitoring events for resource type %s in namespace %s\n", config.ResourceType.Resource, config.Namespace)
 
-	// Watch for resource events
-	watcher, err := client.Watch(context.TODO(), metav1.ListOptions{
+	return kc.WatchEvents(EventWatcherConfig{
+		Namespace:     config.Namespace,
+		ResourceType:  config.ResourceType,
 		LabelSelector: config.LabelSelector,
+		StopCh:        stopCh,
+		OnAdd:         func(obj runtime.Object) { fmt.Println("[ADDED]", describeResource(obj)) },
+		OnModify:      func(obj runtime.Object) { fmt.Println("[MODIFIED]", describeResource(obj)) },
+		OnDelete:      func(obj runtime.Object) { fmt.Println("[DELETED]", describeResource(obj)) },
+		OnWatchError:  func(err error) { fmt.Printf("[WATCH ERROR] %v\n", err) },
 	})
-	if err != nil {
-		return fmt.Errorf("failed to watch resource events: %w", err)
-	}
-	defer watcher.Stop()
+}
 
-	fmt.Printf("Monitoring events for resource type %s in namespace %s\n", config.ResourceType.Resource, config.Namespace)
+// PodHealthConfig holds the configuration for the pod health monitor
+type PodHealthConfig struct {
+	Namespace     string
+	LabelSelector string
+	Interval      time.Duration
+	// Metrics lists the usage metrics to check against limits ("cpu", "memory")
+	Metrics []string
+	// CPULimitPercent and MemoryLimitPercent report containers using more than
+	// this share of their limit; zero disables the check
+	CPULimitPercent    float64
+	MemoryLimitPercent float64
+	// Observers receive the cluster state read on every tick
+	Observers []MonitorObserver
+	// OnTransition is called for every pod health transition after it is printed
+	OnTransition func(PodTransition)
+}
 
-	for event := range watcher.ResultChan() {
-		switch event.Type {
-		case watch.Added:
-			fmt.Println("[ADDED]", describeResource(event.Object))
-		case watch.Modified:
-			fmt.Println("[MODIFIED]", describeResource(event.Object))
-		case watch.Deleted:
-			fmt.Println("[DELETED]", describeResource(event.Object))
-		default:
-			fmt.Println("[UNKNOWN EVENT TYPE]")
-		}
-	}
+// MonitorSnapshot is the cluster state read by the pod health monitor on a single tick
+type MonitorSnapshot struct {
+	Time        time.Time
+	Namespace   string
+	Pods        []corev1.Pod
+	Deployments []appsv1.Deployment
+	// Events holds the Warning events currently held in the cache
+	Events []corev1.Event
+}
 
-	return nil
+// MonitorObserver receives the state seen by the pod health monitor on every tick
+type MonitorObserver interface {
+	Observe(snapshot MonitorSnapshot)
+	RecordWatchError(resource string, err error)
 }
 
-// MonitorPodHealth continuously checks the status of all Pods in a namespace
-func (kc *KubeClient) MonitorPodHealth(namespace, labelSelector string, interval time.Duration) error {
-	podsClient := kc.Clientset.CoreV1().Pods(namespace)
+// MonitorPodHealth continuously checks the status of all Pods in a namespace.
+// Pods are read from the shared ClusterCache, so each tick costs no API calls once synced.
+func (kc *KubeClient) MonitorPodHealth(config PodHealthConfig) error {
+	// Events are only read for the snapshots handed to observers
+	var resources []string
+	if len(config.Observers) > 0 {
+		resources = append(resources, CacheEvents)
+	}
+	clusterCache, err := kc.Cache(config.Namespace, resources...)
+	if err != nil {
+		return fmt.Errorf("failed to start cluster cache: %w", err)
+	}
+
+	fmt.Printf("Monitoring pod health in namespace %s\n", config.Namespace)
 
-	fmt.Printf("Monitoring pod health in namespace %s\n", namespace)
+	for _, observer := range config.Observers {
+		clusterCache.OnWatchError(observer.RecordWatchError)
+	}
 
-	ticker := time.NewTicker(interval)
+	// Only state transitions and restarts are printed after the first tick
+	tracker := NewPodHealthTracker()
+	usageTracker := newUsageTracker(config)
+
+	ticker := time.NewTicker(config.Interval)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		pods, err := podsClient.List(context.TODO(), metav1.ListOptions{
-			LabelSelector: labelSelector,
-		})
+	for now := range ticker.C {
+		pods, err := clusterCache.ListPods(config.LabelSelector)
 		if err != nil {
 			fmt.Printf("Error listing pods: %v\n", err)
 			continue
 		}
 
-		for _, pod := range pods.Items {
-			status := summarizePodStatus(&pod)
-			fmt.Printf("Pod %s: %s\n", pod.Name, status)
+		for _, transition := range tracker.Observe(pods) {
+			fmt.Println(transition)
+			if config.OnTransition != nil {
+				config.OnTransition(transition)
+			}
+		}
+
+		if len(config.Observers) > 0 {
+			snapshot, err := monitorSnapshot(clusterCache, config, pods, now)
+			if err != nil {
+				fmt.Printf("Error reading cluster state: %v\n", err)
+			} else {
+				for _, observer := range config.Observers {
+					observer.Observe(snapshot)
+				}
+			}
+		}
+
+		if usageTracker.enabled() {
+			usages, err := kc.podUsage(config.Namespace, config.LabelSelector, pods)
+			if err != nil {
+				fmt.Printf("Error fetching pod metrics: %v\n", err)
+				continue
+			}
+			for _, line := range usageTracker.observe(usages) {
+				fmt.Println(line)
+			}
 		}
 	}
 
@@ -80,6 +146,94 @@ func (kc *KubeClient) MonitorPodHealth(namespace, labelSelector string, interval
 	return nil
 }
 
+// monitorSnapshot assembles the state handed to observers from the cache
+func monitorSnapshot(clusterCache *ClusterCache, config PodHealthConfig, pods []corev1.Pod, now time.Time) (MonitorSnapshot, error) {
+	deployments, err := clusterCache.ListDeployments(config.LabelSelector)
+	if err != nil {
+		return MonitorSnapshot{}, err
+	}
+	events, err := clusterCache.ListEvents(EventFilter{
+		Types: []string{corev1.EventTypeWarning},
+	})
+	if err != nil {
+		return MonitorSnapshot{}, err
+	}
+
+	return MonitorSnapshot{
+		Time:        now,
+		Namespace:   config.Namespace,
+		Pods:        pods,
+		Deployments: deployments,
+		Events:      events,
+	}, nil
+}
+
+// usageTracker reports containers crossing their configured limit thresholds
+type usageTracker struct {
+	thresholds map[corev1.ResourceName]float64
+	over       map[string]bool
+}
+
+// newUsageTracker builds thresholds for the metrics enabled in the config
+func newUsageTracker(config PodHealthConfig) *usageTracker {
+	tracker := &usageTracker{
+		thresholds: make(map[corev1.ResourceName]float64),
+		over:       make(map[string]bool),
+	}
+	for _, metric := range config.Metrics {
+		switch strings.ToLower(metric) {
+		case "cpu":
+			if config.CPULimitPercent > 0 {
+				tracker.thresholds[corev1.ResourceCPU] = config.CPULimitPercent
+			}
+		case "memory":
+			if config.MemoryLimitPercent > 0 {
+				tracker.thresholds[corev1.ResourceMemory] = config.MemoryLimitPercent
+			}
+		default:
+			log.Printf("Metric %q is not available from metrics.k8s.io and will not be monitored", metric)
+		}
+	}
+	return tracker
+}
+
+// enabled reports whether any usage threshold is configured
+func (t *usageTracker) enabled() bool {
+	return len(t.thresholds) > 0
+}
+
+// observe returns a line for every container that crossed a threshold in either direction
+func (t *usageTracker) observe(usages []PodUsage) []string {
+	var lines []string
+	current := make(map[string]bool)
+
+	for _, pod := range usages {
+		for _, container := range pod.Containers {
+			for name, threshold := range t.thresholds {
+				percent, ok := container.LimitPercent(name)
+				if !ok {
+					continue
+				}
+				key := fmt.Sprintf("%s/%s/%s", pod.Name, container.Name, name)
+				if percent >= threshold {
+					current[key] = true
+					if !t.over[key] {
+						lines = append(lines, fmt.Sprintf("Pod %s: container %s %s usage at %.0f%% of limit (threshold %.0f%%)",
+							pod.Name, container.Name, name, percent, threshold))
+					}
+				} else if t.over[key] {
+					lines = append(lines, fmt.Sprintf("Pod %s: container %s %s usage back to %.0f%% of limit",
+						pod.Name, container.Name, name, percent))
+				}
+			}
+		}
+	}
+
+	t.over = current
+	sort.Strings(lines)
+	return lines
+}
+
 // describeResource provides a brief description of a resource from a runtime.Object
 func describeResource(obj runtime.Object) string {
 	// Use the correct `meta.Accessor` for accessing resource metadata
@@ -89,17 +243,3 @@ func describeResource(obj runtime.Object) string {
 	}
 	return fmt.Sprintf("Name: %s, Namespace: %s, Labels: %v", metaObj.GetName(), metaObj.GetNamespace(), metaObj.GetLabels())
 }
-
-// summarizePodStatus returns a brief status summary for a Pod
-func summarizePodStatus(pod *corev1.Pod) string {
-	status := "Unknown"
-	for _, condition := range pod.Status.Conditions {
-		if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
-			status = "Ready"
-			break
-		} else if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionFalse {
-			status = "Not Ready"
-		}
-	}
-	return status
-}
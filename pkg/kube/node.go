@@ -0,0 +1,236 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// mirrorPodAnnotation marks static pods that the kubelet mirrors into the API
+const mirrorPodAnnotation = "kubernetes.io/config.mirror"
+
+// evictionRetryInterval is how long to wait before retrying an eviction blocked by a PodDisruptionBudget
+const evictionRetryInterval = 5 * time.Second
+
+// DrainConfig holds the options for draining a Node
+type DrainConfig struct {
+	NodeName string
+	// DeleteEmptyDirData allows evicting pods with emptyDir volumes, whose data is lost
+	DeleteEmptyDirData bool
+	// Force allows evicting pods that are not managed by a controller and will not be recreated
+	Force bool
+	// GracePeriod overrides the pods' termination grace period when non-negative
+	GracePeriod int64
+	// Timeout bounds the whole drain; zero waits forever
+	Timeout time.Duration
+}
+
+// ListNodes lists all Nodes in the cluster
+func (kc *KubeClient) ListNodes(labelSelector string) ([]corev1.Node, error) {
+	nodesClient := kc.Clientset.CoreV1().Nodes()
+
+	// Fetch Nodes
+	listOptions := metav1.ListOptions{
+		LabelSelector: labelSelector,
+	}
+	nodes, err := nodesClient.List(context.TODO(), listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	return nodes.Items, nil
+}
+
+// CordonNode marks a Node unschedulable so no new pods are placed on it
+func (kc *KubeClient) CordonNode(name string) error {
+	if err := kc.setUnschedulable(name, true); err != nil {
+		return err
+	}
+	fmt.Printf("Node %s cordoned\n", name)
+	return nil
+}
+
+// UncordonNode marks a Node schedulable again
+func (kc *KubeClient) UncordonNode(name string) error {
+	if err := kc.setUnschedulable(name, false); err != nil {
+		return err
+	}
+	fmt.Printf("Node %s uncordoned\n", name)
+	return nil
+}
+
+// setUnschedulable patches spec.unschedulable on a Node
+func (kc *KubeClient) setUnschedulable(name string, unschedulable bool) error {
+	patch := fmt.Sprintf(`{"spec":{"unschedulable":%t}}`, unschedulable)
+	_, err := kc.Clientset.CoreV1().Nodes().Patch(context.TODO(), name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to patch node %s: %w", name, err)
+	}
+	return nil
+}
+
+// DrainNode cordons a Node and evicts its pods through the Eviction API, so
+// PodDisruptionBudgets are respected. DaemonSet and mirror pods are skipped.
+// Pods with emptyDir volumes or without a controller block the drain unless
+// allowed by the config.
+func (kc *KubeClient) DrainNode(config DrainConfig) error {
+	ctx := context.Background()
+	if config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.Timeout)
+		defer cancel()
+	}
+
+	if err := kc.CordonNode(config.NodeName); err != nil {
+		return err
+	}
+
+	pods, err := kc.Clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", config.NodeName).String(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list pods on node %s: %w", config.NodeName, err)
+	}
+
+	var evict []corev1.Pod
+	var blocked []string
+	for _, pod := range pods.Items {
+		skip, reason := drainFilter(pod, config)
+		switch {
+		case skip:
+			fmt.Printf("Skipping pod %s/%s: %s\n", pod.Namespace, pod.Name, reason)
+		case reason != "":
+			blocked = append(blocked, fmt.Sprintf("%s/%s (%s)", pod.Namespace, pod.Name, reason))
+		default:
+			evict = append(evict, pod)
+		}
+	}
+	if len(blocked) > 0 {
+		sort.Strings(blocked)
+		return fmt.Errorf("cannot drain node %s, pods would be lost: %s", config.NodeName, strings.Join(blocked, ", "))
+	}
+	if len(evict) == 0 {
+		fmt.Printf("Node %s drained, no pods to evict\n", config.NodeName)
+		return nil
+	}
+
+	fmt.Printf("Evicting %d pods from node %s\n", len(evict), config.NodeName)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		done    int
+		errs    []string
+		pending = len(evict)
+	)
+	for _, pod := range evict {
+		wg.Add(1)
+		go func(pod corev1.Pod) {
+			defer wg.Done()
+			err := kc.evictPod(ctx, pod, config.GracePeriod)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s/%s: %v", pod.Namespace, pod.Name, err))
+				return
+			}
+			done++
+			fmt.Printf("[%d/%d] Pod %s/%s evicted\n", done, pending, pod.Namespace, pod.Name)
+		}(pod)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return fmt.Errorf("failed to drain node %s (%d/%d pods evicted): %s",
+			config.NodeName, done, pending, strings.Join(errs, "; "))
+	}
+
+	fmt.Printf("Node %s drained\n", config.NodeName)
+	return nil
+}
+
+// drainFilter decides how a pod is handled during a drain. skip is true for pods
+// that are left in place; a non-empty reason without skip means the pod blocks the drain.
+func drainFilter(pod corev1.Pod, config DrainConfig) (skip bool, reason string) {
+	if _, ok := pod.Annotations[mirrorPodAnnotation]; ok {
+		return true, "mirror pod"
+	}
+	if pod.DeletionTimestamp != nil {
+		return true, "already terminating"
+	}
+
+	controller := metav1.GetControllerOf(&pod)
+	if controller != nil && controller.Kind == "DaemonSet" {
+		return true, "managed by DaemonSet"
+	}
+
+	// Finished pods can always be removed
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return false, ""
+	}
+
+	if controller == nil && !config.Force {
+		return false, "not managed by a controller, use --force"
+	}
+	for _, volume := range pod.Spec.Volumes {
+		if volume.EmptyDir != nil && !config.DeleteEmptyDirData {
+			return false, "uses emptyDir volume " + volume.Name + ", use --delete-emptydir-data"
+		}
+	}
+	return false, ""
+}
+
+// evictPod evicts a pod, retrying while a PodDisruptionBudget blocks it, and waits until it is gone
+func (kc *KubeClient) evictPod(ctx context.Context, pod corev1.Pod, gracePeriod int64) error {
+	eviction := &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+	}
+	if gracePeriod >= 0 {
+		eviction.DeleteOptions = &metav1.DeleteOptions{GracePeriodSeconds: &gracePeriod}
+	}
+
+	for {
+		err := kc.Clientset.PolicyV1().Evictions(pod.Namespace).Evict(ctx, eviction)
+		if err == nil || k8sErrors.IsNotFound(err) {
+			break
+		}
+		if !k8sErrors.IsTooManyRequests(err) {
+			return fmt.Errorf("eviction failed: %w", err)
+		}
+
+		fmt.Printf("Pod %s/%s is protected by a disruption budget, retrying in %s\n",
+			pod.Namespace, pod.Name, evictionRetryInterval)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for disruption budget: %w", err)
+		case <-time.After(evictionRetryInterval):
+		}
+	}
+
+	// Wait for the pod to disappear or be replaced by a new pod of the same name
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		current, err := kc.Clientset.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if k8sErrors.IsNotFound(err) || (err == nil && current.UID != pod.UID) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for pod to terminate")
+		case <-ticker.C:
+		}
+	}
+}
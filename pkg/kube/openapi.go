@@ -0,0 +1,101 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// openAPIIndexFile records the server hash of each cached OpenAPI document
+const openAPIIndexFile = "index.json"
+
+// OpenAPICacheKey returns a directory name identifying the cluster the client talks to,
+// so schemas of different clusters are cached separately
+func (kc *KubeClient) OpenAPICacheKey() string {
+	host := kc.RESTConfig.Host
+	if u, err := url.Parse(host); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	return strings.NewReplacer(":", "_", "/", "_").Replace(host)
+}
+
+// SaveOpenAPISchemas writes the OpenAPI v3 document of every group version the server
+// publishes into dir, one JSON file per group version. Documents whose server hash
+// matches the copy already in dir are not downloaded again unless refresh is set.
+// It returns the number of documents downloaded and the number reused.
+func (kc *KubeClient) SaveOpenAPISchemas(dir string, refresh bool) (int, int, error) {
+	ctx := context.TODO()
+	restClient := kc.Clientset.Discovery().RESTClient()
+
+	data, err := restClient.Get().AbsPath("/openapi/v3").Do(ctx).Raw()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to fetch OpenAPI v3 discovery: %w", err)
+	}
+	var discovery struct {
+		Paths map[string]struct {
+			ServerRelativeURL string `json:"serverRelativeURL"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &discovery); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse OpenAPI v3 discovery: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, 0, fmt.Errorf("failed to create schema directory %s: %w", dir, err)
+	}
+	index := make(map[string]string)
+	if existing, err := os.ReadFile(filepath.Join(dir, openAPIIndexFile)); err == nil {
+		json.Unmarshal(existing, &index)
+	}
+
+	fetched, reused := 0, 0
+	for path, groupVersion := range discovery.Paths {
+		// Only API group versions carry resource schemas; skip paths such as "version"
+		if path != "api/v1" && !strings.HasPrefix(path, "apis/") {
+			continue
+		}
+		u, err := url.Parse(groupVersion.ServerRelativeURL)
+		if err != nil {
+			return fetched, reused, fmt.Errorf("invalid OpenAPI URL for %s: %w", path, err)
+		}
+		hash := u.Query().Get("hash")
+		file := filepath.Join(dir, openAPIFileName(path))
+		if _, err := os.Stat(file); err == nil && !refresh && hash != "" && index[path] == hash {
+			reused++
+			continue
+		}
+
+		request := restClient.Get().AbsPath(u.Path).SetHeader("Accept", "application/json")
+		if hash != "" {
+			request = request.Param("hash", hash)
+		}
+		document, err := request.Do(ctx).Raw()
+		if err != nil {
+			return fetched, reused, fmt.Errorf("failed to fetch OpenAPI schema for %s: %w", path, err)
+		}
+		if err := os.WriteFile(file, document, 0644); err != nil {
+			return fetched, reused, fmt.Errorf("failed to write %s: %w", file, err)
+		}
+		index[path] = hash
+		fetched++
+	}
+
+	encoded, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fetched, reused, fmt.Errorf("failed to encode schema index: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, openAPIIndexFile), encoded, 0644); err != nil {
+		return fetched, reused, fmt.Errorf("failed to write schema index: %w", err)
+	}
+	return fetched, reused, nil
+}
+
+// openAPIFileName returns the file an OpenAPI document path is stored in,
+// e.g. "apis/apps/v1" becomes "apis_apps_v1.json"
+func openAPIFileName(path string) string {
+	return strings.ReplaceAll(path, "/", "_") + ".json"
+}
@@ -0,0 +1,349 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Secret types that are managed by the cluster or by tooling rather than referenced by pods
+var ignoredSecretTypes = map[corev1.SecretType]bool{
+	corev1.SecretTypeServiceAccountToken: true,
+	"helm.sh/release.v1":                 true,
+	"bootstrap.kubernetes.io/token":      true,
+}
+
+// ignoredConfigMaps are published into every namespace by the control plane
+var ignoredConfigMaps = map[string]bool{
+	"kube-root-ca.crt": true,
+}
+
+// OrphanedResource is a resource that nothing in its namespace appears to use
+type OrphanedResource struct {
+	Kind      string
+	Name      string
+	Namespace string
+	Reason    string
+	// Object is the full resource, used to back it up before deletion
+	Object *unstructured.Unstructured
+}
+
+// references collects the names of objects used by pod templates in a namespace
+type references struct {
+	configMaps map[string]bool
+	secrets    map[string]bool
+	pvcs       map[string]bool
+	// pvcPrefixes holds "<template>-<statefulset>-" prefixes of StatefulSet claims
+	pvcPrefixes []string
+	// podLabels holds the labels of pods and pod templates, which Service selectors target
+	podLabels []labels.Set
+}
+
+// FindOrphans reports ConfigMaps, Secrets and PersistentVolumeClaims that no pod or
+// pod template references, and Services whose selectors match no pod or pod template.
+// Templates count so that workloads scaled to zero keep their Services.
+func (kc *KubeClient) FindOrphans(namespace string) ([]OrphanedResource, error) {
+	ctx := context.TODO()
+	refs, err := kc.collectReferences(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var orphans []OrphanedResource
+	add := func(kind, name, reason string, obj runtime.Object) error {
+		u, err := ToUnstructured(obj, corev1.SchemeGroupVersion.WithKind(kind))
+		if err != nil {
+			return err
+		}
+		orphans = append(orphans, OrphanedResource{Kind: kind, Name: name, Namespace: namespace, Reason: reason, Object: u})
+		return nil
+	}
+
+	configMaps, err := kc.ListConfigMaps(namespace, "")
+	if err != nil {
+		return nil, err
+	}
+	for i := range configMaps {
+		configMap := &configMaps[i]
+		if ignoredConfigMaps[configMap.Name] || refs.configMaps[configMap.Name] {
+			continue
+		}
+		if err := add("ConfigMap", configMap.Name, "not referenced by any pod template", configMap); err != nil {
+			return nil, err
+		}
+	}
+
+	secrets, err := kc.Clientset.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if ignoredSecretTypes[secret.Type] || refs.secrets[secret.Name] {
+			continue
+		}
+		if err := add("Secret", secret.Name, "not referenced by any pod template, service account or ingress", secret); err != nil {
+			return nil, err
+		}
+	}
+
+	claims, err := kc.Clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list persistentvolumeclaims: %w", err)
+	}
+	for i := range claims.Items {
+		claim := &claims.Items[i]
+		if refs.claimUsed(claim.Name) {
+			continue
+		}
+		if err := add("PersistentVolumeClaim", claim.Name, "not mounted by any pod template", claim); err != nil {
+			return nil, err
+		}
+	}
+
+	services, err := kc.ListServices(namespace, "")
+	if err != nil {
+		return nil, err
+	}
+	for i := range services {
+		service := &services[i]
+		// Services without a selector have manually managed endpoints
+		if len(service.Spec.Selector) == 0 || service.Spec.Type == corev1.ServiceTypeExternalName {
+			continue
+		}
+		selector := labels.SelectorFromSet(service.Spec.Selector)
+		matched := false
+		for _, podLabels := range refs.podLabels {
+			if selector.Matches(podLabels) {
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		reason := fmt.Sprintf("selector %s matches no pods or pod templates", selector)
+		if err := add("Service", service.Name, reason, service); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(orphans, func(i, j int) bool {
+		if orphans[i].Kind != orphans[j].Kind {
+			return orphans[i].Kind < orphans[j].Kind
+		}
+		return orphans[i].Name < orphans[j].Name
+	})
+	return orphans, nil
+}
+
+// DeleteOrphan deletes an orphaned resource
+func (kc *KubeClient) DeleteOrphan(orphan OrphanedResource) error {
+	ctx := context.TODO()
+	core := kc.Clientset.CoreV1()
+
+	var err error
+	switch orphan.Kind {
+	case "ConfigMap":
+		return kc.DeleteConfigMap(orphan.Name, orphan.Namespace)
+	case "Service":
+		return kc.DeleteService(orphan.Name, orphan.Namespace)
+	case "Secret":
+		err = core.Secrets(orphan.Namespace).Delete(ctx, orphan.Name, metav1.DeleteOptions{})
+	case "PersistentVolumeClaim":
+		err = core.PersistentVolumeClaims(orphan.Namespace).Delete(ctx, orphan.Name, metav1.DeleteOptions{})
+	default:
+		return fmt.Errorf("unsupported kind %s", orphan.Kind)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %w", strings.ToLower(orphan.Kind), err)
+	}
+
+	fmt.Printf("%s %s deleted successfully from namespace %s\n", orphan.Kind, orphan.Name, orphan.Namespace)
+	return nil
+}
+
+// collectReferences gathers every ConfigMap, Secret and PVC referenced from pods,
+// workload pod templates, service accounts and ingresses in the namespace
+func (kc *KubeClient) collectReferences(ctx context.Context, namespace string) (*references, error) {
+	refs := &references{
+		configMaps: make(map[string]bool),
+		secrets:    make(map[string]bool),
+		pvcs:       make(map[string]bool),
+	}
+	options := metav1.ListOptions{}
+
+	pods, err := kc.ListPods(namespace, "")
+	if err != nil {
+		return nil, err
+	}
+	for i := range pods {
+		refs.addPodSpec(&pods[i].Spec)
+		refs.addLabels(pods[i].Labels)
+	}
+
+	apps := kc.Clientset.AppsV1()
+	deployments, err := apps.Deployments(namespace).List(ctx, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for i := range deployments.Items {
+		refs.addPodSpec(&deployments.Items[i].Spec.Template.Spec)
+		refs.addLabels(deployments.Items[i].Spec.Template.Labels)
+	}
+
+	replicaSets, err := apps.ReplicaSets(namespace).List(ctx, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replicasets: %w", err)
+	}
+	for i := range replicaSets.Items {
+		refs.addPodSpec(&replicaSets.Items[i].Spec.Template.Spec)
+		refs.addLabels(replicaSets.Items[i].Spec.Template.Labels)
+	}
+
+	statefulSets, err := apps.StatefulSets(namespace).List(ctx, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for i := range statefulSets.Items {
+		statefulSet := &statefulSets.Items[i]
+		refs.addPodSpec(&statefulSet.Spec.Template.Spec)
+		refs.addLabels(statefulSet.Spec.Template.Labels)
+		for _, template := range statefulSet.Spec.VolumeClaimTemplates {
+			refs.pvcPrefixes = append(refs.pvcPrefixes, template.Name+"-"+statefulSet.Name+"-")
+		}
+	}
+
+	daemonSets, err := apps.DaemonSets(namespace).List(ctx, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+	for i := range daemonSets.Items {
+		refs.addPodSpec(&daemonSets.Items[i].Spec.Template.Spec)
+		refs.addLabels(daemonSets.Items[i].Spec.Template.Labels)
+	}
+
+	batch := kc.Clientset.BatchV1()
+	jobs, err := batch.Jobs(namespace).List(ctx, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	for i := range jobs.Items {
+		refs.addPodSpec(&jobs.Items[i].Spec.Template.Spec)
+		refs.addLabels(jobs.Items[i].Spec.Template.Labels)
+	}
+
+	cronJobs, err := batch.CronJobs(namespace).List(ctx, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cronjobs: %w", err)
+	}
+	for i := range cronJobs.Items {
+		refs.addPodSpec(&cronJobs.Items[i].Spec.JobTemplate.Spec.Template.Spec)
+		refs.addLabels(cronJobs.Items[i].Spec.JobTemplate.Spec.Template.Labels)
+	}
+
+	serviceAccounts, err := kc.Clientset.CoreV1().ServiceAccounts(namespace).List(ctx, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list serviceaccounts: %w", err)
+	}
+	for _, serviceAccount := range serviceAccounts.Items {
+		for _, secret := range serviceAccount.Secrets {
+			refs.secrets[secret.Name] = true
+		}
+		for _, secret := range serviceAccount.ImagePullSecrets {
+			refs.secrets[secret.Name] = true
+		}
+	}
+
+	ingresses, err := kc.Clientset.NetworkingV1().Ingresses(namespace).List(ctx, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingresses: %w", err)
+	}
+	for _, ingress := range ingresses.Items {
+		for _, tls := range ingress.Spec.TLS {
+			refs.secrets[tls.SecretName] = true
+		}
+	}
+
+	return refs, nil
+}
+
+// addLabels records the labels of a pod or pod template
+func (r *references) addLabels(podLabels map[string]string) {
+	if len(podLabels) > 0 {
+		r.podLabels = append(r.podLabels, labels.Set(podLabels))
+	}
+}
+
+// addPodSpec records the ConfigMaps, Secrets and PVCs used by a pod spec
+func (r *references) addPodSpec(spec *corev1.PodSpec) {
+	for _, secret := range spec.ImagePullSecrets {
+		r.secrets[secret.Name] = true
+	}
+
+	for _, volume := range spec.Volumes {
+		switch {
+		case volume.ConfigMap != nil:
+			r.configMaps[volume.ConfigMap.Name] = true
+		case volume.Secret != nil:
+			r.secrets[volume.Secret.SecretName] = true
+		case volume.PersistentVolumeClaim != nil:
+			r.pvcs[volume.PersistentVolumeClaim.ClaimName] = true
+		case volume.Projected != nil:
+			for _, source := range volume.Projected.Sources {
+				if source.ConfigMap != nil {
+					r.configMaps[source.ConfigMap.Name] = true
+				}
+				if source.Secret != nil {
+					r.secrets[source.Secret.Name] = true
+				}
+			}
+		}
+	}
+
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, container := range spec.EphemeralContainers {
+		containers = append(containers, corev1.Container(container.EphemeralContainerCommon))
+	}
+	for _, container := range containers {
+		for _, source := range container.EnvFrom {
+			if source.ConfigMapRef != nil {
+				r.configMaps[source.ConfigMapRef.Name] = true
+			}
+			if source.SecretRef != nil {
+				r.secrets[source.SecretRef.Name] = true
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if env.ValueFrom.ConfigMapKeyRef != nil {
+				r.configMaps[env.ValueFrom.ConfigMapKeyRef.Name] = true
+			}
+			if env.ValueFrom.SecretKeyRef != nil {
+				r.secrets[env.ValueFrom.SecretKeyRef.Name] = true
+			}
+		}
+	}
+}
+
+// claimUsed reports whether a PVC is mounted or belongs to a StatefulSet claim template
+func (r *references) claimUsed(name string) bool {
+	if r.pvcs[name] {
+		return true
+	}
+	for _, prefix := range r.pvcPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
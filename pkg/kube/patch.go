@@ -0,0 +1,85 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/restmapper"
+)
+
+// PatchTypes maps the patch type names accepted on the command line, as in kubectl, to
+// API patch types
+var PatchTypes = map[string]types.PatchType{
+	"strategic": types.StrategicMergePatchType,
+	"merge":     types.MergePatchType,
+	"json":      types.JSONPatchType,
+}
+
+// PatchResource applies a strategic-merge, JSON-merge or JSON patch to a resource and
+// returns the patched object. Only the fields named in the patch change, so concurrent
+// updates to other fields are kept. When an Admitter is configured, the patch is first
+// applied as a dry run and the result admitted before the patch is sent for real.
+// An empty namespace addresses a cluster-scoped resource.
+func (kc *KubeClient) PatchResource(name string, gvr schema.GroupVersionResource, namespace string, patchType types.PatchType, patch []byte, dryRun bool) (*unstructured.Unstructured, error) {
+	resource := kc.DynamicClient.Resource(gvr).Namespace(namespace)
+
+	if kc.Admission != nil || dryRun {
+		preview, err := resource.Patch(context.TODO(), name, patchType, patch, metav1.PatchOptions{DryRun: []string{metav1.DryRunAll}})
+		if err != nil {
+			return nil, patchError(gvr, err)
+		}
+		if err := kc.admit(preview, namespace); err != nil {
+			return nil, err
+		}
+		if dryRun {
+			return preview, nil
+		}
+	}
+
+	patched, err := resource.Patch(context.TODO(), name, patchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return nil, patchError(gvr, err)
+	}
+	return patched, nil
+}
+
+// patchError explains the errors a patch most often fails with
+func patchError(gvr schema.GroupVersionResource, err error) error {
+	if k8sErrors.IsNotFound(err) {
+		return fmt.Errorf("resource not found: %w", err)
+	}
+	if k8sErrors.IsUnsupportedMediaType(err) {
+		return fmt.Errorf("failed to patch %s: strategic merge patches only work for built-in kinds; use a merge or json patch: %w", gvr.Resource, err)
+	}
+	return fmt.Errorf("failed to patch resource: %w", err)
+}
+
+// ResolveResource finds the resource a kind, plural or short name such as "deploy",
+// "deployments.apps" or "Ingress" refers to, and whether it is namespaced
+func (kc *KubeClient) ResolveResource(name string) (schema.GroupVersionResource, bool, error) {
+	discoveryClient := memory.NewMemCacheClient(kc.Clientset.Discovery())
+	mapper := restmapper.NewShortcutExpander(restmapper.NewDeferredDiscoveryRESTMapper(discoveryClient), discoveryClient, nil)
+
+	resource, group, _ := strings.Cut(strings.ToLower(name), ".")
+	gvr, err := mapper.ResourceFor(schema.GroupVersionResource{Group: group, Resource: resource})
+	if err != nil {
+		return schema.GroupVersionResource{}, false, fmt.Errorf("unknown resource type %q: %w", name, err)
+	}
+	gvk, err := mapper.KindFor(gvr)
+	if err != nil {
+		return schema.GroupVersionResource{}, false, fmt.Errorf("unknown resource type %q: %w", name, err)
+	}
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return schema.GroupVersionResource{}, false, fmt.Errorf("unknown resource type %q: %w", name, err)
+	}
+	return gvr, mapping.Scope.Name() == meta.RESTScopeNameNamespace, nil
+}
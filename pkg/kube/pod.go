@@ -10,13 +10,17 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// PodConfig holds the configuration for creating/updating a Kubernetes Pod
+// PodConfig holds the configuration for creating/updating a Kubernetes Pod. Like
+// DeploymentConfig, the container fields describe a single container; set Containers
+// instead for a multi-container Pod.
 type PodConfig struct {
 	Name             string
 	Namespace        string
 	Image            string
 	ContainerName    string
 	ContainerPort    int32
+	Containers       []ContainerConfig
+	InitContainers   []ContainerConfig
 	Labels           map[string]string
 	Annotations      map[string]string
 	NodeSelector     map[string]string
@@ -37,6 +41,20 @@ type PodConfig struct {
 func (kc *KubeClient) CreatePod(config PodConfig) error {
 	podsClient := kc.Clientset.CoreV1().Pods(config.Namespace)
 
+	containers, initContainers, err := podContainers(ContainerConfig{
+		Name:           config.ContainerName,
+		Image:          config.Image,
+		Ports:          containerPorts(config.ContainerPort),
+		Env:            config.Env,
+		Resources:      config.Resources,
+		VolumeMounts:   config.VolumeMounts,
+		LivenessProbe:  config.LivenessProbe,
+		ReadinessProbe: config.ReadinessProbe,
+	}, config.Containers, config.InitContainers)
+	if err != nil {
+		return fmt.Errorf("invalid pod %s: %w", config.Name, err)
+	}
+
 	// Define the Pod spec
 	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
@@ -46,22 +64,8 @@ func (kc *KubeClient) CreatePod(config PodConfig) error {
 			Annotations: config.Annotations,
 		},
 		Spec: corev1.PodSpec{
-			Containers: []corev1.Container{
-				{
-					Name:  config.ContainerName,
-					Image: config.Image,
-					Ports: []corev1.ContainerPort{
-						{
-							ContainerPort: config.ContainerPort,
-						},
-					},
-					Env:            config.Env,
-					Resources:      config.Resources,
-					VolumeMounts:   config.VolumeMounts,
-					LivenessProbe:  config.LivenessProbe,
-					ReadinessProbe: config.ReadinessProbe,
-				},
-			},
+			InitContainers:                initContainers,
+			Containers:                    containers,
 			NodeSelector:                  config.NodeSelector,
 			Affinity:                      config.Affinity,
 			Tolerations:                   config.Tolerations,
@@ -73,7 +77,7 @@ func (kc *KubeClient) CreatePod(config PodConfig) error {
 	}
 
 	// Create the Pod
-	_, err := podsClient.Create(context.TODO(), pod, metav1.CreateOptions{})
+	_, err = podsClient.Create(context.TODO(), pod, metav1.CreateOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to create pod: %w", err)
 	}
@@ -114,17 +118,9 @@ func (kc *KubeClient) DeletePod(name, namespace string) error {
 
 // StreamPodLogs streams logs from a specific Pod container in real-time
 func (kc *KubeClient) StreamPodLogs(podName, namespace, containerName string) error {
-	podsClient := kc.Clientset.CoreV1().Pods(namespace)
-
-	logOptions := &corev1.PodLogOptions{
-		Container: containerName,
-		Follow:    true,
-	}
-
-	// Stream logs
-	stream, err := podsClient.GetLogs(podName, logOptions).Stream(context.TODO())
+	stream, err := kc.PodLogs(context.TODO(), podName, namespace, containerName, true, 0)
 	if err != nil {
-		return fmt.Errorf("failed to stream logs from pod %s: %w", podName, err)
+		return err
 	}
 	defer stream.Close()
 
@@ -136,3 +132,23 @@ func (kc *KubeClient) StreamPodLogs(podName, namespace, containerName string) er
 
 	return nil
 }
+
+// PodLogs opens a log stream for a Pod container. tailLines limits the
+// backlog when positive; the stream ends when ctx is cancelled.
+func (kc *KubeClient) PodLogs(ctx context.Context, podName, namespace, containerName string, follow bool, tailLines int64) (io.ReadCloser, error) {
+	podsClient := kc.Clientset.CoreV1().Pods(namespace)
+
+	logOptions := &corev1.PodLogOptions{
+		Container: containerName,
+		Follow:    follow,
+	}
+	if tailLines > 0 {
+		logOptions.TailLines = &tailLines
+	}
+
+	stream, err := podsClient.GetLogs(podName, logOptions).Stream(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stream logs from pod %s: %w", podName, err)
+	}
+	return stream, nil
+}
@@ -0,0 +1,381 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+// ResourceQuotaConfig holds the configuration for creating/updating a ResourceQuota
+type ResourceQuotaConfig struct {
+	Name      string
+	Namespace string
+	Labels    map[string]string
+	Hard      corev1.ResourceList
+	Scopes    []corev1.ResourceQuotaScope
+}
+
+// LimitRangeConfig holds the configuration for creating/updating a LimitRange
+type LimitRangeConfig struct {
+	Name      string
+	Namespace string
+	Labels    map[string]string
+	Limits    []corev1.LimitRangeItem
+}
+
+// QuotaUsage is the usage of one resource tracked by a ResourceQuota
+type QuotaUsage struct {
+	Namespace string
+	Quota     string
+	Resource  corev1.ResourceName
+	Used      resource.Quantity
+	Hard      resource.Quantity
+}
+
+// Percent returns the usage as a percentage of the hard limit, and false when the limit is zero
+func (u QuotaUsage) Percent() (float64, bool) {
+	return UsagePercent(u.Used, corev1.ResourceList{u.Resource: u.Hard}, u.Resource)
+}
+
+// ApplyResourceQuota creates the ResourceQuota described by config, or updates it if it
+// exists. Updates that conflict with a concurrent change are retried.
+func (kc *KubeClient) ApplyResourceQuota(config ResourceQuotaConfig) error {
+	quotasClient := kc.Clientset.CoreV1().ResourceQuotas(config.Namespace)
+
+	outcome := "updated"
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := quotasClient.Get(context.TODO(), config.Name, metav1.GetOptions{})
+		if k8sErrors.IsNotFound(err) {
+			quota := &corev1.ResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      config.Name,
+					Namespace: config.Namespace,
+					Labels:    config.Labels,
+				},
+				Spec: corev1.ResourceQuotaSpec{
+					Hard:   config.Hard,
+					Scopes: config.Scopes,
+				},
+			}
+			if _, err := quotasClient.Create(context.TODO(), quota, metav1.CreateOptions{}); err != nil {
+				return fmt.Errorf("failed to create resourcequota: %w", err)
+			}
+			outcome = "created"
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to fetch resourcequota: %w", err)
+		}
+
+		existing.Labels = config.Labels
+		existing.Spec.Hard = config.Hard
+		existing.Spec.Scopes = config.Scopes
+		if _, err := quotasClient.Update(context.TODO(), existing, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update resourcequota: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("ResourceQuota %s %s successfully in namespace %s\n", config.Name, outcome, config.Namespace)
+	return nil
+}
+
+// ApplyLimitRange creates the LimitRange described by config, or updates it if it exists.
+// Updates that conflict with a concurrent change are retried.
+func (kc *KubeClient) ApplyLimitRange(config LimitRangeConfig) error {
+	limitRangesClient := kc.Clientset.CoreV1().LimitRanges(config.Namespace)
+
+	outcome := "updated"
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := limitRangesClient.Get(context.TODO(), config.Name, metav1.GetOptions{})
+		if k8sErrors.IsNotFound(err) {
+			limitRange := &corev1.LimitRange{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      config.Name,
+					Namespace: config.Namespace,
+					Labels:    config.Labels,
+				},
+				Spec: corev1.LimitRangeSpec{Limits: config.Limits},
+			}
+			if _, err := limitRangesClient.Create(context.TODO(), limitRange, metav1.CreateOptions{}); err != nil {
+				return fmt.Errorf("failed to create limitrange: %w", err)
+			}
+			outcome = "created"
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to fetch limitrange: %w", err)
+		}
+
+		existing.Labels = config.Labels
+		existing.Spec.Limits = config.Limits
+		if _, err := limitRangesClient.Update(context.TODO(), existing, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update limitrange: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("LimitRange %s %s successfully in namespace %s\n", config.Name, outcome, config.Namespace)
+	return nil
+}
+
+// ListResourceQuotas lists the ResourceQuotas in a namespace, or in all namespaces when it is empty
+func (kc *KubeClient) ListResourceQuotas(namespace string) ([]corev1.ResourceQuota, error) {
+	quotas, err := kc.Clientset.CoreV1().ResourceQuotas(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resourcequotas: %w", err)
+	}
+	return quotas.Items, nil
+}
+
+// ListLimitRanges lists the LimitRanges in a namespace, or in all namespaces when it is empty
+func (kc *KubeClient) ListLimitRanges(namespace string) ([]corev1.LimitRange, error) {
+	limitRanges, err := kc.Clientset.CoreV1().LimitRanges(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list limitranges: %w", err)
+	}
+	return limitRanges.Items, nil
+}
+
+// DeleteResourceQuota deletes a ResourceQuota by name in the specified namespace
+func (kc *KubeClient) DeleteResourceQuota(name, namespace string) error {
+	err := kc.Clientset.CoreV1().ResourceQuotas(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to delete resourcequota: %w", err)
+	}
+	fmt.Printf("ResourceQuota %s deleted successfully from namespace %s\n", name, namespace)
+	return nil
+}
+
+// DeleteLimitRange deletes a LimitRange by name in the specified namespace
+func (kc *KubeClient) DeleteLimitRange(name, namespace string) error {
+	err := kc.Clientset.CoreV1().LimitRanges(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to delete limitrange: %w", err)
+	}
+	fmt.Printf("LimitRange %s deleted successfully from namespace %s\n", name, namespace)
+	return nil
+}
+
+// QuotaStatus returns the used and hard amount of every resource tracked by the
+// ResourceQuotas in a namespace, or in all namespaces when it is empty
+func (kc *KubeClient) QuotaStatus(namespace string) ([]QuotaUsage, error) {
+	quotas, err := kc.ListResourceQuotas(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var usages []QuotaUsage
+	for _, quota := range quotas {
+		for name, hard := range quota.Status.Hard {
+			usages = append(usages, QuotaUsage{
+				Namespace: quota.Namespace,
+				Quota:     quota.Name,
+				Resource:  name,
+				Used:      quota.Status.Used[name],
+				Hard:      hard,
+			})
+		}
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].Namespace != usages[j].Namespace {
+			return usages[i].Namespace < usages[j].Namespace
+		}
+		if usages[i].Quota != usages[j].Quota {
+			return usages[i].Quota < usages[j].Quota
+		}
+		return usages[i].Resource < usages[j].Resource
+	})
+	return usages, nil
+}
+
+// CheckDeploymentQuota returns an error when creating the Deployment described by config
+// would take its namespace past the hard limit of a ResourceQuota
+func (kc *KubeClient) CheckDeploymentQuota(config DeploymentConfig) error {
+	deployment, err := newDeployment(config)
+	if err != nil {
+		return err
+	}
+	return kc.checkDeploymentQuota(deployment)
+}
+
+// checkDeploymentQuota compares what a new Deployment's pods would request with the
+// quota remaining in its namespace. Container defaults from LimitRanges are applied
+// first, as the API server would. Scoped quotas are not checked, since whether they
+// apply depends on the pods. Without permission to read quotas the check is skipped.
+func (kc *KubeClient) checkDeploymentQuota(deployment *appsv1.Deployment) error {
+	quotas, err := kc.ListResourceQuotas(deployment.Namespace)
+	if k8sErrors.IsForbidden(err) {
+		fmt.Printf("Warning: skipping quota check: %v\n", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(quotas) == 0 {
+		return nil
+	}
+	limitRanges, err := kc.ListLimitRanges(deployment.Namespace)
+	if err != nil && !k8sErrors.IsForbidden(err) {
+		return err
+	}
+
+	pod := &corev1.Pod{Spec: *deployment.Spec.Template.Spec.DeepCopy()}
+	applyLimitRangeDefaults(&pod.Spec, limitRanges)
+	replicas := int64(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = int64(*deployment.Spec.Replicas)
+	}
+	requests, limits := PodReservation(pod)
+
+	required := corev1.ResourceList{
+		corev1.ResourcePods:                     *resource.NewQuantity(replicas, resource.DecimalSI),
+		"count/pods":                            *resource.NewQuantity(replicas, resource.DecimalSI),
+		"count/deployments.apps":                *resource.NewQuantity(1, resource.DecimalSI),
+		"count/replicasets.apps":                *resource.NewQuantity(1, resource.DecimalSI),
+		corev1.ResourceRequestsCPU:              scaleQuantity(requests[corev1.ResourceCPU], replicas),
+		corev1.ResourceCPU:                      scaleQuantity(requests[corev1.ResourceCPU], replicas),
+		corev1.ResourceRequestsMemory:           scaleQuantity(requests[corev1.ResourceMemory], replicas),
+		corev1.ResourceMemory:                   scaleQuantity(requests[corev1.ResourceMemory], replicas),
+		corev1.ResourceRequestsEphemeralStorage: scaleQuantity(requests[corev1.ResourceEphemeralStorage], replicas),
+		corev1.ResourceEphemeralStorage:         scaleQuantity(requests[corev1.ResourceEphemeralStorage], replicas),
+		corev1.ResourceLimitsCPU:                scaleQuantity(limits[corev1.ResourceCPU], replicas),
+		corev1.ResourceLimitsMemory:             scaleQuantity(limits[corev1.ResourceMemory], replicas),
+		corev1.ResourceLimitsEphemeralStorage:   scaleQuantity(limits[corev1.ResourceEphemeralStorage], replicas),
+	}
+
+	var problems []string
+	for _, quota := range quotas {
+		if len(quota.Spec.Scopes) > 0 || quota.Spec.ScopeSelector != nil {
+			continue
+		}
+		names := make([]string, 0, len(quota.Status.Hard))
+		for name := range quota.Status.Hard {
+			names = append(names, string(name))
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			resourceName := corev1.ResourceName(name)
+			need, ok := required[resourceName]
+			if !ok {
+				continue
+			}
+			if missing := containersWithout(pod.Spec, resourceName); len(missing) > 0 {
+				problems = append(problems, fmt.Sprintf("quota %s tracks %s but containers %s do not set it",
+					quota.Name, name, strings.Join(missing, ", ")))
+				continue
+			}
+			hard := quota.Status.Hard[resourceName]
+			used := quota.Status.Used[resourceName]
+			total := used.DeepCopy()
+			total.Add(need)
+			if total.Cmp(hard) > 0 {
+				problems = append(problems, fmt.Sprintf("quota %s: %s would reach %s of %s (%s in use, %s needed)",
+					quota.Name, name, total.String(), hard.String(), used.String(), need.String()))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("deployment %s would exceed the quota of namespace %s: %s",
+			deployment.Name, deployment.Namespace, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// applyLimitRangeDefaults fills in container requests and limits from the defaults of
+// Container LimitRanges. A container with a limit but no request is given a request
+// equal to its limit, as the API server does.
+func applyLimitRangeDefaults(spec *corev1.PodSpec, limitRanges []corev1.LimitRange) {
+	apply := func(container *corev1.Container) {
+		for _, limitRange := range limitRanges {
+			for _, item := range limitRange.Spec.Limits {
+				if item.Type != corev1.LimitTypeContainer {
+					continue
+				}
+				for name, quantity := range item.Default {
+					if _, ok := container.Resources.Limits[name]; !ok {
+						if container.Resources.Limits == nil {
+							container.Resources.Limits = corev1.ResourceList{}
+						}
+						container.Resources.Limits[name] = quantity.DeepCopy()
+					}
+				}
+				for name, quantity := range item.DefaultRequest {
+					if _, ok := container.Resources.Requests[name]; !ok {
+						if container.Resources.Requests == nil {
+							container.Resources.Requests = corev1.ResourceList{}
+						}
+						container.Resources.Requests[name] = quantity.DeepCopy()
+					}
+				}
+			}
+		}
+		for name, quantity := range container.Resources.Limits {
+			if _, ok := container.Resources.Requests[name]; !ok {
+				if container.Resources.Requests == nil {
+					container.Resources.Requests = corev1.ResourceList{}
+				}
+				container.Resources.Requests[name] = quantity.DeepCopy()
+			}
+		}
+	}
+	for i := range spec.InitContainers {
+		apply(&spec.InitContainers[i])
+	}
+	for i := range spec.Containers {
+		apply(&spec.Containers[i])
+	}
+}
+
+// containersWithout lists the containers that do not set the request or limit a quota
+// resource such as requests.cpu or limits.memory refers to. The API server rejects pods
+// like these in namespaces whose quota tracks the resource.
+func containersWithout(spec corev1.PodSpec, quotaResource corev1.ResourceName) []string {
+	kind, name, found := strings.Cut(string(quotaResource), ".")
+	if !found {
+		// Bare names such as "cpu" and "memory" mean requests
+		kind, name = "requests", string(quotaResource)
+	}
+	if kind != "requests" && kind != "limits" {
+		return nil
+	}
+	switch corev1.ResourceName(name) {
+	case corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage:
+	default:
+		return nil
+	}
+
+	var missing []string
+	for _, container := range append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...) {
+		list := container.Resources.Requests
+		if kind == "limits" {
+			list = container.Resources.Limits
+		}
+		if _, ok := list[corev1.ResourceName(name)]; !ok {
+			missing = append(missing, container.Name)
+		}
+	}
+	return missing
+}
+
+// scaleQuantity multiplies a quantity by n
+func scaleQuantity(quantity resource.Quantity, n int64) resource.Quantity {
+	format := quantity.Format
+	if format == "" {
+		format = resource.DecimalSI
+	}
+	return *resource.NewMilliQuantity(quantity.MilliValue()*n, format)
+}
@@ -0,0 +1,141 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// rolloutPollInterval is how often WaitForRollout checks the Deployment status
+const rolloutPollInterval = 2 * time.Second
+
+// SetDeploymentImage changes the image of one container of a Deployment, which starts a
+// rollout. An empty container name selects the first container.
+func (kc *KubeClient) SetDeploymentImage(name, namespace, container, image string) error {
+	deploymentsClient := kc.Clientset.AppsV1().Deployments(namespace)
+
+	deployment, err := deploymentsClient.Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to fetch deployment: %w", err)
+	}
+	spec := &deployment.Spec.Template.Spec
+	if len(spec.Containers) == 0 {
+		return fmt.Errorf("deployment %s has no containers", name)
+	}
+	if container == "" {
+		container = spec.Containers[0].Name
+	}
+	target, err := findContainer(spec, container)
+	if err != nil {
+		return fmt.Errorf("deployment %s: %w", name, err)
+	}
+	target.Image = image
+	list := "containers"
+	if isInitContainer(spec, container) {
+		list = "initContainers"
+	}
+
+	if err := kc.admitTyped(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment"), namespace); err != nil {
+		return err
+	}
+
+	// Patch only the image so concurrent changes to the Deployment are not overwritten
+	patch := fmt.Sprintf(`{"spec":{"template":{"spec":{%q:[{"name":%q,"image":%q}]}}}}`, list, container, image)
+	_, err = deploymentsClient.Patch(context.TODO(), name, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update deployment image: %w", err)
+	}
+
+	fmt.Printf("Deployment %s in namespace %s set to image %s\n", name, namespace, image)
+	return nil
+}
+
+// WaitForRollout waits until every replica of a Deployment runs its current pod template
+// and is available, like "kubectl rollout status". It fails early when the Deployment
+// exceeds its progress deadline.
+func (kc *KubeClient) WaitForRollout(ctx context.Context, name, namespace string, timeout time.Duration) error {
+	deploymentsClient := kc.Clientset.AppsV1().Deployments(namespace)
+
+	err := wait.PollUntilContextTimeout(ctx, rolloutPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		deployment, err := deploymentsClient.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, fmt.Errorf("failed to fetch deployment: %w", err)
+		}
+		if deployment.Status.ObservedGeneration < deployment.Generation {
+			return false, nil
+		}
+		for _, condition := range deployment.Status.Conditions {
+			if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+				return false, fmt.Errorf("deployment %s exceeded its progress deadline: %s", name, condition.Message)
+			}
+		}
+
+		replicas := int32(1)
+		if deployment.Spec.Replicas != nil {
+			replicas = *deployment.Spec.Replicas
+		}
+		status := deployment.Status
+		return status.UpdatedReplicas == replicas && status.Replicas == replicas &&
+			status.AvailableReplicas == replicas, nil
+	})
+	if wait.Interrupted(err) {
+		return fmt.Errorf("timed out waiting for deployment %s to roll out", name)
+	}
+	return err
+}
+
+// RolloutPods returns the pods of a Deployment's newest ReplicaSet that are not shutting
+// down, i.e. the pods running its current pod template
+func (kc *KubeClient) RolloutPods(name, namespace string) ([]corev1.Pod, error) {
+	deployment, err := kc.Clientset.AppsV1().Deployments(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch deployment: %w", err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector on deployment %s: %w", name, err)
+	}
+
+	replicaSets, err := kc.ListReplicaSets(namespace, selector.String())
+	if err != nil {
+		return nil, err
+	}
+	var newest *appsv1.ReplicaSet
+	for i := range replicaSets {
+		rs := &replicaSets[i]
+		if !metav1.IsControlledBy(rs, deployment) {
+			continue
+		}
+		if newest == nil || replicaSetRevision(rs) > replicaSetRevision(newest) {
+			newest = rs
+		}
+	}
+	if newest == nil {
+		return nil, nil
+	}
+
+	pods, err := kc.ListPods(namespace, selector.String())
+	if err != nil {
+		return nil, err
+	}
+	var current []corev1.Pod
+	for _, pod := range pods {
+		if pod.DeletionTimestamp == nil && metav1.IsControlledBy(&pod, newest) {
+			current = append(current, pod)
+		}
+	}
+	return current, nil
+}
+
+// replicaSetRevision returns the Deployment revision a ReplicaSet was created for
+func replicaSetRevision(rs *appsv1.ReplicaSet) int64 {
+	revision, _ := strconv.ParseInt(rs.Annotations["deployment.kubernetes.io/revision"], 10, 64)
+	return revision
+}
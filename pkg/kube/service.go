@@ -3,9 +3,15 @@ package kube
 import (
 	"context"
 	"fmt"
+	"net"
+	"sort"
+	"strconv"
 
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
 )
 
 // ServiceConfig holds the configuration for creating/updating a Kubernetes Service
@@ -16,15 +22,28 @@ type ServiceConfig struct {
 	Annotations map[string]string
 	Selector    map[string]string
 	Type        corev1.ServiceType
-	Ports       []corev1.ServicePort
+	// ClusterIP "None" makes a headless Service; it cannot change after creation
+	ClusterIP string
+	Ports     []corev1.ServicePort
 }
 
 // CreateService creates a Service based on the provided ServiceConfig
 func (kc *KubeClient) CreateService(config ServiceConfig) error {
 	servicesClient := kc.Clientset.CoreV1().Services(config.Namespace)
 
-	// Define the Service spec
-	service := &corev1.Service{
+	// Create the Service
+	_, err := servicesClient.Create(context.TODO(), newService(config), metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create service: %w", err)
+	}
+
+	fmt.Printf("Service %s created successfully in namespace %s\n", config.Name, config.Namespace)
+	return nil
+}
+
+// newService builds the Service object described by a ServiceConfig
+func newService(config ServiceConfig) *corev1.Service {
+	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        config.Name,
 			Namespace:   config.Namespace,
@@ -32,47 +51,145 @@ func (kc *KubeClient) CreateService(config ServiceConfig) error {
 			Annotations: config.Annotations,
 		},
 		Spec: corev1.ServiceSpec{
-			Selector: config.Selector,
-			Type:     config.Type,
-			Ports:    config.Ports,
+			Selector:  config.Selector,
+			Type:      config.Type,
+			ClusterIP: config.ClusterIP,
+			Ports:     config.Ports,
 		},
 	}
+}
 
-	// Create the Service
-	_, err := servicesClient.Create(context.TODO(), service, metav1.CreateOptions{})
+// UpdateService updates an existing Service based on the provided ServiceConfig. Updates
+// that conflict with a concurrent change are retried.
+func (kc *KubeClient) UpdateService(config ServiceConfig) error {
+	servicesClient := kc.Clientset.CoreV1().Services(config.Namespace)
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		// Fetch the existing Service
+		existingService, err := servicesClient.Get(context.TODO(), config.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to fetch service: %w", err)
+		}
+
+		// Update fields
+		existingService.Spec.Selector = config.Selector
+		existingService.Spec.Ports = config.Ports
+		existingService.Annotations = config.Annotations
+
+		// Update the Service
+		if _, err := servicesClient.Update(context.TODO(), existingService, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update service: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create service: %w", err)
+		return err
 	}
 
-	fmt.Printf("Service %s created successfully in namespace %s\n", config.Name, config.Namespace)
+	fmt.Printf("Service %s updated successfully in namespace %s\n", config.Name, config.Namespace)
 	return nil
 }
 
-// UpdateService updates an existing Service based on the provided ServiceConfig
-func (kc *KubeClient) UpdateService(config ServiceConfig) error {
+// ApplyService creates the Service described by config, or updates the type, ports and
+// selector of the existing one and merges in its labels and annotations. Node ports already allocated to
+// a port are kept so that updates do not move them. Updates that conflict with a
+// concurrent change are retried.
+func (kc *KubeClient) ApplyService(config ServiceConfig) error {
 	servicesClient := kc.Clientset.CoreV1().Services(config.Namespace)
 
-	// Fetch the existing Service
-	existingService, err := servicesClient.Get(context.TODO(), config.Name, metav1.GetOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to fetch service: %w", err)
+	serviceType := config.Type
+	if serviceType == "" {
+		serviceType = corev1.ServiceTypeClusterIP
 	}
 
-	// Update fields
-	existingService.Spec.Selector = config.Selector
-	existingService.Spec.Ports = config.Ports
-	existingService.Annotations = config.Annotations
-
-	// Update the Service
-	_, err = servicesClient.Update(context.TODO(), existingService, metav1.UpdateOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to update service: %w", err)
+	created := false
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := servicesClient.Get(context.TODO(), config.Name, metav1.GetOptions{})
+		if k8sErrors.IsNotFound(err) {
+			created = true
+			return kc.CreateService(config)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to fetch service: %w", err)
+		}
+
+		ports := make([]corev1.ServicePort, len(config.Ports))
+		copy(ports, config.Ports)
+		if serviceType != corev1.ServiceTypeClusterIP {
+			for i := range ports {
+				protocol := ports[i].Protocol
+				if protocol == "" {
+					protocol = corev1.ProtocolTCP
+				}
+				for _, current := range existing.Spec.Ports {
+					if ports[i].NodePort == 0 && current.Port == ports[i].Port && current.Protocol == protocol {
+						ports[i].NodePort = current.NodePort
+					}
+				}
+			}
+		}
+
+		existing.Labels = mergeStringMaps(existing.Labels, config.Labels)
+		existing.Annotations = mergeStringMaps(existing.Annotations, config.Annotations)
+		existing.Spec.Type = serviceType
+		existing.Spec.Selector = config.Selector
+		existing.Spec.Ports = ports
+		if _, err := servicesClient.Update(context.TODO(), existing, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update service: %w", err)
+		}
+		return nil
+	})
+	if err != nil || created {
+		return err
 	}
 
 	fmt.Printf("Service %s updated successfully in namespace %s\n", config.Name, config.Namespace)
 	return nil
 }
 
+// ServiceEndpoints returns the ready endpoint addresses of a Service as "ip:port", read
+// from its EndpointSlices
+func (kc *KubeClient) ServiceEndpoints(name, namespace string) ([]string, error) {
+	slices, err := kc.Clientset.DiscoveryV1().EndpointSlices(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list endpointslices: %w", err)
+	}
+
+	return readyAddresses(slices.Items), nil
+}
+
+// readyAddresses lists the ready endpoints of EndpointSlices as sorted "ip:port"
+func readyAddresses(slices []discoveryv1.EndpointSlice) []string {
+	var addresses []string
+	for _, slice := range slices {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+				continue
+			}
+			for _, address := range endpoint.Addresses {
+				for _, port := range slice.Ports {
+					if port.Port != nil {
+						addresses = append(addresses, net.JoinHostPort(address, strconv.Itoa(int(*port.Port))))
+					}
+				}
+			}
+		}
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+// GetService fetches a Service by name in the specified namespace
+func (kc *KubeClient) GetService(name, namespace string) (*corev1.Service, error) {
+	service, err := kc.Clientset.CoreV1().Services(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch service: %w", err)
+	}
+	return service, nil
+}
+
 // ListServices lists all Services in the specified namespace
 func (kc *KubeClient) ListServices(namespace, labelSelector string) ([]corev1.Service, error) {
 	servicesClient := kc.Clientset.CoreV1().Services(namespace)
@@ -0,0 +1,136 @@
+package kube
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// DefaultResyncPeriod is used when an EventWatcherConfig does not set a ResyncPeriod
+const DefaultResyncPeriod = 10 * time.Minute
+
+// ResourceWatcher watches a single resource type through a shared informer.
+//
+// The informer performs the initial list, resumes watches from the last seen
+// resourceVersion, requests bookmarks, relists after a 410 Gone response and
+// reconnects with backoff whenever the API server closes the watch.
+type ResourceWatcher struct {
+	config   EventWatcherConfig
+	informer cache.SharedIndexInformer
+}
+
+// NewResourceWatcher creates a ResourceWatcher for the resource type described by the config
+func (kc *KubeClient) NewResourceWatcher(config EventWatcherConfig) (*ResourceWatcher, error) {
+	if config.ResourceType.Resource == "" {
+		return nil, fmt.Errorf("resource type is required")
+	}
+	if config.ResyncPeriod == 0 {
+		config.ResyncPeriod = DefaultResyncPeriod
+	}
+
+	informer := dynamicinformer.NewFilteredDynamicInformer(
+		kc.DynamicClient,
+		config.ResourceType,
+		config.Namespace,
+		config.ResyncPeriod,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+		func(options *metav1.ListOptions) {
+			options.LabelSelector = config.LabelSelector
+			options.FieldSelector = config.FieldSelector
+		},
+	).Informer()
+
+	watcher := &ResourceWatcher{
+		config:   config,
+		informer: informer,
+	}
+
+	// Surface watch failures to the caller while keeping the reflector's own
+	// handling (relist on 410 Gone, reconnect with backoff) intact
+	err := informer.SetWatchErrorHandler(func(r *cache.Reflector, err error) {
+		cache.DefaultWatchErrorHandler(r, err)
+		if config.OnWatchError != nil {
+			config.OnWatchError(err)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set watch error handler: %w", err)
+	}
+
+	_, err = informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if config.OnAdd != nil {
+				config.OnAdd(obj.(runtime.Object))
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			// Periodic resyncs redeliver unchanged objects, which go to OnResync instead
+			oldMeta, oldErr := meta.Accessor(oldObj)
+			newMeta, newErr := meta.Accessor(newObj)
+			if oldErr == nil && newErr == nil && oldMeta.GetResourceVersion() == newMeta.GetResourceVersion() {
+				if config.OnResync != nil {
+					config.OnResync(newObj.(runtime.Object))
+				}
+				return
+			}
+			if config.OnModify != nil {
+				config.OnModify(newObj.(runtime.Object))
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			// Objects deleted while the watch was down arrive wrapped in a tombstone
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if config.OnDelete != nil {
+				if runtimeObj, ok := obj.(runtime.Object); ok {
+					config.OnDelete(runtimeObj)
+				}
+			}
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to register event handler: %w", err)
+	}
+
+	return watcher, nil
+}
+
+// Run starts the informer and blocks until stopCh is closed.
+// A nil stopCh runs the watcher until the process exits.
+func (rw *ResourceWatcher) Run(stopCh <-chan struct{}) error {
+	if stopCh == nil {
+		stopCh = make(chan struct{})
+	}
+
+	go rw.informer.Run(stopCh)
+
+	if !cache.WaitForCacheSync(stopCh, rw.informer.HasSynced) {
+		return fmt.Errorf("failed to sync cache for %s", rw.config.ResourceType.Resource)
+	}
+	log.Printf("Watch cache for %s synced (resourceVersion %s)", rw.config.ResourceType.Resource, rw.informer.LastSyncResourceVersion())
+
+	<-stopCh
+	return nil
+}
+
+// HasSynced reports whether the initial list has been delivered to the callbacks
+func (rw *ResourceWatcher) HasSynced() bool {
+	return rw.informer.HasSynced()
+}
+
+// LastSyncResourceVersion returns the resourceVersion the watch will resume from
+func (rw *ResourceWatcher) LastSyncResourceVersion() string {
+	return rw.informer.LastSyncResourceVersion()
+}
+
+// Store returns the informer's local cache of watched objects
+func (rw *ResourceWatcher) Store() cache.Store {
+	return rw.informer.GetStore()
+}
@@ -0,0 +1,210 @@
+package metrics
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golkube/pkg/kube"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Exporter exposes the monitor's view of the cluster in Prometheus exposition format.
+// It implements kube.MonitorObserver so it can be attached to the pod health monitor.
+type Exporter struct {
+	registry *prometheus.Registry
+	ready    atomic.Bool
+	// snapshot guards the gauges replaced on every observation
+	snapshot *lockedCollector
+
+	podReady              *prometheus.GaugeVec
+	podHealth             *prometheus.GaugeVec
+	containerRestarts     *prometheus.GaugeVec
+	deploymentDesired     *prometheus.GaugeVec
+	deploymentAvailable   *prometheus.GaugeVec
+	deploymentUnavailable *prometheus.GaugeVec
+	watchErrors           *prometheus.CounterVec
+	lastObservation       prometheus.Gauge
+}
+
+// NewExporter creates an Exporter with its own registry
+func NewExporter() *Exporter {
+	e := &Exporter{
+		registry: prometheus.NewRegistry(),
+		podReady: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "golkube_pod_ready",
+			Help: "Whether the pod's Ready condition is true (1) or not (0).",
+		}, []string{"namespace", "pod"}),
+		podHealth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "golkube_pod_health",
+			Help: "Health classification of the pod; the series with value 1 is the current state.",
+		}, []string{"namespace", "pod", "health"}),
+		containerRestarts: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "golkube_pod_container_restarts",
+			Help: "Restart count of each container in the pod.",
+		}, []string{"namespace", "pod", "container"}),
+		deploymentDesired: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "golkube_deployment_replicas_desired",
+			Help: "Number of replicas requested by the deployment spec.",
+		}, []string{"namespace", "deployment"}),
+		deploymentAvailable: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "golkube_deployment_replicas_available",
+			Help: "Number of available replicas of the deployment.",
+		}, []string{"namespace", "deployment"}),
+		deploymentUnavailable: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "golkube_deployment_replicas_unavailable",
+			Help: "Number of unavailable replicas of the deployment.",
+		}, []string{"namespace", "deployment"}),
+		watchErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "golkube_watch_errors_total",
+			Help: "Number of failed watches against the API server, by resource.",
+		}, []string{"resource"}),
+		lastObservation: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "golkube_monitor_last_observation_timestamp_seconds",
+			Help: "Unix time of the monitor's most recent observation.",
+		}),
+	}
+
+	e.snapshot = &lockedCollector{collectors: []prometheus.Collector{
+		e.podReady,
+		e.podHealth,
+		e.containerRestarts,
+		e.deploymentDesired,
+		e.deploymentAvailable,
+		e.deploymentUnavailable,
+	}}
+
+	e.registry.MustRegister(
+		e.snapshot,
+		e.watchErrors,
+		e.lastObservation,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return e
+}
+
+// Observe updates every gauge from the monitor's latest snapshot
+func (e *Exporter) Observe(snapshot kube.MonitorSnapshot) {
+	e.snapshot.mu.Lock()
+	e.observePods(snapshot.Pods)
+	e.observeDeployments(snapshot.Deployments)
+	e.snapshot.mu.Unlock()
+
+	e.lastObservation.Set(float64(snapshot.Time.Unix()))
+	e.ready.Store(true)
+}
+
+// lockedCollector collects a group of metrics under a read lock, so that a scrape never
+// sees them half-way through being reset and refilled under the write lock
+type lockedCollector struct {
+	mu         sync.RWMutex
+	collectors []prometheus.Collector
+}
+
+// Describe sends the descriptors of every metric in the group
+func (c *lockedCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, collector := range c.collectors {
+		collector.Describe(ch)
+	}
+}
+
+// Collect sends the current values of every metric in the group
+func (c *lockedCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, collector := range c.collectors {
+		collector.Collect(ch)
+	}
+}
+
+// observePods replaces the pod gauges with the given pods; callers must hold snapshot.mu
+func (e *Exporter) observePods(pods []corev1.Pod) {
+	// Reset so that deleted pods stop being exported
+	e.podReady.Reset()
+	e.podHealth.Reset()
+	e.containerRestarts.Reset()
+
+	for i := range pods {
+		pod := &pods[i]
+		diagnosis := kube.DiagnosePod(pod)
+
+		ready := 0.0
+		if diagnosis.Health == kube.PodHealthReady {
+			ready = 1
+		}
+		e.podReady.WithLabelValues(pod.Namespace, pod.Name).Set(ready)
+		e.podHealth.WithLabelValues(pod.Namespace, pod.Name, string(diagnosis.Health)).Set(1)
+
+		for _, status := range pod.Status.InitContainerStatuses {
+			e.containerRestarts.WithLabelValues(pod.Namespace, pod.Name, status.Name).Set(float64(status.RestartCount))
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			e.containerRestarts.WithLabelValues(pod.Namespace, pod.Name, status.Name).Set(float64(status.RestartCount))
+		}
+	}
+}
+
+// observeDeployments replaces the deployment gauges with the given deployments; callers
+// must hold snapshot.mu
+func (e *Exporter) observeDeployments(deployments []appsv1.Deployment) {
+	e.deploymentDesired.Reset()
+	e.deploymentAvailable.Reset()
+	e.deploymentUnavailable.Reset()
+
+	for _, deployment := range deployments {
+		var desired int32 = 1
+		if deployment.Spec.Replicas != nil {
+			desired = *deployment.Spec.Replicas
+		}
+		e.deploymentDesired.WithLabelValues(deployment.Namespace, deployment.Name).Set(float64(desired))
+		e.deploymentAvailable.WithLabelValues(deployment.Namespace, deployment.Name).Set(float64(deployment.Status.AvailableReplicas))
+		e.deploymentUnavailable.WithLabelValues(deployment.Namespace, deployment.Name).Set(float64(deployment.Status.UnavailableReplicas))
+	}
+}
+
+// RecordWatchError counts a failed watch for the resource
+func (e *Exporter) RecordWatchError(resource string, err error) {
+	e.watchErrors.WithLabelValues(resource).Inc()
+}
+
+// Handler returns an HTTP handler serving /metrics, /healthz and /readyz
+func (e *Exporter) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(e.registry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		// Ready once the monitor has completed its first observation
+		if !e.ready.Load() {
+			http.Error(w, "waiting for first observation", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	return mux
+}
+
+// ListenAndServe serves the exporter's endpoints on addr until the server fails
+func (e *Exporter) ListenAndServe(addr string) error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           e.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Printf("Serving metrics on %s/metrics", addr)
+	if err := server.ListenAndServe(); err != nil {
+		return fmt.Errorf("metrics server failed: %w", err)
+	}
+	return nil
+}
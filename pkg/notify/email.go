@@ -0,0 +1,107 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EmailConfig holds the configuration for SMTP email notifications
+type EmailConfig struct {
+	Enabled    bool     `mapstructure:"enabled"`
+	SMTPServer string   `mapstructure:"smtp_server"`
+	Port       int      `mapstructure:"port"`
+	Username   string   `mapstructure:"username"`
+	Password   string   `mapstructure:"password"`
+	From       string   `mapstructure:"from"`
+	To         []string `mapstructure:"to"`
+	Template   string   `mapstructure:"template"`
+}
+
+// EmailNotifier sends messages through an SMTP server
+type EmailNotifier struct {
+	config EmailConfig
+}
+
+// NewEmailNotifier creates an EmailNotifier; credentials fall back to SMTP_USERNAME and SMTP_PASSWORD
+func NewEmailNotifier(config EmailConfig) (*EmailNotifier, error) {
+	if config.Username == "" {
+		config.Username = os.Getenv("SMTP_USERNAME")
+	}
+	if config.Password == "" {
+		config.Password = os.Getenv("SMTP_PASSWORD")
+	}
+	if config.Port == 0 {
+		config.Port = 587
+	}
+
+	switch {
+	case config.SMTPServer == "":
+		return nil, fmt.Errorf("smtp_server is required")
+	case config.From == "":
+		return nil, fmt.Errorf("from is required")
+	case len(config.To) == 0:
+		return nil, fmt.Errorf("at least one recipient is required in to")
+	}
+
+	return &EmailNotifier{config: config}, nil
+}
+
+// Name returns the channel name
+func (e *EmailNotifier) Name() string {
+	return "email"
+}
+
+// Notify renders the message and sends it to every configured recipient
+func (e *EmailNotifier) Notify(ctx context.Context, msg Message) error {
+	body, err := Render(e.config.Template, msg)
+	if err != nil {
+		return err
+	}
+
+	severity := msg.Severity
+	if severity == "" {
+		severity = SeverityInfo
+	}
+	// Line breaks in a title would end the header and let it inject others, so they are
+	// folded to spaces and non-ASCII titles are encoded as RFC 2047 words
+	title := strings.Join(strings.FieldsFunc(msg.Title, func(r rune) bool { return r == '\r' || r == '\n' }), " ")
+	subject := mime.QEncoding.Encode("utf-8", fmt.Sprintf("[golkube][%s] %s", strings.ToUpper(string(severity)), title))
+	headers := []string{
+		"From: " + e.config.From,
+		"To: " + strings.Join(e.config.To, ", "),
+		"Subject: " + subject,
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+	}
+	payload := []byte(strings.Join(headers, "\r\n") + "\r\n\r\n" + strings.ReplaceAll(body, "\n", "\r\n"))
+
+	addr := net.JoinHostPort(e.config.SMTPServer, strconv.Itoa(e.config.Port))
+	var auth smtp.Auth
+	if e.config.Username != "" {
+		auth = smtp.PlainAuth("", e.config.Username, e.config.Password, e.config.SMTPServer)
+	}
+
+	// net/smtp has no context support, so honour cancellation around the blocking send
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, e.config.From, e.config.To, payload)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("email send cancelled: %w", ctx.Err())
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to send email via %s: %w", addr, err)
+		}
+		return nil
+	}
+}
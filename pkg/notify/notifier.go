@@ -0,0 +1,177 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Severity indicates how urgent a notification is
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// Message is a channel-agnostic notification
+type Message struct {
+	Title    string
+	Body     string
+	Severity Severity
+	// Source identifies the golkube component that produced the message (monitor, pipeline, ...)
+	Source string
+	// Event is a machine-readable event type (alert.firing, deploy.succeeded, ...) used by structured channels
+	Event  string
+	Fields map[string]string
+	Time   time.Time
+}
+
+// Notifier delivers messages to a single channel
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, msg Message) error
+}
+
+// Config mirrors the "notifications" block of the configuration file
+type Config struct {
+	Email   EmailConfig   `mapstructure:"email"`
+	Slack   SlackConfig   `mapstructure:"slack"`
+	Webhook WebhookConfig `mapstructure:"webhook"`
+	Retry   RetryConfig   `mapstructure:"retry"`
+}
+
+// RetryConfig controls how failed deliveries are retried
+type RetryConfig struct {
+	Attempts       int           `mapstructure:"attempts"`
+	InitialBackoff time.Duration `mapstructure:"initial_backoff"`
+	MaxBackoff     time.Duration `mapstructure:"max_backoff"`
+}
+
+// FromConfig builds a notifier for every enabled channel, each wrapped with retries
+func FromConfig(config Config) ([]Notifier, error) {
+	var notifiers []Notifier
+
+	if config.Slack.Enabled {
+		slack, err := NewSlackNotifier(config.Slack)
+		if err != nil {
+			return nil, fmt.Errorf("invalid slack configuration: %w", err)
+		}
+		notifiers = append(notifiers, WithRetry(slack, config.Retry))
+	}
+
+	if config.Email.Enabled {
+		email, err := NewEmailNotifier(config.Email)
+		if err != nil {
+			return nil, fmt.Errorf("invalid email configuration: %w", err)
+		}
+		notifiers = append(notifiers, WithRetry(email, config.Retry))
+	}
+
+	// Webhooks retry each URL on their own so one failing endpoint does not resend to the others
+	if config.Webhook.Enabled {
+		webhook, err := NewWebhookNotifier(config.Webhook, config.Retry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook configuration: %w", err)
+		}
+		notifiers = append(notifiers, webhook)
+	}
+
+	return notifiers, nil
+}
+
+// multiNotifier fans a message out to several notifiers
+type multiNotifier struct {
+	notifiers []Notifier
+}
+
+// NewMulti returns a Notifier that delivers every message to all the given notifiers
+func NewMulti(notifiers ...Notifier) Notifier {
+	return &multiNotifier{notifiers: notifiers}
+}
+
+// Name returns the name of the fan-out notifier
+func (m *multiNotifier) Name() string {
+	return "multi"
+}
+
+// Notify delivers the message to every notifier and joins their errors
+func (m *multiNotifier) Notify(ctx context.Context, msg Message) error {
+	var errs []error
+	for _, notifier := range m.notifiers {
+		if err := notifier.Notify(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", notifier.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// retryNotifier retries failed deliveries with exponential backoff
+type retryNotifier struct {
+	notifier Notifier
+	config   RetryConfig
+}
+
+// WithRetry wraps a notifier so failed deliveries are retried with exponential backoff
+func WithRetry(notifier Notifier, config RetryConfig) Notifier {
+	return &retryNotifier{notifier: notifier, config: config.withDefaults()}
+}
+
+// withDefaults fills in unset retry settings
+func (c RetryConfig) withDefaults() RetryConfig {
+	if c.Attempts <= 0 {
+		c.Attempts = 3
+	}
+	if c.InitialBackoff <= 0 {
+		c.InitialBackoff = time.Second
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = 30 * time.Second
+	}
+	return c
+}
+
+// Name returns the name of the wrapped notifier
+func (r *retryNotifier) Name() string {
+	return r.notifier.Name()
+}
+
+// Notify delivers the message, retrying until it succeeds, attempts run out or ctx is done
+func (r *retryNotifier) Notify(ctx context.Context, msg Message) error {
+	return retry(ctx, r.config, r.notifier.Name(), func() error {
+		return r.notifier.Notify(ctx, msg)
+	})
+}
+
+// retry calls deliver until it succeeds, attempts run out or ctx is done,
+// doubling the backoff between attempts up to MaxBackoff
+func retry(ctx context.Context, config RetryConfig, name string, deliver func() error) error {
+	backoff := config.InitialBackoff
+
+	var err error
+	for attempt := 1; attempt <= config.Attempts; attempt++ {
+		if err = deliver(); err == nil {
+			return nil
+		}
+		if attempt == config.Attempts {
+			break
+		}
+
+		log.Printf("Notification via %s failed (attempt %d/%d), retrying in %s: %v",
+			name, attempt, config.Attempts, backoff, err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("notification cancelled: %w", ctx.Err())
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > config.MaxBackoff {
+			backoff = config.MaxBackoff
+		}
+	}
+	return fmt.Errorf("notification failed after %d attempts: %w", config.Attempts, err)
+}
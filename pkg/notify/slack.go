@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// SlackConfig holds the configuration for Slack incoming-webhook notifications
+type SlackConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	WebhookURL string `mapstructure:"webhook_url"`
+	Channel    string `mapstructure:"channel"`
+	Username   string `mapstructure:"username"`
+	Template   string `mapstructure:"template"`
+}
+
+// SlackNotifier posts messages to a Slack incoming webhook
+type SlackNotifier struct {
+	config SlackConfig
+	client *http.Client
+}
+
+// NewSlackNotifier creates a SlackNotifier; the webhook URL falls back to SLACK_WEBHOOK_URL
+func NewSlackNotifier(config SlackConfig) (*SlackNotifier, error) {
+	if config.WebhookURL == "" {
+		config.WebhookURL = os.Getenv("SLACK_WEBHOOK_URL")
+	}
+	if config.WebhookURL == "" {
+		return nil, fmt.Errorf("webhook_url is required (or set SLACK_WEBHOOK_URL)")
+	}
+	if config.Template == "" {
+		config.Template = DefaultSlackTemplate
+	}
+
+	return &SlackNotifier{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Name returns the channel name
+func (s *SlackNotifier) Name() string {
+	return "slack"
+}
+
+// Notify posts the rendered message to the webhook
+func (s *SlackNotifier) Notify(ctx context.Context, msg Message) error {
+	text, err := Render(s.config.Template, msg)
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]string{"text": text}
+	if s.config.Channel != "" {
+		payload["channel"] = s.config.Channel
+	}
+	if s.config.Username != "" {
+		payload["username"] = s.config.Username
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode slack payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to slack: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("slack webhook returned status %d: %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultTextTemplate renders a message as plain text for email bodies
+const DefaultTextTemplate = `[{{ upper .Severity }}] {{ .Title }}
+{{ if .Body }}
+{{ .Body }}
+{{ end }}{{ range $key, $value := .Fields }}
+{{ $key }}: {{ $value }}{{ end }}
+
+Source: {{ .Source }}
+Time: {{ .Time.Format "2006-01-02 15:04:05 MST" }}
+`
+
+// DefaultSlackTemplate renders a message using Slack's mrkdwn formatting
+const DefaultSlackTemplate = `{{ emoji .Severity }} *{{ .Title }}*{{ if .Body }}
+{{ .Body }}{{ end }}{{ range $key, $value := .Fields }}
+• *{{ $key }}*: {{ $value }}{{ end }}
+_{{ .Source }} · {{ .Time.Format "15:04:05 MST" }}_`
+
+// templateFuncs are available to every notification template
+var templateFuncs = template.FuncMap{
+	"upper": func(s Severity) string { return strings.ToUpper(string(s)) },
+	"emoji": func(s Severity) string {
+		switch s {
+		case SeverityCritical:
+			return ":red_circle:"
+		case SeverityWarning:
+			return ":warning:"
+		default:
+			return ":information_source:"
+		}
+	},
+}
+
+// Render executes a notification template against a message.
+// An empty template string falls back to DefaultTextTemplate.
+func Render(tmpl string, msg Message) (string, error) {
+	if tmpl == "" {
+		tmpl = DefaultTextTemplate
+	}
+	if msg.Time.IsZero() {
+		msg.Time = time.Now()
+	}
+	if msg.Severity == "" {
+		msg.Severity = SeverityInfo
+	}
+
+	parsed, err := template.New("notification").Funcs(templateFuncs).Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse notification template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := parsed.Execute(&buf, msg); err != nil {
+		return "", fmt.Errorf("failed to render notification template: %w", err)
+	}
+	return buf.String(), nil
+}
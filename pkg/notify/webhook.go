@@ -0,0 +1,312 @@
+package notify
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// WebhookSchemaVersion identifies the layout of WebhookEvent. It only changes when
+// fields are removed or change meaning; new optional fields keep the same version.
+const WebhookSchemaVersion = "golkube.event/v1"
+
+// DefaultDeadLetterFile is used when the webhook config does not set dead_letter_file
+const DefaultDeadLetterFile = ".golkube/webhook-deadletter.jsonl"
+
+// Headers set on every webhook delivery
+const (
+	// SignatureHeader carries "sha256=" followed by the hex HMAC-SHA256 of the request body
+	SignatureHeader = "X-Golkube-Signature-256"
+	EventHeader     = "X-Golkube-Event"
+	DeliveryHeader  = "X-Golkube-Delivery"
+)
+
+// WebhookConfig holds the configuration for generic JSON webhook notifications
+type WebhookConfig struct {
+	Enabled bool     `mapstructure:"enabled"`
+	URLs    []string `mapstructure:"urls"`
+	// Secret is the HMAC-SHA256 key used to sign payloads; it is required
+	Secret string `mapstructure:"secret"`
+	// DeadLetterFile receives events that could not be delivered after all retries
+	DeadLetterFile string        `mapstructure:"dead_letter_file"`
+	Timeout        time.Duration `mapstructure:"timeout"`
+}
+
+// WebhookEvent is the JSON payload posted to webhook endpoints
+type WebhookEvent struct {
+	SchemaVersion string            `json:"schemaVersion"`
+	ID            string            `json:"id"`
+	Type          string            `json:"type"`
+	Source        string            `json:"source"`
+	Severity      Severity          `json:"severity"`
+	Title         string            `json:"title"`
+	Body          string            `json:"body,omitempty"`
+	Fields        map[string]string `json:"fields,omitempty"`
+	Timestamp     time.Time         `json:"timestamp"`
+}
+
+// DeadLetter is an undelivered event as stored in the dead-letter file
+type DeadLetter struct {
+	URL      string       `json:"url"`
+	Error    string       `json:"error"`
+	FailedAt time.Time    `json:"failedAt"`
+	Event    WebhookEvent `json:"event"`
+}
+
+// WebhookNotifier posts signed JSON events to one or more URLs
+type WebhookNotifier struct {
+	config WebhookConfig
+	retry  RetryConfig
+	client *http.Client
+
+	// mu serialises access to the dead-letter file
+	mu sync.Mutex
+}
+
+// NewWebhookNotifier creates a WebhookNotifier; the secret falls back to GOLKUBE_WEBHOOK_SECRET
+func NewWebhookNotifier(config WebhookConfig, retry RetryConfig) (*WebhookNotifier, error) {
+	if len(config.URLs) == 0 {
+		return nil, fmt.Errorf("at least one url is required")
+	}
+	if config.Secret == "" {
+		config.Secret = os.Getenv("GOLKUBE_WEBHOOK_SECRET")
+	}
+	if config.Secret == "" {
+		// Receivers must be able to verify every delivery, so unsigned events are never sent
+		return nil, fmt.Errorf("secret is required (or set GOLKUBE_WEBHOOK_SECRET)")
+	}
+	if config.DeadLetterFile == "" {
+		config.DeadLetterFile = DefaultDeadLetterFile
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	return &WebhookNotifier{
+		config: config,
+		retry:  retry.withDefaults(),
+		client: &http.Client{Timeout: config.Timeout},
+	}, nil
+}
+
+// Name returns the channel name
+func (w *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+// Notify posts the message as a WebhookEvent to every URL. Each URL is retried
+// independently and events that still fail are appended to the dead-letter file.
+func (w *WebhookNotifier) Notify(ctx context.Context, msg Message) error {
+	event := NewWebhookEvent(msg)
+
+	var errs []error
+	for _, url := range w.config.URLs {
+		err := retry(ctx, w.retry, w.Name(), func() error {
+			return w.deliver(ctx, url, event)
+		})
+		if err == nil {
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", url, err))
+		if dlErr := w.deadLetter(DeadLetter{URL: url, Error: err.Error(), FailedAt: time.Now(), Event: event}); dlErr != nil {
+			errs = append(errs, dlErr)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NewWebhookEvent converts a message to the webhook schema. The ID is derived from
+// the content so receivers can deduplicate retried and replayed deliveries.
+func NewWebhookEvent(msg Message) WebhookEvent {
+	if msg.Time.IsZero() {
+		msg.Time = time.Now()
+	}
+	event := WebhookEvent{
+		SchemaVersion: WebhookSchemaVersion,
+		Type:          msg.Event,
+		Source:        msg.Source,
+		Severity:      msg.Severity,
+		Title:         msg.Title,
+		Body:          msg.Body,
+		Fields:        msg.Fields,
+		Timestamp:     msg.Time.UTC(),
+	}
+	if event.Type == "" {
+		event.Type = "notification"
+	}
+	if event.Severity == "" {
+		event.Severity = SeverityInfo
+	}
+
+	content, _ := json.Marshal(event)
+	sum := sha256.Sum256(content)
+	event.ID = hex.EncodeToString(sum[:16])
+	return event
+}
+
+// Sign returns the signature header value for a payload
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliver makes a single signed POST of the event to url
+func (w *WebhookNotifier) deliver(ctx context.Context, url string, event WebhookEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "golkube-webhook")
+	req.Header.Set(EventHeader, event.Type)
+	req.Header.Set(DeliveryHeader, event.ID)
+	req.Header.Set(SignatureHeader, Sign(w.config.Secret, body))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+	return nil
+}
+
+// deadLetter appends an undelivered event to the dead-letter file
+func (w *WebhookNotifier) deadLetter(entry DeadLetter) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.appendDeadLetters([]DeadLetter{entry})
+}
+
+// appendDeadLetters appends entries to the dead-letter file in a single write, so that
+// lines appended by other processes never interleave with them; callers must hold mu
+func (w *WebhookNotifier) appendDeadLetters(entries []DeadLetter) error {
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to encode dead letter: %w", err)
+		}
+		buf.Write(append(line, '\n'))
+	}
+	if err := os.MkdirAll(filepath.Dir(w.config.DeadLetterFile), 0755); err != nil {
+		return fmt.Errorf("failed to create dead-letter directory: %w", err)
+	}
+
+	file, err := os.OpenFile(w.config.DeadLetterFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open dead-letter file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write dead letter: %w", err)
+	}
+	return nil
+}
+
+// DeadLetters returns the events currently in the dead-letter file
+func (w *WebhookNotifier) DeadLetters() ([]DeadLetter, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return readDeadLetters(w.config.DeadLetterFile)
+}
+
+// readDeadLetters parses a dead-letter file
+func readDeadLetters(path string) ([]DeadLetter, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open dead-letter file: %w", err)
+	}
+	defer file.Close()
+
+	var entries []DeadLetter
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var entry DeadLetter
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse dead-letter file: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read dead-letter file: %w", err)
+	}
+	return entries, nil
+}
+
+// Replay redelivers every dead-lettered event once to its original URL and appends the
+// events that failed again back to the dead-letter file. It returns the number delivered
+// and the entries that remain.
+//
+// The file is first renamed aside, so events other processes dead-letter during the
+// replay go to a new file and are neither lost nor replayed twice. If the replay cannot
+// finish, the events stay in the renamed copy named in the error.
+func (w *WebhookNotifier) Replay(ctx context.Context) (int, []DeadLetter, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	replaying := fmt.Sprintf("%s.replay-%d", w.config.DeadLetterFile, os.Getpid())
+	if err := os.Rename(w.config.DeadLetterFile, replaying); err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil, nil
+		}
+		return 0, nil, fmt.Errorf("failed to move dead-letter file aside: %w", err)
+	}
+
+	entries, err := readDeadLetters(replaying)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w; the events are kept in %s", err, replaying)
+	}
+
+	delivered := 0
+	var remaining []DeadLetter
+	for _, entry := range entries {
+		if err := w.deliver(ctx, entry.URL, entry.Event); err != nil {
+			entry.Error = err.Error()
+			entry.FailedAt = time.Now()
+			remaining = append(remaining, entry)
+			continue
+		}
+		delivered++
+	}
+
+	if len(remaining) > 0 {
+		if err := w.appendDeadLetters(remaining); err != nil {
+			return delivered, remaining, fmt.Errorf("%w; the events are kept in %s", err, replaying)
+		}
+	}
+	if err := os.Remove(replaying); err != nil {
+		return delivered, remaining, fmt.Errorf("failed to remove %s: %w", replaying, err)
+	}
+	return delivered, remaining, nil
+}
@@ -0,0 +1,161 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// builtinRule is a rule implemented in Go that applies to objects with a pod spec
+type builtinRule struct {
+	name        string
+	description string
+	severity    Severity
+	// check returns one message per violation
+	check func(w workload, config Config) []string
+}
+
+// builtinRules are the rules every workload is checked against unless disabled
+var builtinRules = []builtinRule{
+	{
+		name:        "latest-tag",
+		description: "images must be pinned to a tag other than latest, or a digest",
+		severity:    SeverityError,
+		check:       checkLatestTag,
+	},
+	{
+		name:        "resource-requests",
+		description: "containers must request CPU and memory",
+		severity:    SeverityWarning,
+		check:       checkResourceRequests,
+	},
+	{
+		name:        "resource-limits",
+		description: "containers must set CPU and memory limits",
+		severity:    SeverityWarning,
+		check:       checkResourceLimits,
+	},
+	{
+		name:        "readiness-probe",
+		description: "long-running containers must have a readiness probe",
+		severity:    SeverityWarning,
+		check:       checkReadinessProbe,
+	},
+	{
+		name:        "privileged",
+		description: "containers must not run privileged",
+		severity:    SeverityError,
+		check:       checkPrivileged,
+	},
+	{
+		name:        "host-path",
+		description: "pods must not mount hostPath volumes",
+		severity:    SeverityError,
+		check:       checkHostPath,
+	},
+	{
+		name:        "owner-label",
+		description: "workloads must carry the owner label",
+		severity:    SeverityWarning,
+		check:       checkOwnerLabel,
+	},
+}
+
+// allContainers returns the init and regular containers of a pod spec
+func allContainers(spec *corev1.PodSpec) []corev1.Container {
+	return append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+}
+
+func checkLatestTag(w workload, config Config) []string {
+	var messages []string
+	for _, container := range allContainers(w.spec) {
+		if usesLatestTag(container.Image) {
+			messages = append(messages, fmt.Sprintf("container %s uses unpinned image %q", container.Name, container.Image))
+		}
+	}
+	return messages
+}
+
+// usesLatestTag reports whether an image reference has no tag, the latest tag, and no digest
+func usesLatestTag(image string) bool {
+	if strings.Contains(image, "@") {
+		return false
+	}
+	// A colon after the last slash separates the tag; one before it is a registry port
+	name := image[strings.LastIndex(image, "/")+1:]
+	_, tag, hasTag := strings.Cut(name, ":")
+	return !hasTag || tag == "latest"
+}
+
+func checkResourceRequests(w workload, config Config) []string {
+	var messages []string
+	for _, container := range w.spec.Containers {
+		if missing := missingResources(container.Resources.Requests); missing != "" {
+			messages = append(messages, fmt.Sprintf("container %s has no %s request", container.Name, missing))
+		}
+	}
+	return messages
+}
+
+func checkResourceLimits(w workload, config Config) []string {
+	var messages []string
+	for _, container := range w.spec.Containers {
+		if missing := missingResources(container.Resources.Limits); missing != "" {
+			messages = append(messages, fmt.Sprintf("container %s has no %s limit", container.Name, missing))
+		}
+	}
+	return messages
+}
+
+// missingResources names the CPU and memory entries absent from a resource list
+func missingResources(resources corev1.ResourceList) string {
+	var missing []string
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if _, ok := resources[name]; !ok {
+			missing = append(missing, string(name))
+		}
+	}
+	return strings.Join(missing, " or ")
+}
+
+func checkReadinessProbe(w workload, config Config) []string {
+	// Jobs run to completion and are never behind a Service
+	if kind := w.obj.GetKind(); kind == "Job" || kind == "CronJob" {
+		return nil
+	}
+	var messages []string
+	for _, container := range w.spec.Containers {
+		if container.ReadinessProbe == nil {
+			messages = append(messages, fmt.Sprintf("container %s has no readiness probe", container.Name))
+		}
+	}
+	return messages
+}
+
+func checkPrivileged(w workload, config Config) []string {
+	var messages []string
+	for _, container := range allContainers(w.spec) {
+		if context := container.SecurityContext; context != nil && context.Privileged != nil && *context.Privileged {
+			messages = append(messages, fmt.Sprintf("container %s runs privileged", container.Name))
+		}
+	}
+	return messages
+}
+
+func checkHostPath(w workload, config Config) []string {
+	var messages []string
+	for _, volume := range w.spec.Volumes {
+		if volume.HostPath != nil {
+			messages = append(messages, fmt.Sprintf("volume %s mounts host path %s", volume.Name, volume.HostPath.Path))
+		}
+	}
+	return messages
+}
+
+func checkOwnerLabel(w workload, config Config) []string {
+	if w.obj.GetLabels()[config.OwnerLabel] != "" {
+		return nil
+	}
+	return []string{fmt.Sprintf("missing label %q", config.OwnerLabel)}
+}
@@ -0,0 +1,125 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// CustomRule is a rule written as a CEL expression. The expression must evaluate to
+// true for compliant objects and can use these variables:
+//
+//	object     the full object as a map
+//	containers the init and regular containers of its pod spec (empty for other kinds)
+//	namespace  the namespace the object is applied to
+type CustomRule struct {
+	Name       string   `mapstructure:"name"`
+	Severity   Severity `mapstructure:"severity"`
+	Expression string   `mapstructure:"expression"`
+	// Message is reported when the expression is false; defaults to the expression
+	Message string `mapstructure:"message"`
+	// Kinds limits the rule to these kinds; empty applies it to every kind
+	Kinds []string `mapstructure:"kinds"`
+}
+
+// compiledRule is a custom rule with its CEL program
+type compiledRule struct {
+	CustomRule
+	program cel.Program
+}
+
+// celEnvironment declares the variables available to custom rules
+var celEnvironment = func() *cel.Env {
+	env, err := cel.NewEnv(
+		cel.Variable("object", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("containers", cel.ListType(cel.DynType)),
+		cel.Variable("namespace", cel.StringType),
+		ext.Strings(),
+	)
+	if err != nil {
+		panic(fmt.Sprintf("invalid CEL environment: %v", err))
+	}
+	return env
+}()
+
+// compileRule checks a custom rule and compiles its expression
+func compileRule(rule CustomRule) (*compiledRule, error) {
+	if rule.Name == "" {
+		return nil, fmt.Errorf("custom rule name is required")
+	}
+	if rule.Expression == "" {
+		return nil, fmt.Errorf("custom rule %s: expression is required", rule.Name)
+	}
+	if rule.Severity == "" {
+		rule.Severity = SeverityWarning
+	}
+	if err := validSeverity(rule.Severity); err != nil {
+		return nil, fmt.Errorf("custom rule %s: %w", rule.Name, err)
+	}
+
+	ast, issues := celEnvironment.Compile(rule.Expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("custom rule %s: %w", rule.Name, issues.Err())
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("custom rule %s: expression must return a bool, not %s", rule.Name, ast.OutputType())
+	}
+	program, err := celEnvironment.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("custom rule %s: %w", rule.Name, err)
+	}
+	return &compiledRule{CustomRule: rule, program: program}, nil
+}
+
+// appliesTo reports whether the rule checks objects of a kind
+func (r *compiledRule) appliesTo(kind string) bool {
+	if len(r.Kinds) == 0 {
+		return true
+	}
+	for _, k := range r.Kinds {
+		if strings.EqualFold(k, kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// description returns the message shown for the rule in listings
+func (r *compiledRule) description() string {
+	if r.Message != "" {
+		return r.Message
+	}
+	return r.Expression
+}
+
+// evaluate runs the expression against an object. An expression that cannot be
+// evaluated, for example because it reads a missing field, counts as a violation.
+func (r *compiledRule) evaluate(w workload) []string {
+	containers := []interface{}{}
+	if path := podSpecPath(w.obj.GetKind()); path != nil {
+		for _, field := range []string{"initContainers", "containers"} {
+			items, _, _ := unstructured.NestedSlice(w.obj.Object, append(path, field)...)
+			containers = append(containers, items...)
+		}
+	}
+
+	result, _, err := r.program.Eval(map[string]interface{}{
+		"object":     w.obj.Object,
+		"containers": containers,
+		"namespace":  w.namespace,
+	})
+	if err != nil {
+		return []string{fmt.Sprintf("could not evaluate %q: %v", r.Expression, err)}
+	}
+	passed, ok := result.Value().(bool)
+	if !ok {
+		return []string{fmt.Sprintf("expression %q returned %v, not a bool", r.Expression, result.Value())}
+	}
+	if passed {
+		return nil
+	}
+	return []string{r.description()}
+}
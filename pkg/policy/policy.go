@@ -0,0 +1,274 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Severity decides what happens when a rule is violated
+type Severity string
+
+const (
+	// SeverityError violations reject the object
+	SeverityError Severity = "error"
+	// SeverityWarning violations are reported but the object is still applied
+	SeverityWarning Severity = "warning"
+	// SeverityDisabled turns a rule off
+	SeverityDisabled Severity = "disabled"
+)
+
+// DefaultOwnerLabel is the label the owner-label rule requires when none is configured
+const DefaultOwnerLabel = "owner"
+
+// Config mirrors the "policy" block of the configuration file
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// OwnerLabel is the label key the owner-label rule requires on workloads
+	OwnerLabel string `mapstructure:"owner_label"`
+	// Rules overrides the severity of built-in and custom rules by name
+	Rules map[string]Severity `mapstructure:"rules"`
+	// Namespaces overrides rule severities for objects in a namespace
+	Namespaces map[string]NamespaceConfig `mapstructure:"namespaces"`
+	Custom     []CustomRule               `mapstructure:"custom"`
+}
+
+// NamespaceConfig holds the rule severities for one namespace
+type NamespaceConfig struct {
+	Rules map[string]Severity `mapstructure:"rules"`
+}
+
+// Violation is a rule an object breaks
+type Violation struct {
+	Rule      string
+	Severity  Severity
+	Kind      string
+	Name      string
+	Namespace string
+	Message   string
+}
+
+// String formats the violation as "Kind/name: message (rule)"
+func (v Violation) String() string {
+	return fmt.Sprintf("%s/%s: %s (%s)", v.Kind, v.Name, v.Message, v.Rule)
+}
+
+// RuleInfo describes a rule and its severity in a namespace
+type RuleInfo struct {
+	Name        string
+	Description string
+	Severity    Severity
+	Custom      bool
+}
+
+// workload is an object under check along with its pod spec, if it has one
+type workload struct {
+	obj       *unstructured.Unstructured
+	namespace string
+	// spec is nil for objects that do not run pods
+	spec *corev1.PodSpec
+}
+
+// Engine checks objects against the built-in and custom rules
+type Engine struct {
+	config Config
+	custom []*compiledRule
+}
+
+// NewEngine validates the configuration and compiles the custom rules
+func NewEngine(config Config) (*Engine, error) {
+	if config.OwnerLabel == "" {
+		config.OwnerLabel = DefaultOwnerLabel
+	}
+
+	known := make(map[string]bool)
+	for _, rule := range builtinRules {
+		known[rule.name] = true
+	}
+
+	engine := &Engine{config: config}
+	for i := range config.Custom {
+		rule := config.Custom[i]
+		if known[rule.Name] {
+			return nil, fmt.Errorf("custom rule %q is defined more than once or shadows a built-in rule", rule.Name)
+		}
+		compiled, err := compileRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		known[rule.Name] = true
+		engine.custom = append(engine.custom, compiled)
+	}
+
+	check := func(scope string, severities map[string]Severity) error {
+		for name, severity := range severities {
+			if !known[name] {
+				return fmt.Errorf("%s: unknown rule %q", scope, name)
+			}
+			if err := validSeverity(severity); err != nil {
+				return fmt.Errorf("%s: rule %s: %w", scope, name, err)
+			}
+		}
+		return nil
+	}
+	if err := check("policy.rules", config.Rules); err != nil {
+		return nil, err
+	}
+	for namespace, nsConfig := range config.Namespaces {
+		if err := check("policy.namespaces."+namespace, nsConfig.Rules); err != nil {
+			return nil, err
+		}
+	}
+	return engine, nil
+}
+
+// validSeverity checks a configured severity
+func validSeverity(severity Severity) error {
+	switch severity {
+	case SeverityError, SeverityWarning, SeverityDisabled:
+		return nil
+	}
+	return fmt.Errorf("invalid severity %q (use error, warning or disabled)", severity)
+}
+
+// severity returns a rule's effective severity in a namespace: the namespace override,
+// then the global override, then the rule's default
+func (e *Engine) severity(name string, defaultSeverity Severity, namespace string) Severity {
+	if severity, ok := e.config.Namespaces[namespace].Rules[name]; ok {
+		return severity
+	}
+	if severity, ok := e.config.Rules[name]; ok {
+		return severity
+	}
+	return defaultSeverity
+}
+
+// Rules lists every rule with its effective severity in a namespace
+func (e *Engine) Rules(namespace string) []RuleInfo {
+	var rules []RuleInfo
+	for _, rule := range builtinRules {
+		rules = append(rules, RuleInfo{
+			Name:        rule.name,
+			Description: rule.description,
+			Severity:    e.severity(rule.name, rule.severity, namespace),
+		})
+	}
+	for _, rule := range e.custom {
+		rules = append(rules, RuleInfo{
+			Name:        rule.Name,
+			Description: rule.description(),
+			Severity:    e.severity(rule.Name, rule.Severity, namespace),
+			Custom:      true,
+		})
+	}
+	return rules
+}
+
+// Check evaluates all enabled rules against an object. Objects without a namespace are
+// checked with the rules of defaultNamespace.
+func (e *Engine) Check(obj *unstructured.Unstructured, defaultNamespace string) ([]Violation, error) {
+	w := workload{obj: obj, namespace: obj.GetNamespace()}
+	if w.namespace == "" {
+		w.namespace = defaultNamespace
+	}
+	spec, err := podSpec(obj)
+	if err != nil {
+		return nil, err
+	}
+	w.spec = spec
+
+	var violations []Violation
+	add := func(rule string, severity Severity, messages []string) {
+		for _, message := range messages {
+			violations = append(violations, Violation{
+				Rule:      rule,
+				Severity:  severity,
+				Kind:      obj.GetKind(),
+				Name:      obj.GetName(),
+				Namespace: w.namespace,
+				Message:   message,
+			})
+		}
+	}
+
+	for _, rule := range builtinRules {
+		severity := e.severity(rule.name, rule.severity, w.namespace)
+		if severity == SeverityDisabled || w.spec == nil {
+			continue
+		}
+		add(rule.name, severity, rule.check(w, e.config))
+	}
+	for _, rule := range e.custom {
+		severity := e.severity(rule.Name, rule.Severity, w.namespace)
+		if severity == SeverityDisabled || !rule.appliesTo(obj.GetKind()) {
+			continue
+		}
+		add(rule.Name, severity, rule.evaluate(w))
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Severity == SeverityError && violations[j].Severity != SeverityError
+	})
+	return violations, nil
+}
+
+// Admit checks an object about to be created or updated. Warnings are printed to
+// stderr, keeping stdout clean for command output; error-level violations reject the object.
+func (e *Engine) Admit(obj *unstructured.Unstructured, namespace string) error {
+	violations, err := e.Check(obj, namespace)
+	if err != nil {
+		return err
+	}
+
+	var rejected []string
+	for _, violation := range violations {
+		if violation.Severity == SeverityError {
+			rejected = append(rejected, violation.String())
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Policy warning: %s\n", violation)
+	}
+	if len(rejected) > 0 {
+		return fmt.Errorf("rejected by policy: %s", strings.Join(rejected, "; "))
+	}
+	return nil
+}
+
+// podSpecPath returns where the pod spec of a workload kind lives, or nil for other kinds
+func podSpecPath(kind string) []string {
+	switch kind {
+	case "Pod":
+		return []string{"spec"}
+	case "Deployment", "ReplicaSet", "StatefulSet", "DaemonSet", "Job", "ReplicationController":
+		return []string{"spec", "template", "spec"}
+	case "CronJob":
+		return []string{"spec", "jobTemplate", "spec", "template", "spec"}
+	}
+	return nil
+}
+
+// podSpec returns the pod spec of workload kinds, or nil for other kinds
+func podSpec(obj *unstructured.Unstructured) (*corev1.PodSpec, error) {
+	path := podSpecPath(obj.GetKind())
+	if path == nil {
+		return nil, nil
+	}
+
+	content, found, err := unstructured.NestedMap(obj.Object, path...)
+	if err != nil {
+		return nil, fmt.Errorf("%s/%s: invalid pod spec: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	spec := &corev1.PodSpec{}
+	if !found {
+		return spec, nil
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, spec); err != nil {
+		return nil, fmt.Errorf("%s/%s: invalid pod spec: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	return spec, nil
+}
@@ -0,0 +1,359 @@
+package scaffold
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golkube/pkg/kube"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Docker's defaults for healthcheck settings left unset in the image
+const (
+	defaultHealthInterval = 30 * time.Second
+	defaultHealthTimeout  = 30 * time.Second
+	defaultHealthRetries  = 3
+)
+
+// ociLabelPrefix marks the OCI image labels copied to the Deployment as annotations
+const ociLabelPrefix = "org.opencontainers.image."
+
+// Options are the settings that cannot be read from the image
+type Options struct {
+	// Name of the Deployment and Service; defaults to the image name
+	Name        string
+	Namespace   string
+	Replicas    int32
+	ServiceType corev1.ServiceType
+}
+
+// Result holds the configs generated from an image and notes on what needs a manual look
+type Result struct {
+	Deployment kube.DeploymentConfig
+	// Service is nil when the image exposes no ports
+	Service  *kube.ServiceConfig
+	Warnings []string
+}
+
+// FromImage generates a Deployment and Service for an image from its metadata: exposed
+// ports become container and service ports, the HEALTHCHECK becomes exec readiness and
+// liveness probes, and the image env, entrypoint, cmd and user are carried over.
+func FromImage(ref string, inspect types.ImageInspect, opts Options) (Result, error) {
+	if inspect.Config == nil {
+		return Result{}, fmt.Errorf("image %s has no config", ref)
+	}
+	config := inspect.Config
+
+	name := opts.Name
+	if name == "" {
+		name = nameFromImage(ref)
+	}
+	if problems := validation.IsDNS1035Label(name); len(problems) > 0 {
+		return Result{}, fmt.Errorf("invalid name %q: %s; pass --name", name, strings.Join(problems, ", "))
+	}
+	if opts.Replicas <= 0 {
+		opts.Replicas = 1
+	}
+	switch opts.ServiceType {
+	case "":
+		opts.ServiceType = corev1.ServiceTypeClusterIP
+	case corev1.ServiceTypeClusterIP, corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer:
+	default:
+		return Result{}, fmt.Errorf("unsupported service type %q; use ClusterIP, NodePort or LoadBalancer", opts.ServiceType)
+	}
+
+	// The labels double as the Deployment selector, which cannot change, so the image
+	// version is kept in the annotations only
+	var result Result
+	labels := map[string]string{"app": name}
+
+	image, warning := pinImage(ref, inspect.RepoDigests)
+	if warning != "" {
+		result.Warnings = append(result.Warnings, warning)
+	}
+
+	containerConfig := kube.ContainerConfig{
+		Name:    name,
+		Image:   image,
+		Command: config.Entrypoint,
+		Args:    config.Cmd,
+		Env:     envVars(config.Env),
+	}
+
+	ports := exposedPorts(config.ExposedPorts)
+	for _, port := range ports {
+		containerConfig.Ports = append(containerConfig.Ports, corev1.ContainerPort{
+			Name:          port.Name,
+			ContainerPort: port.Port,
+			Protocol:      port.Protocol,
+		})
+	}
+
+	securityContext, warning := userSecurityContext(config.User)
+	containerConfig.SecurityContext = securityContext
+	if warning != "" {
+		result.Warnings = append(result.Warnings, warning)
+	}
+
+	readiness, liveness, warning := healthProbes(config.Healthcheck)
+	containerConfig.ReadinessProbe = readiness
+	containerConfig.LivenessProbe = liveness
+	if warning != "" {
+		result.Warnings = append(result.Warnings, warning)
+	}
+	result.Warnings = append(result.Warnings, "no resource requests or limits are set; add them before deploying")
+
+	result.Deployment = kube.DeploymentConfig{
+		Name:             name,
+		Namespace:        opts.Namespace,
+		Replicas:         opts.Replicas,
+		Containers:       []kube.ContainerConfig{containerConfig},
+		Labels:           labels,
+		Annotations:      imageAnnotations(config.Labels),
+		TerminationGrace: 30,
+	}
+
+	if len(ports) == 0 {
+		result.Warnings = append(result.Warnings, "the image exposes no ports, so no Service was generated")
+		return result, nil
+	}
+	service := &kube.ServiceConfig{
+		Name:      name,
+		Namespace: opts.Namespace,
+		Labels:    labels,
+		Selector:  labels,
+		Type:      opts.ServiceType,
+	}
+	for _, port := range ports {
+		service.Ports = append(service.Ports, corev1.ServicePort{
+			Name:       port.Name,
+			Port:       port.Port,
+			TargetPort: intstr.FromString(port.Name),
+			Protocol:   port.Protocol,
+		})
+	}
+	result.Service = service
+	return result, nil
+}
+
+// Manifests returns the generated objects as manifests
+func (r Result) Manifests() ([]*unstructured.Unstructured, error) {
+	deployment, err := kube.DeploymentManifest(r.Deployment)
+	if err != nil {
+		return nil, err
+	}
+	manifests := []*unstructured.Unstructured{deployment}
+	if r.Service != nil {
+		service, err := kube.ServiceManifest(*r.Service)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, service)
+	}
+	return manifests, nil
+}
+
+// pinImage replaces an untagged or "latest" image reference with the digest the image was
+// pulled by, so that the Deployment runs the inspected image rather than whatever "latest"
+// points to later. Images without a registry digest are kept, with a warning.
+func pinImage(ref string, repoDigests []string) (string, string) {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return ref, fmt.Sprintf("cannot parse image reference %q; check the image field", ref)
+	}
+	if _, ok := named.(reference.Digested); ok {
+		return ref, ""
+	}
+	if tagged, ok := named.(reference.Tagged); ok && tagged.Tag() != "latest" {
+		return ref, ""
+	}
+
+	for _, repoDigest := range repoDigests {
+		digested, err := reference.ParseNormalizedNamed(repoDigest)
+		if err != nil || digested.Name() != named.Name() {
+			continue
+		}
+		if canonical, ok := digested.(reference.Canonical); ok {
+			pinned, err := reference.WithDigest(reference.TrimNamed(named), canonical.Digest())
+			if err == nil {
+				return reference.FamiliarString(pinned), fmt.Sprintf("image %s has no version tag, so it was pinned to its digest", ref)
+			}
+		}
+	}
+	return ref, fmt.Sprintf("image %s has no version tag or registry digest; tag it with a version before deploying", ref)
+}
+
+// imagePort is a port exposed by an image
+type imagePort struct {
+	Name     string
+	Port     int32
+	Protocol corev1.Protocol
+}
+
+// exposedPorts converts the image's exposed ports ("8080/tcp") to named ports, sorted by
+// number. Port names are limited to 15 characters, so they are built from the protocol
+// and number.
+func exposedPorts(exposed nat.PortSet) []imagePort {
+	var ports []imagePort
+	for key := range exposed {
+		port, err := strconv.ParseInt(key.Port(), 10, 32)
+		if err != nil {
+			// Port ranges cannot be expressed as container ports
+			continue
+		}
+		protocol := key.Proto()
+		ports = append(ports, imagePort{
+			Name:     fmt.Sprintf("%s-%d", protocol, port),
+			Port:     int32(port),
+			Protocol: corev1.Protocol(strings.ToUpper(protocol)),
+		})
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].Port != ports[j].Port {
+			return ports[i].Port < ports[j].Port
+		}
+		return ports[i].Protocol < ports[j].Protocol
+	})
+	return ports
+}
+
+// envVars converts the image's KEY=value env. PATH is left to the image, so that a later
+// image with a different PATH is not overridden.
+func envVars(env []string) []corev1.EnvVar {
+	var vars []corev1.EnvVar
+	for _, entry := range env {
+		key, value, _ := strings.Cut(entry, "=")
+		if key == "" || key == "PATH" {
+			continue
+		}
+		vars = append(vars, corev1.EnvVar{Name: key, Value: value})
+	}
+	return vars
+}
+
+// userSecurityContext converts the image's USER ("1000", "1000:1000", "app") to a security
+// context. Kubernetes only accepts numeric IDs, so user names produce a warning instead.
+func userSecurityContext(user string) (*corev1.SecurityContext, string) {
+	if user == "" {
+		return nil, "the image runs as root; consider a non-root USER"
+	}
+	uid, gid, hasGroup := strings.Cut(user, ":")
+	userID, err := strconv.ParseInt(uid, 10, 64)
+	if err != nil {
+		return nil, fmt.Sprintf("the image runs as user %q; set runAsUser to its numeric ID", user)
+	}
+
+	securityContext := &corev1.SecurityContext{RunAsUser: &userID}
+	if userID != 0 {
+		nonRoot := true
+		securityContext.RunAsNonRoot = &nonRoot
+	}
+	if hasGroup {
+		if groupID, err := strconv.ParseInt(gid, 10, 64); err == nil {
+			securityContext.RunAsGroup = &groupID
+		}
+	}
+	return securityContext, ""
+}
+
+// healthProbes converts a Docker HEALTHCHECK to exec readiness and liveness probes with
+// the same interval, timeout and retries. The liveness probe waits out the start period,
+// during which Docker ignores failures.
+func healthProbes(health *container.HealthConfig) (*corev1.Probe, *corev1.Probe, string) {
+	if health == nil || len(health.Test) == 0 {
+		return nil, nil, "the image has no HEALTHCHECK; add readiness and liveness probes"
+	}
+
+	var command []string
+	switch health.Test[0] {
+	case "NONE":
+		return nil, nil, "the image disables its healthcheck; add readiness and liveness probes"
+	case "CMD":
+		command = health.Test[1:]
+	case "CMD-SHELL":
+		command = []string{"/bin/sh", "-c", strings.Join(health.Test[1:], " ")}
+	default:
+		return nil, nil, fmt.Sprintf("unsupported HEALTHCHECK test %q; add probes by hand", health.Test[0])
+	}
+	if len(command) == 0 {
+		return nil, nil, "the image HEALTHCHECK has no command; add probes by hand"
+	}
+
+	interval := orDefault(health.Interval, defaultHealthInterval)
+	timeout := orDefault(health.Timeout, defaultHealthTimeout)
+	retries := int32(health.Retries)
+	if retries <= 0 {
+		retries = defaultHealthRetries
+	}
+
+	probe := func(initialDelay time.Duration) *corev1.Probe {
+		return &corev1.Probe{
+			ProbeHandler:        corev1.ProbeHandler{Exec: &corev1.ExecAction{Command: command}},
+			InitialDelaySeconds: seconds(initialDelay),
+			PeriodSeconds:       max(seconds(interval), 1),
+			TimeoutSeconds:      max(seconds(timeout), 1),
+			FailureThreshold:    retries,
+		}
+	}
+	return probe(0), probe(health.StartPeriod), ""
+}
+
+// imageAnnotations copies the image's OCI labels such as org.opencontainers.image.source
+func imageAnnotations(labels map[string]string) map[string]string {
+	annotations := make(map[string]string)
+	for key, value := range labels {
+		if strings.HasPrefix(key, ociLabelPrefix) && len(validation.IsQualifiedName(key)) == 0 {
+			annotations[key] = value
+		}
+	}
+	if len(annotations) == 0 {
+		return nil
+	}
+	return annotations
+}
+
+// invalidNameChars matches runs of characters not allowed in a DNS-1035 label
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// nameFromImage derives a resource name from an image reference:
+// "registry.example.com/team/my_app:1.2" becomes "my-app"
+func nameFromImage(ref string) string {
+	name, _, _ := strings.Cut(ref, "@")
+	name = path.Base(name)
+	name, _, _ = strings.Cut(name, ":")
+	name = invalidNameChars.ReplaceAllString(strings.ToLower(name), "-")
+	name = strings.Trim(name, "-")
+	if name != "" && (name[0] < 'a' || name[0] > 'z') {
+		name = "app-" + name
+	}
+	if len(name) > validation.DNS1035LabelMaxLength {
+		name = strings.TrimRight(name[:validation.DNS1035LabelMaxLength], "-")
+	}
+	return name
+}
+
+// orDefault returns d, or def when d is zero
+func orDefault(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
+
+// seconds rounds a duration up to whole seconds
+func seconds(d time.Duration) int32 {
+	return int32((d + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,243 @@
+package validation
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golkube/pkg/kube"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// componentPrefix is how OpenAPI v3 documents reference shared schemas
+const componentPrefix = "#/components/schemas/"
+
+// objectMetaSchema is the component name of ObjectMeta, used for CRD metadata
+const objectMetaSchema = "io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"
+
+// builtinSchemas are OpenAPI v3 documents of the core/v1, apps/v1, batch/v1 and
+// discovery.k8s.io/v1 APIs, reduced to their component schemas
+//
+//go:embed builtin/*.json
+var builtinSchemas embed.FS
+
+// SchemaSet holds OpenAPI schemas indexed by the kinds they describe
+type SchemaSet struct {
+	// components are the named schemas that $ref entries point to
+	components map[string]*spec.Schema
+	kinds      map[schema.GroupVersionKind]*spec.Schema
+	// sources lists where the schemas were loaded from, for messages
+	sources []string
+	// builtin is set when the schemas are the embedded fallback set
+	builtin bool
+}
+
+// NewSchemaSet returns an empty schema set
+func NewSchemaSet() *SchemaSet {
+	return &SchemaSet{
+		components: make(map[string]*spec.Schema),
+		kinds:      make(map[schema.GroupVersionKind]*spec.Schema),
+	}
+}
+
+// LoadSchemaDir loads every OpenAPI v3 document (*.json) and CustomResourceDefinition
+// manifest (*.yaml, *.yml) in a directory, as written by golkube validate export-schemas
+func LoadSchemaDir(dir string) (*SchemaSet, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema directory: %w", err)
+	}
+
+	set := NewSchemaSet()
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		switch filepath.Ext(entry.Name()) {
+		case ".json":
+			if err := set.addOpenAPIFile(path); err != nil {
+				return nil, err
+			}
+		case ".yaml", ".yml":
+			objects, err := kube.LoadManifests(path)
+			if err != nil {
+				return nil, err
+			}
+			for _, obj := range objects {
+				if IsCRD(obj) {
+					if err := set.AddCRD(obj); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+	if len(set.kinds) == 0 {
+		return nil, fmt.Errorf("no schemas found in %s", dir)
+	}
+	set.sources = append(set.sources, dir)
+	return set, nil
+}
+
+// LoadBuiltinSchemas loads the schemas embedded in golkube. They cover the workload,
+// service, config and storage kinds of the core, apps, batch and discovery APIs, and are
+// the fallback when neither a schema directory nor a cluster is available.
+func LoadBuiltinSchemas() (*SchemaSet, error) {
+	entries, err := builtinSchemas.ReadDir("builtin")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read built-in schemas: %w", err)
+	}
+
+	set := NewSchemaSet()
+	for _, entry := range entries {
+		data, err := builtinSchemas.ReadFile("builtin/" + entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read built-in schema %s: %w", entry.Name(), err)
+		}
+		if err := set.addOpenAPIDocument(entry.Name(), data); err != nil {
+			return nil, err
+		}
+	}
+	set.sources = append(set.sources, "built-in schemas")
+	set.builtin = true
+	return set, nil
+}
+
+// Kinds returns the number of kinds the set has schemas for
+func (s *SchemaSet) Kinds() int {
+	return len(s.kinds)
+}
+
+// Builtin reports whether the set is the embedded fallback, which covers only some APIs
+func (s *SchemaSet) Builtin() bool {
+	return s.builtin
+}
+
+// Covers reports whether the set has a schema for the object's kind
+func (s *SchemaSet) Covers(obj *unstructured.Unstructured) bool {
+	_, ok := s.lookup(obj.GroupVersionKind())
+	return ok
+}
+
+// addOpenAPIFile adds the component schemas of an OpenAPI v3 document. JSON files that
+// are not OpenAPI documents, such as the cache index, are ignored.
+func (s *SchemaSet) addOpenAPIFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return s.addOpenAPIDocument(path, data)
+}
+
+// addOpenAPIDocument adds the component schemas of an OpenAPI v3 document read from source
+func (s *SchemaSet) addOpenAPIDocument(source string, data []byte) error {
+	var document struct {
+		OpenAPI    string `json:"openapi"`
+		Components struct {
+			Schemas map[string]*spec.Schema `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", source, err)
+	}
+	if document.OpenAPI == "" {
+		return nil
+	}
+
+	for name, component := range document.Components.Schemas {
+		s.components[name] = component
+		for _, gvk := range groupVersionKinds(component) {
+			s.kinds[gvk] = component
+		}
+	}
+	return nil
+}
+
+// groupVersionKinds reads the x-kubernetes-group-version-kind extension of a schema
+func groupVersionKinds(component *spec.Schema) []schema.GroupVersionKind {
+	raw, ok := component.Extensions["x-kubernetes-group-version-kind"].([]interface{})
+	if !ok {
+		return nil
+	}
+	var gvks []schema.GroupVersionKind
+	for _, item := range raw {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		group, _ := entry["group"].(string)
+		version, _ := entry["version"].(string)
+		kind, _ := entry["kind"].(string)
+		gvks = append(gvks, schema.GroupVersionKind{Group: group, Version: version, Kind: kind})
+	}
+	return gvks
+}
+
+// IsCRD reports whether an object is a CustomResourceDefinition
+func IsCRD(obj *unstructured.Unstructured) bool {
+	return obj.GetKind() == "CustomResourceDefinition" && strings.HasPrefix(obj.GetAPIVersion(), "apiextensions.k8s.io/")
+}
+
+// AddCRD adds the schema of every version of a CustomResourceDefinition, so its custom
+// resources can be validated before the CRD is installed
+func (s *SchemaSet) AddCRD(crd *unstructured.Unstructured) error {
+	group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+	kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	if group == "" || kind == "" {
+		return fmt.Errorf("CustomResourceDefinition %s has no group or kind", crd.GetName())
+	}
+
+	for _, item := range versions {
+		version, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(version, "name")
+		content, found, _ := unstructured.NestedMap(version, "schema", "openAPIV3Schema")
+		if !found {
+			continue
+		}
+
+		data, err := json.Marshal(content)
+		if err != nil {
+			return fmt.Errorf("CustomResourceDefinition %s: %w", crd.GetName(), err)
+		}
+		crdSchema := &spec.Schema{}
+		if err := json.Unmarshal(data, crdSchema); err != nil {
+			return fmt.Errorf("CustomResourceDefinition %s version %s: invalid schema: %w", crd.GetName(), name, err)
+		}
+		s.kinds[schema.GroupVersionKind{Group: group, Version: name, Kind: kind}] = crdSchema
+	}
+	return nil
+}
+
+// lookup returns the schema for a kind
+func (s *SchemaSet) lookup(gvk schema.GroupVersionKind) (*spec.Schema, bool) {
+	kindSchema, ok := s.kinds[gvk]
+	return kindSchema, ok
+}
+
+// resolve follows $ref and single-entry allOf wrappers to the schema that describes a value.
+// Kubernetes OpenAPI v3 documents wrap references in allOf to attach defaults and descriptions.
+func (s *SchemaSet) resolve(current *spec.Schema) *spec.Schema {
+	for depth := 0; current != nil && depth < 32; depth++ {
+		if ref := current.Ref.String(); ref != "" {
+			current = s.components[strings.TrimPrefix(ref, componentPrefix)]
+			continue
+		}
+		if len(current.AllOf) == 1 && len(current.Type) == 0 && len(current.Properties) == 0 {
+			current = &current.AllOf[0]
+			continue
+		}
+		return current
+	}
+	return current
+}
@@ -0,0 +1,314 @@
+package validation
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// FieldError is a schema violation at a field path such as spec.template.spec.containers[0].name
+type FieldError struct {
+	Path    string
+	Message string
+}
+
+// Error formats the error as "path: message"
+func (e FieldError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// Validate checks an object against the schema of its kind. It reports unknown and
+// misspelled fields, missing required fields, wrong types and values outside an enum.
+func (s *SchemaSet) Validate(obj *unstructured.Unstructured) []FieldError {
+	gvk := obj.GroupVersionKind()
+	kindSchema, ok := s.lookup(gvk)
+	if !ok && s.builtin {
+		return []FieldError{{Message: fmt.Sprintf("no built-in schema for %s %s; validate against a cluster or --schema-dir", obj.GetAPIVersion(), gvk.Kind)}}
+	}
+	if !ok {
+		return []FieldError{{Message: fmt.Sprintf("no schema for %s %s; check apiVersion and kind, or install the CRD", obj.GetAPIVersion(), gvk.Kind)}}
+	}
+
+	v := &walker{set: s}
+	v.walkRoot(obj.Object, kindSchema)
+	sort.SliceStable(v.errors, func(i, j int) bool { return v.errors[i].Path < v.errors[j].Path })
+	return v.errors
+}
+
+// walker accumulates errors while walking an object alongside its schema
+type walker struct {
+	set    *SchemaSet
+	errors []FieldError
+}
+
+func (w *walker) fail(path, format string, args ...interface{}) {
+	w.errors = append(w.errors, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// walkRoot validates a top-level object. apiVersion and kind were already used to pick the
+// schema, and CRD schemas often leave metadata open, so metadata is checked as ObjectMeta.
+func (w *walker) walkRoot(object map[string]interface{}, rootSchema *spec.Schema) {
+	rootSchema = w.set.resolve(rootSchema)
+	if metadata, ok := object["metadata"]; ok {
+		if metaSchema, ok := w.set.components[objectMetaSchema]; ok {
+			w.walk("metadata", metadata, metaSchema)
+		}
+	}
+
+	rest := make(map[string]interface{}, len(object))
+	for key, value := range object {
+		if key != "metadata" {
+			rest[key] = value
+		}
+	}
+	trimmed := *rootSchema
+	trimmed.Properties = make(map[string]spec.Schema, len(rootSchema.Properties))
+	for name, property := range rootSchema.Properties {
+		if name != "metadata" {
+			trimmed.Properties[name] = property
+		}
+	}
+	// apiVersion and kind are always allowed even where a CRD schema omits them
+	for _, name := range []string{"apiVersion", "kind"} {
+		if _, ok := trimmed.Properties[name]; !ok {
+			trimmed.Properties[name] = *spec.StringProperty()
+		}
+	}
+	w.walk("", rest, &trimmed)
+}
+
+// walk validates value against fieldSchema; path is the value's field path
+func (w *walker) walk(path string, value interface{}, fieldSchema *spec.Schema) {
+	fieldSchema = w.set.resolve(fieldSchema)
+	if fieldSchema == nil || value == nil {
+		// Unresolvable references are not the manifest's fault, and null clears a field
+		return
+	}
+	if extensionBool(fieldSchema, "x-kubernetes-int-or-string") {
+		switch value.(type) {
+		case string:
+		default:
+			if _, ok := integerValue(value); !ok {
+				w.fail(path, "expected integer or string, got %s", describe(value))
+			}
+		}
+		return
+	}
+
+	switch {
+	case fieldSchema.Type.Contains("object") || (len(fieldSchema.Type) == 0 && len(fieldSchema.Properties) > 0):
+		w.walkObject(path, value, fieldSchema)
+	case fieldSchema.Type.Contains("array"):
+		items, ok := value.([]interface{})
+		if !ok {
+			w.fail(path, "expected array, got %s", describe(value))
+			return
+		}
+		if fieldSchema.Items == nil || fieldSchema.Items.Schema == nil {
+			return
+		}
+		for i, item := range items {
+			w.walk(fmt.Sprintf("%s[%d]", path, i), item, fieldSchema.Items.Schema)
+		}
+	case fieldSchema.Type.Contains("string"):
+		text, ok := value.(string)
+		if !ok {
+			w.fail(path, "expected string, got %s", describe(value))
+			return
+		}
+		w.checkEnum(path, text, fieldSchema)
+	case fieldSchema.Type.Contains("integer"):
+		if _, ok := integerValue(value); !ok {
+			w.fail(path, "expected integer, got %s", describe(value))
+		}
+	case fieldSchema.Type.Contains("number"):
+		if _, ok := numberValue(value); !ok {
+			w.fail(path, "expected number, got %s", describe(value))
+		}
+	case fieldSchema.Type.Contains("boolean"):
+		if _, ok := value.(bool); !ok {
+			w.fail(path, "expected boolean, got %s", describe(value))
+		}
+	}
+}
+
+// walkObject validates the fields of an object value
+func (w *walker) walkObject(path string, value interface{}, fieldSchema *spec.Schema) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		w.fail(path, "expected object, got %s", describe(value))
+		return
+	}
+
+	for _, name := range fieldSchema.Required {
+		if _, ok := object[name]; !ok {
+			w.fail(join(path, name), "required field is missing")
+		}
+	}
+
+	preserveUnknown := extensionBool(fieldSchema, "x-kubernetes-preserve-unknown-fields")
+	embedded := extensionBool(fieldSchema, "x-kubernetes-embedded-resource")
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fieldPath := join(path, key)
+		if property, ok := fieldSchema.Properties[key]; ok {
+			w.walk(fieldPath, object[key], &property)
+			continue
+		}
+		if embedded && (key == "apiVersion" || key == "kind" || key == "metadata") {
+			continue
+		}
+		if additional := fieldSchema.AdditionalProperties; additional != nil {
+			if additional.Schema != nil {
+				w.walk(fieldPath, object[key], additional.Schema)
+				continue
+			}
+			if additional.Allows {
+				continue
+			}
+		}
+		if preserveUnknown || (len(fieldSchema.Properties) == 0 && fieldSchema.AdditionalProperties == nil) {
+			// Schemas without properties describe free-form objects
+			continue
+		}
+
+		message := fmt.Sprintf("unknown field %q", key)
+		if suggestion := closestField(key, fieldSchema.Properties); suggestion != "" {
+			message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+		}
+		w.fail(fieldPath, "%s", message)
+	}
+}
+
+// checkEnum reports a string outside the schema's enum
+func (w *walker) checkEnum(path, value string, fieldSchema *spec.Schema) {
+	if len(fieldSchema.Enum) == 0 {
+		return
+	}
+	allowed := make([]string, 0, len(fieldSchema.Enum))
+	for _, option := range fieldSchema.Enum {
+		text := fmt.Sprint(option)
+		if text == value {
+			return
+		}
+		allowed = append(allowed, strconv.Quote(text))
+	}
+	w.fail(path, "unsupported value %q, must be one of %s", value, strings.Join(allowed, ", "))
+}
+
+// join appends a field name to a path
+func join(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+// extensionBool reads a boolean vendor extension
+func extensionBool(fieldSchema *spec.Schema, name string) bool {
+	value, _ := fieldSchema.Extensions.GetBool(name)
+	return value
+}
+
+// integerValue converts the numeric types produced by the YAML and JSON decoders to an integer
+func integerValue(value interface{}) (int64, bool) {
+	switch n := value.(type) {
+	case int:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case float64:
+		if n == math.Trunc(n) {
+			return int64(n), true
+		}
+	}
+	return 0, false
+}
+
+// numberValue converts the numeric types produced by the YAML and JSON decoders to a float
+func numberValue(value interface{}) (float64, bool) {
+	if n, ok := integerValue(value); ok {
+		return float64(n), true
+	}
+	n, ok := value.(float64)
+	return n, ok
+}
+
+// describe names the type of a decoded value for error messages
+func describe(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case bool:
+		return fmt.Sprintf("boolean %t", v)
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	if n, ok := numberValue(value); ok {
+		return fmt.Sprintf("number %v", n)
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// closestField suggests the property a misspelled field most likely meant: a case-insensitive
+// match, or the nearest name within an edit distance of two
+func closestField(field string, properties map[string]spec.Schema) string {
+	best, bestDistance := "", 3
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if strings.EqualFold(name, field) {
+			return name
+		}
+		if distance := editDistance(strings.ToLower(field), strings.ToLower(name)); distance < bestDistance {
+			best, bestDistance = name, distance
+		}
+	}
+	return best
+}
+
+// editDistance is the Damerau-Levenshtein (optimal string alignment) distance, so that
+// swapped letters such as "contianers" count as a single edit
+func editDistance(a, b string) int {
+	previous2 := make([]int, len(b)+1)
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				current[j] = min(current[j], previous2[j-2]+1)
+			}
+		}
+		previous2, previous, current = previous, current, previous2
+	}
+	return previous[len(b)]
+}